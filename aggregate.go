@@ -0,0 +1,233 @@
+package skiphash
+
+// augmentation maintains a summary per node and level that covers the live
+// entries in [node, node.next[level]), the way span counts them. A range is
+// then summarized by O(log n) of them.
+type augmentation[K any, V any] interface {
+	// refresh recomputes node's summary at level from node's own entry at
+	// level 0, and from the summaries one level down otherwise.
+	refresh(sh *SkipHash[K, V], node *slNode[K, V], level int)
+}
+
+// monoid is an augmentation over an associative combine. Summaries of empty
+// runs are represented by an unset aggCell, so combine needs no identity.
+type monoid[K any, V any, A any] struct {
+	// slot is this augmentation's index in slNode.aug.
+	slot    int
+	combine func(a, b A) A
+	extract func(K, V) A
+}
+
+type aggCell[A any] struct {
+	value A
+	ok    bool
+}
+
+func (m *monoid[K, V, A]) join(a, b aggCell[A]) aggCell[A] {
+	switch {
+	case !a.ok:
+		return b
+	case !b.ok:
+		return a
+	}
+	return aggCell[A]{value: m.combine(a.value, b.value), ok: true}
+}
+
+// cells returns node's summaries, allocating them on first use. Only
+// writers call it; every linked node has its cells.
+func (m *monoid[K, V, A]) cells(sh *SkipHash[K, V], node *slNode[K, V]) []aggCell[A] {
+	if node.aug == nil {
+		node.aug = make([]any, len(sh.augs))
+	}
+	cells, _ := node.aug[m.slot].([]aggCell[A])
+	if cells == nil {
+		cells = make([]aggCell[A], node.height)
+		node.aug[m.slot] = cells
+	}
+	return cells
+}
+
+func (m *monoid[K, V, A]) refresh(sh *SkipHash[K, V], node *slNode[K, V], level int) {
+	cells := m.cells(sh, node)
+	if level == 0 {
+		cells[0] = aggCell[A]{}
+		if node != sh.head && node.rTime == 0 {
+			cells[0] = aggCell[A]{value: m.extract(node.key, *node.value.Load()), ok: true}
+		}
+		return
+	}
+	var acc aggCell[A]
+	for cur := node; cur != node.next[level]; cur = cur.next[level-1] {
+		acc = m.join(acc, m.cells(sh, cur)[level-1])
+	}
+	cells[level] = acc
+}
+
+// queryLocked combines the summaries of the live entries in [low, high]
+// from left to right. It reports false when there are none.
+func (m *monoid[K, V, A]) queryLocked(sh *SkipHash[K, V], low, high K) (A, bool) {
+	var acc aggCell[A]
+	node := sh.lowerBoundLocked(low)
+	for node != sh.tail && sh.compare(node.key, high) <= 0 {
+		cells := node.aug[m.slot].([]aggCell[A])
+		level := int(node.height) - 1
+		for ; level >= 0; level-- {
+			if next := node.next[level]; next != sh.tail && sh.compare(next.key, high) <= 0 {
+				break
+			}
+		}
+		if level < 0 {
+			acc = m.join(acc, cells[0])
+			break
+		}
+		acc = m.join(acc, cells[level])
+		node = node.next[level]
+	}
+	return acc.value, acc.ok
+}
+
+// augmentLocked refreshes every summary that covers node after node was
+// linked, changed value, became a tombstone or, when linked is false, was
+// unlinked.
+func (sh *SkipHash[K, V]) augmentLocked(node *slNode[K, V], linked bool) {
+	for _, aug := range sh.augs {
+		cover := node.prev[node.height-1]
+		for level := range sh.maxLevel {
+			if level < int(node.height) {
+				if linked {
+					aug.refresh(sh, node, level)
+				}
+				aug.refresh(sh, node.prev[level], level)
+				continue
+			}
+			for int(cover.height) <= level {
+				cover = cover.prev[cover.height-1]
+			}
+			aug.refresh(sh, cover, level)
+		}
+	}
+}
+
+// rebuildAugmentsLocked recomputes every summary level by level, for after
+// a bulk load.
+func (sh *SkipHash[K, V]) rebuildAugmentsLocked() {
+	for _, aug := range sh.augs {
+		for level := range sh.maxLevel {
+			for node := sh.head; node != sh.tail; node = node.next[level] {
+				aug.refresh(sh, node, level)
+			}
+		}
+	}
+}
+
+// WithAggregate maintains a user-defined summary of the entries, such as a
+// count, a weighted sum or a bounding box, so QueryAggregate can answer it
+// for any key interval in O(log n). extract maps one entry to a summary and
+// combine merges the summaries of two adjacent runs, left one first; it must
+// be associative but need not be commutative. Every write pays O(log n)
+// calls to combine. Several aggregates may be registered; QueryAggregate
+// picks one by its summary type.
+func WithAggregate[K any, V any, A any](combine func(a, b A) A, extract func(K, V) A) Option {
+	return func(cfg *config) {
+		cfg.aggregates = append(cfg.aggregates, func(slot int) augmentation[K, V] {
+			return &monoid[K, V, A]{slot: slot, combine: combine, extract: extract}
+		})
+	}
+}
+
+// QueryAggregate combines the summaries of the live entries in [low, high]
+// with the aggregate registered by WithAggregate for summary type A, or the
+// first of them if there are several. It reports false when the interval
+// holds no entries and panics when no aggregate of type A was registered.
+func QueryAggregate[A any, K any, V any](sh *SkipHash[K, V], low, high K) (A, bool) {
+	var agg *monoid[K, V, A]
+	for _, aug := range sh.augs {
+		if m, ok := aug.(*monoid[K, V, A]); ok {
+			agg = m
+			break
+		}
+	}
+	if agg == nil {
+		panic("skiphash: QueryAggregate requires a WithAggregate of the same summary type")
+	}
+	var zero A
+	low, high = sh.normalizeKey(low), sh.normalizeKey(high)
+	if sh.compare(low, high) > 0 {
+		return zero, false
+	}
+	sh.faultIn(low, high)
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+	return agg.queryLocked(sh, low, high)
+}
+
+// Number is the constraint for values that WithRangeStats can sum.
+type Number interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr |
+		~float32 | ~float64
+}
+
+type rangeStats[V any] struct {
+	sum, min, max V
+}
+
+// rangeStatsOps carries the arithmetic for rangeStats[V] from
+// WithRangeStats, where V is known to be a Number, to New.
+type rangeStatsOps[V any] struct {
+	combine func(a, b rangeStats[V]) rangeStats[V]
+	leaf    func(V) rangeStats[V]
+}
+
+// WithRangeStats maintains sums, minima and maxima of the values per level
+// so RangeSum, RangeMin and RangeMax run in O(log n). Its type argument must
+// be the value type of the SkipHash. Every write pays O(log n) extra work.
+func WithRangeStats[V Number]() Option {
+	return func(cfg *config) {
+		cfg.rangeStats = rangeStatsOps[V]{
+			combine: func(a, b rangeStats[V]) rangeStats[V] {
+				return rangeStats[V]{sum: a.sum + b.sum, min: min(a.min, b.min), max: max(a.max, b.max)}
+			},
+			leaf: func(v V) rangeStats[V] {
+				return rangeStats[V]{sum: v, min: v, max: v}
+			},
+		}
+	}
+}
+
+// RangeSum returns the sum of the values in [low, high]. It panics unless the
+// SkipHash was created with WithRangeStats.
+func (sh *SkipHash[K, V]) RangeSum(low, high K) V {
+	stats, _ := sh.rangeStatsOf(low, high)
+	return stats.sum
+}
+
+// RangeMin returns the smallest value in [low, high], or false if the
+// interval is empty. It panics unless the SkipHash was created with
+// WithRangeStats.
+func (sh *SkipHash[K, V]) RangeMin(low, high K) (V, bool) {
+	stats, ok := sh.rangeStatsOf(low, high)
+	return stats.min, ok
+}
+
+// RangeMax returns the largest value in [low, high], or false if the
+// interval is empty. It panics unless the SkipHash was created with
+// WithRangeStats.
+func (sh *SkipHash[K, V]) RangeMax(low, high K) (V, bool) {
+	stats, ok := sh.rangeStatsOf(low, high)
+	return stats.max, ok
+}
+
+func (sh *SkipHash[K, V]) rangeStatsOf(low, high K) (rangeStats[V], bool) {
+	if sh.stats == nil {
+		panic("skiphash: range aggregates require WithRangeStats")
+	}
+	low, high = sh.normalizeKey(low), sh.normalizeKey(high)
+	if sh.compare(low, high) > 0 {
+		return rangeStats[V]{}, false
+	}
+	sh.faultIn(low, high)
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+	return sh.stats.queryLocked(sh, low, high)
+}
@@ -0,0 +1,107 @@
+package skiphash
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRangeStatsMatchScan(t *testing.T) {
+	sh := New[int, int](WithRangeStats[int](), WithRandSource(rand.NewSource(1)))
+	r := rand.New(rand.NewSource(2))
+
+	check := func() {
+		for range 50 {
+			low := r.Intn(1100) - 50
+			high := low + r.Intn(400)
+			entries := sh.Range(low, high)
+			sum, lo, hi := 0, 0, 0
+			for i, e := range entries {
+				sum += e.Value
+				if i == 0 || e.Value < lo {
+					lo = e.Value
+				}
+				if i == 0 || e.Value > hi {
+					hi = e.Value
+				}
+			}
+			require.Equal(t, sum, sh.RangeSum(low, high), "sum [%d, %d]", low, high)
+			gotMin, ok := sh.RangeMin(low, high)
+			require.Equal(t, len(entries) > 0, ok)
+			require.Equal(t, lo, gotMin, "min [%d, %d]", low, high)
+			gotMax, _ := sh.RangeMax(low, high)
+			require.Equal(t, hi, gotMax, "max [%d, %d]", low, high)
+		}
+	}
+
+	for range 3000 {
+		k := r.Intn(1000)
+		if r.Intn(4) == 0 {
+			sh.Remove(k)
+		} else {
+			sh.Store(k, r.Intn(2000)-1000)
+		}
+	}
+	check()
+
+	// Tombstones deferred behind an open range must not count.
+	var once bool
+	for range sh.RangeIterContext(context.Background(), 0, 999) {
+		if once {
+			break
+		}
+		once = true
+		for k := 0; k < 1000; k += 2 {
+			sh.Remove(k)
+		}
+		for k := 1; k < 1000; k += 4 {
+			sh.Store(k, k)
+		}
+		check()
+	}
+	check()
+	assert.Zero(t, sh.RangeSum(10, 5))
+}
+
+func TestRangeStatsBulkLoad(t *testing.T) {
+	entries := make([]Entry[int, float64], 1000)
+	for i := range entries {
+		entries[i] = Entry[int, float64]{Key: i, Value: float64(i) / 2}
+	}
+	sh, err := NewFromSorted(entries, WithRangeStats[float64]())
+	require.NoError(t, err)
+	assert.Equal(t, 2475.0, sh.RangeSum(0, 99))
+	maxV, ok := sh.RangeMax(0, 999)
+	assert.True(t, ok)
+	assert.Equal(t, 499.5, maxV)
+
+	assert.Panics(t, func() { New[int, int]().RangeSum(0, 1) })
+	assert.Panics(t, func() { New[int, string](WithRangeStats[int]()) })
+}
+
+func TestQueryAggregate(t *testing.T) {
+	type box struct{ minX, maxX int }
+	sh := New[int, int](
+		WithAggregate(func(a, b string) string { return a + b },
+			func(k, _ int) string { return string(rune('a' + k%26)) }),
+		WithAggregate(func(a, b box) box { return box{min(a.minX, b.minX), max(a.maxX, b.maxX)} },
+			func(_, v int) box { return box{v, v} }),
+	)
+	for k := range 26 {
+		sh.Store(k, 100-k*k)
+	}
+	sh.Remove(3)
+
+	word, ok := QueryAggregate[string](sh, 1, 6)
+	assert.True(t, ok)
+	assert.Equal(t, "bcefg", word, "combine runs left to right")
+	bounds, ok := QueryAggregate[box](sh, 0, 10)
+	assert.True(t, ok)
+	assert.Equal(t, box{0, 100}, bounds)
+	_, ok = QueryAggregate[box](sh, 3, 3)
+	assert.False(t, ok)
+	assert.Panics(t, func() { QueryAggregate[int](sh, 0, 1) })
+}
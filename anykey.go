@@ -0,0 +1,112 @@
+package skiphash
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// AnyKey boxes a key whose type is only known at run time. The type must be
+// registered with RegisterKeyType first. Keys of different registered types
+// may share one SkipHash; they order by registration order first and by the
+// type's comparator within a type.
+type AnyKey struct {
+	typ *anyKeyType
+	v   any
+}
+
+type anyKeyType struct {
+	rank    int
+	compare func(a, b any) int
+	hash    func(any) uint64
+}
+
+var anyKeyTypes struct {
+	mu    sync.RWMutex
+	types map[reflect.Type]*anyKeyType
+}
+
+// RegisterKeyType makes T usable inside AnyKey. compare orders two values
+// and hash must agree with it: values comparing equal must hash equally.
+// Registering the same type again replaces its functions but keeps its
+// position in the cross-type order.
+func RegisterKeyType[T any](compare func(a, b T) int, hash func(T) uint64) {
+	if compare == nil || hash == nil {
+		panic("skiphash: RegisterKeyType requires non-nil compare and hash functions")
+	}
+	rt := reflect.TypeFor[T]()
+
+	anyKeyTypes.mu.Lock()
+	defer anyKeyTypes.mu.Unlock()
+	if anyKeyTypes.types == nil {
+		anyKeyTypes.types = make(map[reflect.Type]*anyKeyType)
+	}
+	rank := len(anyKeyTypes.types)
+	if prev, ok := anyKeyTypes.types[rt]; ok {
+		rank = prev.rank
+	}
+	anyKeyTypes.types[rt] = &anyKeyType{
+		rank:    rank,
+		compare: func(a, b any) int { return compare(a.(T), b.(T)) },
+		hash:    func(v any) uint64 { return hash(v.(T)) },
+	}
+}
+
+// AnyKeyOf boxes v, failing if its dynamic type was not registered.
+func AnyKeyOf(v any) (AnyKey, error) {
+	anyKeyTypes.mu.RLock()
+	typ, ok := anyKeyTypes.types[reflect.TypeOf(v)]
+	anyKeyTypes.mu.RUnlock()
+	if !ok {
+		return AnyKey{}, fmt.Errorf("skiphash: key type %T is not registered", v)
+	}
+	return AnyKey{typ: typ, v: v}, nil
+}
+
+// MustAnyKey is like AnyKeyOf but panics on unregistered types.
+func MustAnyKey(v any) AnyKey {
+	k, err := AnyKeyOf(v)
+	if err != nil {
+		panic(err)
+	}
+	return k
+}
+
+// Value returns the boxed key.
+func (k AnyKey) Value() any {
+	return k.v
+}
+
+func (k AnyKey) String() string {
+	return fmt.Sprint(k.v)
+}
+
+// NewAnyKey creates a SkipHash keyed by AnyKey.
+func NewAnyKey[V any](opts ...Option) *SkipHash[AnyKey, V] {
+	return newSkipHash(compareAnyKeys, newHashIndex[AnyKey, V](hashAnyKey, compareAnyKeys), opts)
+}
+
+func compareAnyKeys(a, b AnyKey) int {
+	switch {
+	case a.typ == b.typ:
+		if a.typ == nil {
+			return 0
+		}
+		return a.typ.compare(a.v, b.v)
+	case a.typ == nil:
+		return -1
+	case b.typ == nil:
+		return 1
+	case a.typ.rank < b.typ.rank:
+		return -1
+	default:
+		return 1
+	}
+}
+
+func hashAnyKey(k AnyKey) uint64 {
+	if k.typ == nil {
+		return 0
+	}
+	return k.typ.hash(k.v) ^ uint64(k.typ.rank)*0x9e3779b97f4a7c15
+}
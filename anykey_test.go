@@ -0,0 +1,41 @@
+package skiphash
+
+import (
+	"cmp"
+	"hash/maphash"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var anyKeySeed = maphash.MakeSeed()
+
+func init() {
+	RegisterKeyType(cmp.Compare[int], func(v int) uint64 { return maphash.Comparable(anyKeySeed, v) })
+	RegisterKeyType(cmp.Compare[string], func(v string) uint64 { return maphash.String(anyKeySeed, v) })
+}
+
+func TestSkipHashAnyKey(t *testing.T) {
+	sh := NewAnyKey[string](WithRandSource(rand.NewSource(19)))
+	for _, k := range []any{3, "b", 1, "a", 2} {
+		assert.True(t, sh.Insert(MustAnyKey(k), "v"))
+	}
+	assert.False(t, sh.Insert(MustAnyKey(2), "dup"))
+	assert.True(t, sh.Contains(MustAnyKey("a")))
+	assert.False(t, sh.Contains(MustAnyKey("c")))
+
+	var got []any
+	for _, e := range sh.RangeAll() {
+		got = append(got, e.Key.Value())
+	}
+	assert.Equal(t, []any{1, 2, 3, "a", "b"}, got, "types order by registration, then by value")
+
+	entries := sh.Range(MustAnyKey(2), MustAnyKey("a"))
+	assert.Len(t, entries, 3)
+
+	_, err := AnyKeyOf(1.5)
+	require.Error(t, err)
+	assert.Panics(t, func() { MustAnyKey(struct{}{}) })
+}
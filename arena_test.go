@@ -0,0 +1,43 @@
+package skiphash
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSkipHashArena(t *testing.T) {
+	sh := New[int, int](WithArena(), WithMaxLevel(8), WithRandSource(rand.NewSource(1)))
+	const n = 3 * arenaChunk
+	for k := range n {
+		sh.Store(k, k)
+	}
+	for k := 0; k < n; k += 3 {
+		sh.Remove(k)
+	}
+	require.Equal(t, n-arenaChunk, sh.Len())
+	for k := range n {
+		v, ok := sh.Get(k)
+		assert.Equal(t, k%3 != 0, ok, "key %d", k)
+		if ok {
+			assert.Equal(t, k, v)
+		}
+	}
+	assert.Equal(t, 2, sh.RangeCount(0, 3))
+}
+
+func TestSkipHashArenaWithPool(t *testing.T) {
+	sh := New[int, int](WithArena(), WithNodePool())
+	for round := range 10 {
+		for k := range 500 {
+			sh.Store(k, round)
+		}
+		for k := range 500 {
+			sh.Remove(k)
+		}
+	}
+	sh.Store(1, 1)
+	assert.Equal(t, []Entry[int, int]{{Key: 1, Value: 1}}, sh.RangeAll())
+}
@@ -0,0 +1,122 @@
+package skiphash
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Auto snapshots are named by their UnixNano start time, zero-padded so
+// that name order is age order.
+const (
+	autoSnapshotPrefix = "snapshot-"
+	autoSnapshotSuffix = ".skh"
+)
+
+// AutoSnapshotStats reports the work done by StartAutoSnapshot.
+type AutoSnapshotStats struct {
+	Snapshots uint64
+	Failures  uint64
+	// LastPath and LastSnapshot describe the last snapshot written; they
+	// are zero if none has been.
+	LastPath     string
+	LastSnapshot time.Time
+	// LastErr is the error of the last failed attempt, if any.
+	LastErr error
+}
+
+type autoSnapshotState struct {
+	mu    sync.Mutex
+	stats AutoSnapshotStats
+}
+
+// StartAutoSnapshot writes a snapshot file to dir with SaveToFile every
+// interval until ctx is done, deleting all but the newest keep of them, or
+// none if keep is not positive. Snapshots do not block writers; see SaveTo.
+// Failures are counted in AutoSnapshotStats and retried at the next tick.
+func (sh *SkipHash[K, V]) StartAutoSnapshot(ctx context.Context, dir string, interval time.Duration, keep int) {
+	sh.unsupportedInFineGrained()
+	if interval <= 0 {
+		panic("skiphash: StartAutoSnapshot requires a positive interval")
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				sh.autoSnapshot(dir, keep)
+			}
+		}
+	}()
+}
+
+// AutoSnapshotStats returns the counters of the services started with
+// StartAutoSnapshot.
+func (sh *SkipHash[K, V]) AutoSnapshotStats() AutoSnapshotStats {
+	sh.snapshots.mu.Lock()
+	defer sh.snapshots.mu.Unlock()
+	return sh.snapshots.stats
+}
+
+func (sh *SkipHash[K, V]) autoSnapshot(dir string, keep int) {
+	now := time.Now()
+	path := filepath.Join(dir, fmt.Sprintf("%s%020d%s", autoSnapshotPrefix, now.UnixNano(), autoSnapshotSuffix))
+	err := os.MkdirAll(dir, 0o755)
+	if err == nil {
+		err = sh.SaveToFile(path)
+	}
+	if err == nil && keep > 0 {
+		err = rotateSnapshots(dir, keep)
+	}
+
+	s := &sh.snapshots
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil {
+		s.stats.Failures++
+		s.stats.LastErr = err
+		return
+	}
+	s.stats.Snapshots++
+	s.stats.LastPath, s.stats.LastSnapshot = path, now
+}
+
+// snapshotFiles returns the paths of the auto snapshots in dir, oldest
+// first.
+func snapshotFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, e := range entries {
+		name := e.Name()
+		if e.Type().IsRegular() && strings.HasPrefix(name, autoSnapshotPrefix) && strings.HasSuffix(name, autoSnapshotSuffix) {
+			paths = append(paths, filepath.Join(dir, name))
+		}
+	}
+	slices.Sort(paths)
+	return paths, nil
+}
+
+// rotateSnapshots deletes all but the newest keep auto snapshots in dir.
+func rotateSnapshots(dir string, keep int) error {
+	paths, err := snapshotFiles(dir)
+	if err != nil {
+		return err
+	}
+	for _, path := range paths[:max(len(paths)-keep, 0)] {
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
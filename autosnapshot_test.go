@@ -0,0 +1,58 @@
+package skiphash
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSkipHashAutoSnapshot(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "snaps")
+	sh := New[int, int]()
+	for k := range 100 {
+		sh.Store(k, k)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sh.StartAutoSnapshot(ctx, dir, 2*time.Millisecond, 2)
+
+	assert.Eventually(t, func() bool {
+		return sh.AutoSnapshotStats().Snapshots >= 5
+	}, 2*time.Second, 2*time.Millisecond)
+	cancel()
+	// Let a tick in flight finish before inspecting the directory.
+	time.Sleep(20 * time.Millisecond)
+
+	stats := sh.AutoSnapshotStats()
+	assert.Zero(t, stats.Failures)
+	assert.False(t, stats.LastSnapshot.IsZero())
+	paths, err := snapshotFiles(dir)
+	require.NoError(t, err)
+	assert.Len(t, paths, 2)
+	assert.Equal(t, stats.LastPath, paths[1])
+
+	out := New[int, int]()
+	require.NoError(t, out.LoadFromFile(paths[1]))
+	assert.Equal(t, sh.RangeAll(), out.RangeAll())
+}
+
+func TestSkipHashAutoSnapshotFailure(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "file")
+	require.NoError(t, os.WriteFile(file, nil, 0o644))
+	sh := New[int, int]()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sh.StartAutoSnapshot(ctx, file, time.Millisecond, 1)
+	assert.Eventually(t, func() bool {
+		return sh.AutoSnapshotStats().Failures > 0
+	}, 2*time.Second, time.Millisecond)
+	assert.Error(t, sh.AutoSnapshotStats().LastErr)
+	assert.Zero(t, sh.AutoSnapshotStats().Snapshots)
+}
@@ -0,0 +1,22 @@
+package skiphash
+
+import "bytes"
+
+// MarshalBinary encodes the live entries in the SaveTo format, using the
+// codecs set by WithKeyCodec and WithValueCodec. gob uses it for SkipHash
+// values nested in other payloads.
+func (sh *SkipHash[K, V]) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := sh.SaveTo(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary replaces the contents of sh with entries written by
+// MarshalBinary, rebuilding the index and levels with sh's own options. Like
+// UnmarshalJSON it needs a SkipHash created with New or NewFunc; a decoder
+// that allocates a zero value gets ErrUninitialized.
+func (sh *SkipHash[K, V]) UnmarshalBinary(data []byte) error {
+	return sh.LoadFrom(bytes.NewReader(data))
+}
@@ -0,0 +1,52 @@
+package skiphash
+
+import (
+	"bytes"
+	"encoding/gob"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBinaryRoundTripInGobPayload(t *testing.T) {
+	type payload struct {
+		Shard int
+		Index *SkipHash[int, string]
+	}
+
+	sh := New[int, string]()
+	for i := range 200 {
+		sh.Store(i, string(rune('a'+i%26)))
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, gob.NewEncoder(&buf).Encode(payload{Shard: 7, Index: sh}))
+
+	got := payload{Index: New[int, string]()}
+	require.NoError(t, gob.NewDecoder(&buf).Decode(&got))
+	require.Equal(t, 7, got.Shard)
+	require.Equal(t, sh.RangeAll(), got.Index.RangeAll())
+	require.Equal(t, 50, got.Index.Rank(50))
+	require.Equal(t, 200, got.Index.RangeCount(0, 1000))
+
+	v, ok := got.Index.Get(27)
+	require.True(t, ok)
+	require.Equal(t, "b", v)
+}
+
+func TestBinaryUnmarshalReplacesAndValidates(t *testing.T) {
+	src := New[string, int]()
+	src.Store("x", 1)
+	data, err := src.MarshalBinary()
+	require.NoError(t, err)
+
+	dst := New[string, int]()
+	dst.Store("old", 0)
+	require.NoError(t, dst.UnmarshalBinary(data))
+	require.Equal(t, []Entry[string, int]{{Key: "x", Value: 1}}, dst.RangeAll())
+
+	require.Error(t, dst.UnmarshalBinary([]byte("garbage")))
+
+	var zero SkipHash[string, int]
+	require.ErrorIs(t, zero.UnmarshalBinary(data), ErrUninitialized)
+}
@@ -0,0 +1,173 @@
+package skiphash
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"hash/crc32"
+	"hash/fnv"
+	"io"
+)
+
+// Block export layout, fixed-size integers little-endian:
+//
+//	data blocks | filter block | index block | meta block | footer
+//
+// Every block is followed by the CRC-32C of its bytes as a uint32; block
+// sizes do not include it. A data block holds entries in key order, each as
+// uvarint key length | key | uvarint value length | value, compressed as a
+// whole. The index block has one entry per data block: uvarint length |
+// last key | uvarint offset | uvarint size. The filter block is a bloom
+// filter over the keys: uvarint k | bit array, where a key sets bits
+// (h1 + i*h2) mod m for i < k, computed in uint64; h1 and h2 are the low and
+// high 32 bits of the key's FNV-1a 64 hash, m is the number of bits, and bit
+// j is bit j%8 of byte j/8. The meta block holds the key codec, value codec
+// and block compression names as uvarint-length strings, then the uvarint
+// entry count. The footer is the offset and size of the filter, index and
+// meta blocks as six uint64s, then magic "SKHT" and the format as a uint32.
+const (
+	blocksMagic       = "SKHT"
+	blocksFormat      = 1
+	blocksFooterSize  = 6*8 + len(blocksMagic) + 4
+	bloomBitsPerKey   = 10
+	bloomHashesPerKey = 7
+)
+
+// ExportBlocks writes the entries live at the moment of the call to w as a
+// sorted table of data blocks of about blockSize bytes before compression,
+// with a block index and a bloom filter, for readers of the SSTable-style
+// layout described in the source. Keys and values are encoded with the
+// codecs of the SkipHash; the block index is only binary-searchable on the
+// encoded keys when the key codec preserves order, as the string and []byte
+// codecs do. Blocks are compressed with the WithCompression Compressor, or
+// gzip by default. The output is meant for other readers and is not
+// encrypted by WithSnapshotCipher. Like SaveTo, ExportBlocks does not block
+// writers. It panics if blockSize is not positive.
+func (sh *SkipHash[K, V]) ExportBlocks(w io.Writer, blockSize int) error {
+	sh.unsupportedInFineGrained()
+	if blockSize <= 0 {
+		panic("skiphash: ExportBlocks requires a positive block size")
+	}
+	sh.faultInAll()
+	keys, values := sh.keyCodec, sh.valueCodec
+	compressor := sh.compressor
+	if compressor == nil {
+		compressor = Gzip(gzip.DefaultCompression)
+	}
+
+	sh.mu.Lock()
+	ver := sh.rqc.onRangeLocked()
+	sh.mu.Unlock()
+	defer func() {
+		sh.mu.Lock()
+		sh.rqc.afterRangeLocked(sh, ver)
+		sh.mu.Unlock()
+	}()
+
+	bw := bufio.NewWriter(w)
+	out := &blockWriter{w: bw}
+	var (
+		raw, index, lastKey []byte
+		compressed          bytes.Buffer
+		hashes              []uint64
+		err                 error
+	)
+	flush := func() error {
+		if len(raw) == 0 {
+			return nil
+		}
+		compressed.Reset()
+		zw, err := compressor.NewWriter(&compressed)
+		if err != nil {
+			return err
+		}
+		if _, err := zw.Write(raw); err != nil {
+			return err
+		}
+		if err := zw.Close(); err != nil {
+			return err
+		}
+		off, size, err := out.write(compressed.Bytes())
+		index = appendChunk(index, lastKey)
+		index = binary.AppendUvarint(binary.AppendUvarint(index, off), size)
+		raw = raw[:0]
+		return err
+	}
+	sh.walkAtVersion(sh.head, nil, ver, func(key K, value V) bool {
+		start := len(raw)
+		if raw, err = appendRecord(raw, keys, values, key, value); err != nil {
+			return false
+		}
+		n, width := binary.Uvarint(raw[start:])
+		kb := raw[start+width : start+width+int(n)]
+		h := fnv.New64a()
+		h.Write(kb)
+		hashes = append(hashes, h.Sum64())
+		lastKey = append(lastKey[:0], kb...)
+		if len(raw) >= blockSize {
+			err = flush()
+		}
+		return err == nil
+	})
+	if err == nil {
+		err = flush()
+	}
+	if err != nil {
+		return err
+	}
+
+	var footer []byte
+	meta := appendChunk(nil, []byte(keys.name))
+	meta = appendChunk(meta, []byte(values.name))
+	meta = appendChunk(meta, []byte(compressor.Name()))
+	meta = binary.AppendUvarint(meta, uint64(len(hashes)))
+	for _, block := range [][]byte{bloomFilter(hashes), index, meta} {
+		off, size, err := out.write(block)
+		if err != nil {
+			return err
+		}
+		footer = binary.LittleEndian.AppendUint64(footer, off)
+		footer = binary.LittleEndian.AppendUint64(footer, size)
+	}
+	footer = append(footer, blocksMagic...)
+	footer = binary.LittleEndian.AppendUint32(footer, blocksFormat)
+	if _, err := bw.Write(footer); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// blockWriter writes checksummed blocks and tracks their offsets.
+type blockWriter struct {
+	w   io.Writer
+	off uint64
+}
+
+func (b *blockWriter) write(block []byte) (off, size uint64, err error) {
+	off, size = b.off, uint64(len(block))
+	if _, err := b.w.Write(block); err != nil {
+		return 0, 0, err
+	}
+	if _, err := b.w.Write(binary.LittleEndian.AppendUint32(nil, crc32.Checksum(block, snapshotCRC))); err != nil {
+		return 0, 0, err
+	}
+	b.off += size + 4
+	return off, size, nil
+}
+
+// bloomFilter builds the filter block for keys with the given FNV-1a 64
+// hashes, sized for about a 1% false positive rate.
+func bloomFilter(hashes []uint64) []byte {
+	m := uint64(max(64, len(hashes)*bloomBitsPerKey)+7) / 8 * 8
+	filter := binary.AppendUvarint(nil, bloomHashesPerKey)
+	bits := make([]byte, m/8)
+	for _, h := range hashes {
+		h1, h2 := h&0xffffffff, h>>32
+		for i := uint64(0); i < bloomHashesPerKey; i++ {
+			j := (h1 + i*h2) % m
+			bits[j/8] |= 1 << (j % 8)
+		}
+	}
+	return append(filter, bits...)
+}
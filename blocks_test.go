@@ -0,0 +1,176 @@
+package skiphash
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"hash/fnv"
+	"io"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// blockTable reads ExportBlocks output the way an external reader would,
+// from the documented layout alone.
+type blockTable struct {
+	t      *testing.T
+	data   []byte
+	filter []byte
+	k      uint64
+	index  []blockHandle
+	meta   []string
+	count  uint64
+}
+
+type blockHandle struct {
+	last      string
+	off, size uint64
+}
+
+func openBlockTable(t *testing.T, data []byte) *blockTable {
+	footer := data[len(data)-blocksFooterSize:]
+	require.Equal(t, "SKHT", string(footer[48:52]))
+	require.Equal(t, uint32(1), binary.LittleEndian.Uint32(footer[52:]))
+	tb := &blockTable{t: t, data: data}
+	block := func(i int) []byte {
+		off := binary.LittleEndian.Uint64(footer[16*i:])
+		size := binary.LittleEndian.Uint64(footer[16*i+8:])
+		return tb.block(off, size)
+	}
+
+	filter := block(0)
+	k, n := binary.Uvarint(filter)
+	tb.k, tb.filter = k, filter[n:]
+
+	r := bufio.NewReader(bytes.NewReader(block(1)))
+	for {
+		last, err := readChunk(r)
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		off, err := binary.ReadUvarint(r)
+		require.NoError(t, err)
+		size, err := binary.ReadUvarint(r)
+		require.NoError(t, err)
+		tb.index = append(tb.index, blockHandle{last: string(last), off: off, size: size})
+	}
+
+	r = bufio.NewReader(bytes.NewReader(block(2)))
+	for range 3 {
+		name, err := readChunk(r)
+		require.NoError(t, err)
+		tb.meta = append(tb.meta, string(name))
+	}
+	count, err := binary.ReadUvarint(r)
+	require.NoError(t, err)
+	tb.count = count
+	return tb
+}
+
+func (tb *blockTable) block(off, size uint64) []byte {
+	block := tb.data[off : off+size]
+	crc := binary.LittleEndian.Uint32(tb.data[off+size:])
+	require.Equal(tb.t, crc32.Checksum(block, crc32.MakeTable(crc32.Castagnoli)), crc)
+	return block
+}
+
+func (tb *blockTable) mayContain(key string) bool {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	sum := h.Sum64()
+	h1, h2 := sum&0xffffffff, sum>>32
+	m := uint64(len(tb.filter)) * 8
+	for i := range tb.k {
+		j := (h1 + i*h2) % m
+		if tb.filter[j/8]&(1<<(j%8)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// entries decompresses data block i.
+func (tb *blockTable) entries(i int) [][2]string {
+	h := tb.index[i]
+	zr, err := gzip.NewReader(bytes.NewReader(tb.block(h.off, h.size)))
+	require.NoError(tb.t, err)
+	r := bufio.NewReader(zr)
+	var out [][2]string
+	for {
+		key, err := readChunk(r)
+		if err == io.EOF {
+			return out
+		}
+		require.NoError(tb.t, err)
+		value, err := readChunk(r)
+		require.NoError(tb.t, err)
+		out = append(out, [2]string{string(key), string(value)})
+	}
+}
+
+func (tb *blockTable) get(key string) (string, bool) {
+	if !tb.mayContain(key) {
+		return "", false
+	}
+	i := sort.Search(len(tb.index), func(i int) bool { return tb.index[i].last >= key })
+	if i == len(tb.index) {
+		return "", false
+	}
+	for _, e := range tb.entries(i) {
+		if e[0] == key {
+			return e[1], true
+		}
+	}
+	return "", false
+}
+
+func TestExportBlocks(t *testing.T) {
+	sh := New[string, string]()
+	for i := range 5000 {
+		sh.Store(fmt.Sprintf("key-%05d", i), fmt.Sprintf("value-%d", i))
+	}
+	var buf bytes.Buffer
+	require.NoError(t, sh.ExportBlocks(&buf, 4096))
+
+	tb := openBlockTable(t, buf.Bytes())
+	require.Equal(t, []string{"string", "string", "gzip"}, tb.meta)
+	require.Equal(t, uint64(5000), tb.count)
+	require.Greater(t, len(tb.index), 10)
+
+	var all [][2]string
+	for i := range tb.index {
+		block := tb.entries(i)
+		require.Equal(t, tb.index[i].last, block[len(block)-1][0])
+		all = append(all, block...)
+	}
+	require.Len(t, all, 5000)
+	for i, e := range sh.RangeAll() {
+		require.Equal(t, [2]string{e.Key, e.Value}, all[i])
+	}
+
+	for _, i := range []int{0, 1234, 4999} {
+		value, ok := tb.get(fmt.Sprintf("key-%05d", i))
+		require.True(t, ok)
+		require.Equal(t, fmt.Sprintf("value-%d", i), value)
+	}
+	falsePositives := 0
+	for i := range 1000 {
+		if tb.mayContain(fmt.Sprintf("absent-%d", i)) {
+			falsePositives++
+		}
+	}
+	require.Less(t, falsePositives, 50)
+
+	buf.Reset()
+	require.NoError(t, New[string, string]().ExportBlocks(&buf, 4096))
+	tb = openBlockTable(t, buf.Bytes())
+	require.Empty(t, tb.index)
+	require.Zero(t, tb.count)
+	require.Panics(t, func() { _ = sh.ExportBlocks(&buf, 0) })
+}
@@ -0,0 +1,93 @@
+package skiphash
+
+// Bound says how RangeBounds treats one end of an interval.
+type Bound int
+
+const (
+	// Inclusive includes the bound key. It is the zero value.
+	Inclusive Bound = iota
+	// Exclusive excludes the bound key.
+	Exclusive
+	// Unbounded ignores the bound key and extends the interval to the end
+	// of the key order.
+	Unbounded
+)
+
+// BoundOptions sets the semantics of both ends of a RangeBounds interval.
+// The zero value is the closed interval Range uses.
+type BoundOptions struct {
+	Low, High Bound
+}
+
+// HalfOpen is [low, high).
+func HalfOpen() BoundOptions { return BoundOptions{High: Exclusive} }
+
+// From is [low, ∞); high is ignored.
+func From() BoundOptions { return BoundOptions{High: Unbounded} }
+
+// Below is (-∞, high); low is ignored.
+func Below() BoundOptions { return BoundOptions{Low: Unbounded, High: Exclusive} }
+
+// All is every key; low and high are ignored.
+func All() BoundOptions { return BoundOptions{Low: Unbounded, High: Unbounded} }
+
+// RangeBounds returns the live entries between low and high, in order, with
+// each end included, excluded or left open as opts says.
+func (sh *SkipHash[K, V]) RangeBounds(low, high K, opts BoundOptions) []Entry[K, V] {
+	low, high = sh.normalizeKey(low), sh.normalizeKey(high)
+	lowKey, highKey := &low, &high
+	if opts.Low == Unbounded {
+		lowKey = nil
+	}
+	if opts.High == Unbounded {
+		highKey = nil
+	}
+	if lowKey != nil && highKey != nil {
+		if c := sh.compare(low, high); c > 0 || c == 0 && (opts.Low == Exclusive || opts.High == Exclusive) {
+			return nil
+		}
+	}
+	afterLow := func(key K) bool {
+		if lowKey == nil {
+			return true
+		}
+		c := sh.compare(key, low)
+		return c > 0 || c == 0 && opts.Low == Inclusive
+	}
+	beforeHigh := func(key K) bool {
+		if highKey == nil {
+			return true
+		}
+		c := sh.compare(key, high)
+		return c < 0 || c == 0 && opts.High == Inclusive
+	}
+
+	entries := make([]Entry[K, V], 0, defaultEntryCap)
+	if sh.fine != nil {
+		for _, e := range sh.fine.entries(lowKey, highKey) {
+			if afterLow(e.Key) && beforeHigh(e.Key) {
+				entries = append(entries, e)
+			}
+		}
+		return entries
+	}
+	if lowKey != nil && highKey != nil {
+		sh.faultIn(low, high)
+	} else {
+		sh.faultInAll()
+	}
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+
+	node := sh.head.next[0]
+	if lowKey != nil {
+		node = sh.lowerBoundLocked(low)
+	}
+	for ; node != sh.tail && beforeHigh(node.key); node = node.next[0] {
+		if node.rTime == 0 && afterLow(node.key) {
+			sh.touch(node)
+			entries = append(entries, Entry[K, V]{Key: node.key, Value: *node.value.Load()})
+		}
+	}
+	return entries
+}
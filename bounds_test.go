@@ -0,0 +1,35 @@
+package skiphash
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRangeBounds(t *testing.T) {
+	for _, mode := range []ConcurrencyMode{GlobalLock, FineGrained} {
+		sh := New[float64, int](WithConcurrencyMode(mode))
+		for i := range 10 {
+			sh.Store(float64(i)/2, i)
+		}
+		keys := func(entries []Entry[float64, int]) []float64 {
+			var out []float64
+			for _, e := range entries {
+				out = append(out, e.Key)
+			}
+			return out
+		}
+
+		assert.Equal(t, []float64{1, 1.5, 2}, keys(sh.RangeBounds(1, 2, BoundOptions{})))
+		assert.Equal(t, []float64{1, 1.5}, keys(sh.RangeBounds(1, 2, HalfOpen())))
+		assert.Equal(t, []float64{1.5}, keys(sh.RangeBounds(1, 2, BoundOptions{Low: Exclusive, High: Exclusive})))
+		assert.Equal(t, []float64{3.5, 4, 4.5}, keys(sh.RangeBounds(3.5, 0, From())))
+		assert.Equal(t, []float64{0, 0.5}, keys(sh.RangeBounds(99, 1, Below())))
+		assert.Len(t, sh.RangeBounds(0, 0, All()), 10)
+		assert.Equal(t, []float64{4, 4.5}, keys(sh.RangeBounds(3.5, 0, BoundOptions{Low: Exclusive, High: Unbounded})))
+
+		assert.Nil(t, sh.RangeBounds(2, 2, HalfOpen()))
+		assert.Nil(t, sh.RangeBounds(3, 2, BoundOptions{}))
+		assert.Equal(t, []float64{2}, keys(sh.RangeBounds(2, 2, BoundOptions{})))
+	}
+}
@@ -0,0 +1,53 @@
+package skiphash
+
+// WithBucketVersions keeps a mutation counter per coarse key bucket, as
+// computed by bucket, so caches can invalidate only the buckets whose
+// BucketVersion changed. Inserts, updates and removals all bump the counter
+// of the affected key's bucket.
+func WithBucketVersions[K any, B comparable](bucket func(K) B) Option {
+	return func(cfg *config) {
+		if bucket == nil {
+			return
+		}
+		cfg.buckets = func() bucketTracker[K] {
+			return &bucketCounters[K, B]{
+				bucket:   bucket,
+				versions: make(map[B]uint64),
+			}
+		}
+	}
+}
+
+type bucketTracker[K any] interface {
+	bump(key K)
+	version(bucket any) uint64
+}
+
+type bucketCounters[K any, B comparable] struct {
+	bucket   func(K) B
+	versions map[B]uint64
+}
+
+func (b *bucketCounters[K, B]) bump(key K) {
+	b.versions[b.bucket(key)]++
+}
+
+func (b *bucketCounters[K, B]) version(bucket any) uint64 {
+	typed, ok := bucket.(B)
+	if !ok {
+		return 0
+	}
+	return b.versions[typed]
+}
+
+// BucketVersion returns the mutation counter of bucket, which must have the
+// type produced by the WithBucketVersions function. Buckets that were never
+// written, or a SkipHash without bucket versions, report 0.
+func (sh *SkipHash[K, V]) BucketVersion(bucket any) uint64 {
+	if sh.buckets == nil {
+		return 0
+	}
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+	return sh.buckets.version(bucket)
+}
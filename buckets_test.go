@@ -0,0 +1,30 @@
+package skiphash
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSkipHashBucketVersions(t *testing.T) {
+	sh := New[int, int](WithBucketVersions(func(k int) int { return k / 100 }))
+
+	sh.Insert(1, 1)
+	sh.Insert(150, 1)
+	assert.Equal(t, uint64(1), sh.BucketVersion(0))
+	assert.Equal(t, uint64(1), sh.BucketVersion(1))
+
+	sh.Store(1, 2)
+	sh.Store(2, 2)
+	assert.Equal(t, uint64(3), sh.BucketVersion(0))
+
+	assert.False(t, sh.Insert(1, 3), "rejected writes must not bump")
+	assert.False(t, sh.Remove(99))
+	assert.Equal(t, uint64(3), sh.BucketVersion(0))
+
+	sh.Remove(150)
+	assert.Equal(t, uint64(2), sh.BucketVersion(1))
+	assert.Equal(t, uint64(0), sh.BucketVersion(7))
+	assert.Equal(t, uint64(0), sh.BucketVersion("0"), "mismatched bucket type")
+	assert.Equal(t, uint64(0), New[int, int]().BucketVersion(0))
+}
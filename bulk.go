@@ -0,0 +1,242 @@
+package skiphash
+
+import (
+	"cmp"
+	"fmt"
+	"math/bits"
+)
+
+// InsertSortedStrict inserts entries that must already be sorted by strictly
+// increasing key. The input is validated before anything is written: the
+// first out-of-order key yields ErrUnsorted, a repeated key ErrDuplicateKey
+// and a key that is already live ErrKeyExists, each wrapped with the index of
+// the offending entry. A quota rejection stops the load at that entry and
+// leaves the entries before it inserted.
+func (sh *SkipHash[K, V]) InsertSortedStrict(entries []Entry[K, V]) error {
+	sh.unsupportedInFineGrained()
+	if sh.normalize != nil {
+		normalized := make([]Entry[K, V], len(entries))
+		for i, e := range entries {
+			normalized[i] = Entry[K, V]{Key: sh.normalize(e.Key), Value: e.Value}
+		}
+		entries = normalized
+	}
+	if err := sh.checkSorted(entries); err != nil {
+		return err
+	}
+
+	sh.mu.Lock()
+	defer sh.unlock()
+
+	if len(entries) > 0 {
+		sh.faultInLocked(entries[0].Key, entries[len(entries)-1].Key)
+	}
+	sh.beginBatchLocked()
+	defer sh.endBatchLocked()
+	for i, e := range entries {
+		if _, exists := sh.index.get(e.Key); exists {
+			return fmt.Errorf("%w: entry %d (key %v)", ErrKeyExists, i, e.Key)
+		}
+	}
+	for i, e := range entries {
+		if err := sh.insertLocked(e.Key, e.Value); err != nil {
+			return fmt.Errorf("entry %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// InsertIfRangeEmpty inserts key only if no live key lies in [low, high],
+// checking and inserting in one critical section. key does not need to fall
+// inside the interval. It reports whether the entry was inserted.
+func (sh *SkipHash[K, V]) InsertIfRangeEmpty(low, high K, key K, value V) bool {
+	sh.unsupportedInFineGrained()
+	low, high, key = sh.normalizeKey(low), sh.normalizeKey(high), sh.normalizeKey(key)
+	sh.mu.Lock()
+	defer sh.unlock()
+	sh.faultInLocked(key, key)
+
+	if sh.compare(low, high) <= 0 {
+		sh.faultInLocked(low, high)
+		if sh.rankLocked(high, true)-sh.rankLocked(low, false) > 0 {
+			return false
+		}
+	}
+	if _, exists := sh.index.get(key); exists {
+		return false
+	}
+	return sh.insertLocked(key, value) == nil
+}
+
+// TrimBelow removes every key below cutoff under one write lock and returns
+// how many it removed. It walks the prefix of the base level instead of
+// searching for each key, which suits retention of time-keyed data.
+func (sh *SkipHash[K, V]) TrimBelow(cutoff K) int {
+	sh.unsupportedInFineGrained()
+	cutoff = sh.normalizeKey(cutoff)
+	sh.faultInAll()
+	sh.mu.Lock()
+	defer sh.unlock()
+
+	var doomed []*slNode[K, V]
+	for node := sh.head.next[0]; node != sh.tail && sh.compare(node.key, cutoff) < 0; node = node.next[0] {
+		if node.rTime == 0 {
+			doomed = append(doomed, node)
+		}
+	}
+	sh.beginBatchLocked()
+	defer sh.endBatchLocked()
+	for _, node := range doomed {
+		sh.removeLocked(node)
+	}
+	return len(doomed)
+}
+
+// NewFromSorted builds a SkipHash from entries sorted by strictly increasing
+// key in O(n), linking each level bottom-up instead of searching for every
+// insertion point. Levels are assigned deterministically so the result is
+// perfectly balanced: entry i gets one level per trailing zero bit of i+1.
+// It fails with ErrUnsorted or ErrDuplicateKey like InsertSortedStrict.
+// Options that must vet each insert, such as quotas, caps and weight
+// limits, fall back to ordinary inserts.
+func NewFromSorted[K cmp.Ordered, V any](entries []Entry[K, V], opts ...Option) (*SkipHash[K, V], error) {
+	sh := New[K, V](opts...)
+	if sh.normalize != nil {
+		normalized := make([]Entry[K, V], len(entries))
+		for i, e := range entries {
+			normalized[i] = Entry[K, V]{Key: sh.normalize(e.Key), Value: e.Value}
+		}
+		entries = normalized
+	}
+	if err := sh.checkSorted(entries); err != nil {
+		return nil, err
+	}
+	if sh.fine != nil {
+		for _, e := range entries {
+			sh.fine.put(e.Key, e.Value, true)
+		}
+		return sh, nil
+	}
+
+	sh.mu.Lock()
+	defer sh.unlock()
+	if err := sh.loadSortedLocked(entries); err != nil {
+		return nil, err
+	}
+	return sh, nil
+}
+
+// NewFromMap builds a SkipHash holding the entries of m, sorting the keys
+// once and linking the levels like NewFromSorted. If a key normalizer maps
+// several keys of m to one key, which of their values survives is
+// unspecified. An error comes only from options that reject inserts, such
+// as quotas or weight limits.
+func NewFromMap[K cmp.Ordered, V any](m map[K]V, opts ...Option) (*SkipHash[K, V], error) {
+	sh := New[K, V](opts...)
+	entries := make([]Entry[K, V], 0, len(m))
+	for k, v := range m {
+		entries = append(entries, Entry[K, V]{Key: k, Value: v})
+	}
+	sorted, err := sh.sortForImport(entries, KeepLast, nil)
+	if err != nil {
+		return nil, err
+	}
+	if sh.fine != nil {
+		for _, e := range sorted {
+			sh.fine.put(e.Key, e.Value, true)
+		}
+		return sh, nil
+	}
+
+	sh.mu.Lock()
+	defer sh.unlock()
+	if err := sh.loadSortedLocked(sorted); err != nil {
+		return nil, err
+	}
+	return sh, nil
+}
+
+// checkSorted reports the first entry that breaks strictly increasing key
+// order.
+func (sh *SkipHash[K, V]) checkSorted(entries []Entry[K, V]) error {
+	for i := 1; i < len(entries); i++ {
+		c := sh.compare(entries[i].Key, entries[i-1].Key)
+		if c == 0 {
+			return fmt.Errorf("%w: entry %d (key %v)", ErrDuplicateKey, i, entries[i].Key)
+		}
+		if c < 0 {
+			return fmt.Errorf("%w: entry %d (key %v)", ErrUnsorted, i, entries[i].Key)
+		}
+	}
+	return nil
+}
+
+// loadSortedLocked inserts sorted entries whose keys are not live. When the
+// list holds no nodes at all and no option needs to vet inserts, it builds
+// the levels directly; otherwise it inserts one entry at a time and stops at
+// the first rejection.
+func (sh *SkipHash[K, V]) loadSortedLocked(sorted []Entry[K, V]) error {
+	bulk := sh.head.next[0] == sh.tail &&
+		sh.quota == nil && sh.maxWeight <= 0 && sh.maxEntries <= 0
+	if !bulk {
+		for _, e := range sorted {
+			if err := sh.insertLocked(e.Key, e.Value); err != nil {
+				return fmt.Errorf("key %v: %w", e.Key, err)
+			}
+		}
+		return nil
+	}
+
+	sh.index.reserve(len(sorted))
+	preds := make([]*slNode[K, V], sh.maxLevel)
+	predRanks := make([]int, sh.maxLevel)
+	for level := range preds {
+		preds[level] = sh.head
+	}
+	for i, e := range sorted {
+		rank := i + 1
+		node := sh.newNodeLocked(e.Key, e.Value, uint8(min(bits.TrailingZeros(uint(rank))+1, sh.maxLevel)))
+		for level := range int(node.height) {
+			pred := preds[level]
+			pred.next[level] = node
+			pred.span[level] = rank - predRanks[level]
+			node.prev[level] = pred
+			preds[level], predRanks[level] = node, rank
+		}
+		sh.index.set(e.Key, node)
+		sh.len++
+		sh.weight += sh.weigh(e.Key, e.Value)
+		sh.touch(node)
+		sh.noteWriteLocked(node)
+		sh.changedLocked(change[K, V]{kind: ChangeInsert, key: e.Key, value: e.Value})
+	}
+	for level, pred := range preds {
+		pred.next[level] = sh.tail
+		pred.span[level] = len(sorted) - predRanks[level]
+		sh.tail.prev[level] = pred
+	}
+	sh.rebuildAugmentsLocked()
+	return nil
+}
+
+// replaceAllLocked removes every live entry and loads sorted, which must be
+// in key order without duplicates. A rejected insert stops the load there.
+func (sh *SkipHash[K, V]) replaceAllLocked(sorted []Entry[K, V]) error {
+	sh.expireDueLocked(0)
+	if sh.tier != nil {
+		sh.dropUnmigratedLocked()
+		sh.loadSegmentsLocked(func(*segment[K]) bool { return true })
+	}
+	var live []*slNode[K, V]
+	for node := sh.head.next[0]; node != sh.tail; node = node.next[0] {
+		if node.rTime == 0 {
+			live = append(live, node)
+		}
+	}
+	sh.beginBatchLocked()
+	defer sh.endBatchLocked()
+	for _, node := range live {
+		sh.removeLocked(node)
+	}
+	return sh.loadSortedLocked(sorted)
+}
@@ -0,0 +1,168 @@
+package skiphash
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSkipHashInsertSortedStrict(t *testing.T) {
+	sh := New[int, int]()
+	require.NoError(t, sh.InsertSortedStrict([]Entry[int, int]{{1, 1}, {3, 3}, {5, 5}}))
+	assert.Equal(t, 3, sh.Len())
+
+	err := sh.InsertSortedStrict([]Entry[int, int]{{6, 6}, {8, 8}, {7, 7}})
+	assert.ErrorIs(t, err, ErrUnsorted)
+	assert.ErrorContains(t, err, "entry 2")
+	assert.False(t, sh.Contains(6), "rejected input must not be partially applied")
+
+	err = sh.InsertSortedStrict([]Entry[int, int]{{6, 6}, {6, 7}})
+	assert.ErrorIs(t, err, ErrDuplicateKey)
+
+	err = sh.InsertSortedStrict([]Entry[int, int]{{2, 2}, {3, 3}})
+	assert.ErrorIs(t, err, ErrKeyExists)
+	assert.False(t, sh.Contains(2))
+
+	assert.NoError(t, sh.InsertSortedStrict(nil))
+	checkSpans(t, sh)
+}
+
+func TestSkipHashInsertIfRangeEmpty(t *testing.T) {
+	sh := New[int, string]()
+	// Booking slots keyed by start minute, each 30 minutes long.
+	book := func(start int) bool {
+		return sh.InsertIfRangeEmpty(start-29, start+29, start, "booked")
+	}
+
+	assert.True(t, book(60))
+	assert.False(t, book(75), "overlaps the 60 slot")
+	assert.False(t, book(60))
+	assert.True(t, book(90))
+	assert.True(t, book(30))
+	assert.Equal(t, 3, sh.Len())
+
+	sh.Remove(90)
+	assert.True(t, book(100))
+	assert.True(t, sh.InsertIfRangeEmpty(10, 5, 7, "empty interval"))
+}
+
+func TestSkipHashInsertAllDuplicates(t *testing.T) {
+	feed := []Entry[int, int]{{3, 30}, {1, 10}, {3, 31}, {2, 20}, {1, 11}}
+
+	sh := New[int, int]()
+	n, err := sh.InsertAll(feed)
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+	assert.Equal(t, []Entry[int, int]{{1, 11}, {2, 20}, {3, 31}}, sh.RangeAll())
+
+	sh = New[int, int]()
+	sh.Store(2, 200)
+	n, err = sh.InsertAll(feed, WithDuplicates(KeepFirst))
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, []Entry[int, int]{{1, 10}, {2, 200}, {3, 30}}, sh.RangeAll())
+
+	sh = New[int, int]()
+	_, err = sh.InsertAll(feed, WithDuplicates(RejectDuplicates))
+	assert.ErrorIs(t, err, ErrDuplicateKey)
+	assert.Zero(t, sh.Len())
+
+	sh.Store(2, 200)
+	_, err = sh.InsertAll([]Entry[int, int]{{1, 1}, {2, 2}}, WithDuplicates(RejectDuplicates))
+	assert.ErrorIs(t, err, ErrKeyExists)
+	assert.False(t, sh.Contains(1))
+
+	n, err = sh.InsertAll(feed, WithDuplicateResolver(func(_ int, earlier, later int) int {
+		return earlier + later
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, []Entry[int, int]{{1, 21}, {2, 220}, {3, 61}}, sh.RangeAll())
+	checkSpans(t, sh)
+}
+
+func TestNewFromSorted(t *testing.T) {
+	entries := make([]Entry[int, int], 1000)
+	for i := range entries {
+		entries[i] = Entry[int, int]{Key: 2 * i, Value: i}
+	}
+	sh, err := NewFromSorted(entries)
+	require.NoError(t, err)
+	require.Equal(t, entries, sh.RangeAll())
+	require.Equal(t, 1000, sh.Len())
+	checkSpans(t, sh)
+
+	for level := 0; level < sh.maxLevel; level++ {
+		for cur := sh.head; cur != sh.tail; cur = cur.next[level] {
+			require.Same(t, cur, cur.next[level].prev[level])
+		}
+	}
+	require.Equal(t, 10, sh.Rank(20))
+	e, ok := sh.Select(500)
+	require.True(t, ok)
+	require.Equal(t, 1000, e.Key)
+
+	// The bulk-built list behaves like any other.
+	sh.Store(1, -1)
+	sh.Remove(500)
+	checkSpans(t, sh)
+	v, ok := sh.Get(1)
+	require.True(t, ok)
+	require.Equal(t, -1, v)
+	require.Equal(t, 1000, sh.Len())
+}
+
+func TestNewFromSortedValidates(t *testing.T) {
+	_, err := NewFromSorted([]Entry[int, int]{{Key: 2}, {Key: 1}})
+	require.ErrorIs(t, err, ErrUnsorted)
+	_, err = NewFromSorted([]Entry[int, int]{{Key: 1}, {Key: 1}})
+	require.ErrorIs(t, err, ErrDuplicateKey)
+
+	sh, err := NewFromSorted[int, int](nil)
+	require.NoError(t, err)
+	require.Zero(t, sh.Len())
+}
+
+func TestNewFromSortedHonorsCaps(t *testing.T) {
+	entries := []Entry[int, int]{{Key: 1, Value: 1}, {Key: 2, Value: 2}, {Key: 3, Value: 3}}
+	sh, err := NewFromSorted(entries, WithMaxEntries(2, EvictOldest))
+	require.NoError(t, err)
+	require.Equal(t, entries[1:], sh.RangeAll())
+}
+
+func TestNewFromMap(t *testing.T) {
+	m := map[string]int{"b": 2, "a": 1, "d": 4, "c": 3}
+	sh, err := NewFromMap(m)
+	require.NoError(t, err)
+	require.Equal(t, []Entry[string, int]{
+		{Key: "a", Value: 1}, {Key: "b", Value: 2}, {Key: "c", Value: 3}, {Key: "d", Value: 4},
+	}, sh.RangeAll())
+	checkSpans(t, sh)
+
+	empty, err := NewFromMap[int, int](nil)
+	require.NoError(t, err)
+	require.Zero(t, empty.Len())
+
+	_, err = NewFromMap(m, WithMaxWeight(1, func(string, int) int64 { return 1 }))
+	require.ErrorIs(t, err, ErrOverWeight)
+}
+
+func TestSkipHashTrimBelow(t *testing.T) {
+	var removed []int
+	sh := New[int, int](WithHooks(Hooks[int, int]{OnRemove: func(k, _ int) { removed = append(removed, k) }}))
+	for i := range 100 {
+		sh.Store(i, i)
+	}
+	sh.Remove(3)
+	removed = nil
+
+	assert.Zero(t, sh.TrimBelow(-1))
+	assert.Equal(t, 9, sh.TrimBelow(10))
+	assert.Equal(t, []int{0, 1, 2, 4, 5, 6, 7, 8, 9}, removed)
+	assert.Equal(t, 90, sh.Len())
+	first, _ := sh.Ceil(-1)
+	assert.Equal(t, 10, first.Key)
+	assert.Equal(t, 90, sh.TrimBelow(1000))
+	assert.Zero(t, sh.Len())
+}
@@ -0,0 +1,18 @@
+package skiphash
+
+import (
+	"bytes"
+	"hash/maphash"
+)
+
+// NewBytes creates a SkipHash keyed by byte slices in bytes.Compare order.
+// Keys are stored without copying, so callers must not modify a slice after
+// passing it as a key.
+func NewBytes[V any](opts ...Option) *SkipHash[[]byte, V] {
+	seed := maphash.MakeSeed()
+	return newSkipHash(
+		bytes.Compare,
+		newHashIndex[[]byte, V](func(k []byte) uint64 { return maphash.Bytes(seed, k) }, bytes.Compare),
+		opts,
+	)
+}
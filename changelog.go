@@ -0,0 +1,101 @@
+package skiphash
+
+import "fmt"
+
+// ChangeRecord is one committed mutation in the changelog. Seq numbers the
+// records of a SkipHash consecutively from 1. For removals Value is the
+// removed value.
+type ChangeRecord[K any, V any] struct {
+	Seq   uint64
+	Kind  ChangeKind
+	Key   K
+	Value V
+}
+
+// WithChangelog keeps the most recent records of every committed insert,
+// update and removal, at least retain of them, for Changes to serve to
+// replicas and incremental caches.
+func WithChangelog(retain int) Option {
+	return func(cfg *config) {
+		if retain > 0 {
+			cfg.changelog = retain
+		}
+	}
+}
+
+type changelog[K any, V any] struct {
+	retain  int
+	seq     uint64
+	records []ChangeRecord[K, V]
+}
+
+func (l *changelog[K, V]) appendLocked(c change[K, V]) {
+	l.seq++
+	l.records = append(l.records, ChangeRecord[K, V]{Seq: l.seq, Kind: c.kind, Key: c.key, Value: c.value})
+	// Trim in batches so appends stay amortized O(1).
+	if len(l.records) >= 2*l.retain {
+		n := copy(l.records, l.records[len(l.records)-l.retain:])
+		clear(l.records[n:])
+		l.records = l.records[:n]
+	}
+}
+
+// Changes returns the records after sequence number since, oldest first,
+// and the sequence number to pass on the next call. Records that have
+// already been discarded are skipped: when the first record's Seq is not
+// since+1, the caller has missed changes and must resync from a full copy.
+// Changes panics unless the SkipHash was created with WithChangelog.
+func (sh *SkipHash[K, V]) Changes(since uint64) ([]ChangeRecord[K, V], uint64) {
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+	l := sh.changelog
+	if l == nil {
+		panic("skiphash: Changes requires WithChangelog")
+	}
+	if since >= l.seq {
+		return nil, l.seq
+	}
+	start := 0
+	if len(l.records) > 0 && since >= l.records[0].Seq {
+		start = int(since - l.records[0].Seq + 1)
+	}
+	return append([]ChangeRecord[K, V](nil), l.records[start:]...), l.seq
+}
+
+// ApplyChanges replays records from another SkipHash's Changes in one
+// transaction, skipping those at or below AppliedSeq, so a batch can be
+// applied again or overlap the previous one without effect. Records must be
+// in increasing Seq order, or ErrUnsorted is returned. A replica seeded with
+// a copy of the source converges by applying every record from some point
+// before the copy was taken. If a write is rejected, nothing is applied.
+func (sh *SkipHash[K, V]) ApplyChanges(records []ChangeRecord[K, V]) error {
+	for i := 1; i < len(records); i++ {
+		if records[i].Seq <= records[i-1].Seq {
+			return fmt.Errorf("%w: record %d (seq %d)", ErrUnsorted, i, records[i].Seq)
+		}
+	}
+	return sh.Txn(func(tx *Txn[K, V]) error {
+		for _, r := range records {
+			if r.Seq <= sh.appliedSeq {
+				continue
+			}
+			if r.Kind == ChangeRemove {
+				tx.Remove(r.Key)
+			} else if _, err := tx.Store(r.Key, r.Value); err != nil {
+				return fmt.Errorf("seq %d: %w", r.Seq, err)
+			}
+		}
+		if n := len(records); n > 0 {
+			sh.appliedSeq = max(sh.appliedSeq, records[n-1].Seq)
+		}
+		return nil
+	})
+}
+
+// AppliedSeq returns the Seq of the last record applied by ApplyChanges,
+// which is where the next call to the source's Changes should start.
+func (sh *SkipHash[K, V]) AppliedSeq() uint64 {
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+	return sh.appliedSeq
+}
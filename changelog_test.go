@@ -0,0 +1,85 @@
+package skiphash
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChanges(t *testing.T) {
+	sh := New[string, int](WithChangelog(4))
+	records, next := sh.Changes(0)
+	assert.Empty(t, records)
+	assert.Zero(t, next)
+
+	sh.Store("a", 1)
+	sh.Store("a", 2)
+	sh.Remove("a")
+	records, next = sh.Changes(0)
+	assert.Equal(t, []ChangeRecord[string, int]{
+		{Seq: 1, Kind: ChangeInsert, Key: "a", Value: 1},
+		{Seq: 2, Kind: ChangeUpdate, Key: "a", Value: 2},
+		{Seq: 3, Kind: ChangeRemove, Key: "a", Value: 2},
+	}, records)
+	assert.Equal(t, uint64(3), next)
+
+	records, next = sh.Changes(next)
+	assert.Empty(t, records)
+	assert.Equal(t, uint64(3), next)
+
+	// Rolled-back transactions leave no records.
+	require.Error(t, sh.Txn(func(tx *Txn[string, int]) error {
+		tx.Store("x", 1)
+		return errors.New("abort")
+	}))
+	for i := range 10 {
+		sh.Store("b", i)
+	}
+	records, next = sh.Changes(2)
+	assert.Equal(t, uint64(13), next)
+	assert.Greater(t, records[0].Seq, uint64(3), "a gap reveals discarded records")
+	assert.GreaterOrEqual(t, len(records), 4)
+	assert.Equal(t, ChangeRecord[string, int]{Seq: 13, Kind: ChangeUpdate, Key: "b", Value: 9}, records[len(records)-1])
+
+	records, _ = sh.Changes(11)
+	assert.Len(t, records, 2)
+	assert.Equal(t, uint64(12), records[0].Seq)
+
+	assert.Panics(t, func() { New[string, int]().Changes(0) })
+}
+
+func TestApplyChanges(t *testing.T) {
+	src := New[string, int](WithChangelog(100))
+	replica := New[string, int]()
+
+	src.Store("a", 1)
+	src.Store("b", 2)
+	records, _ := src.Changes(replica.AppliedSeq())
+	require.NoError(t, replica.ApplyChanges(records))
+
+	src.Store("a", 10)
+	src.Remove("b")
+	src.Store("c", 3)
+	records, next := src.Changes(replica.AppliedSeq())
+	require.NoError(t, replica.ApplyChanges(records))
+	assert.Equal(t, src.RangeAll(), replica.RangeAll())
+	assert.Equal(t, next, replica.AppliedSeq())
+
+	// Replaying everything again changes nothing.
+	src.Store("a", 100)
+	all, _ := src.Changes(0)
+	require.NoError(t, replica.ApplyChanges(all))
+	require.NoError(t, replica.ApplyChanges(all))
+	assert.Equal(t, src.RangeAll(), replica.RangeAll())
+
+	assert.ErrorIs(t, replica.ApplyChanges([]ChangeRecord[string, int]{all[1], all[0]}), ErrUnsorted)
+
+	// A replica seeded from a copy converges by replaying from before it.
+	seeded := src.Union(New[string, int]())
+	src.Remove("a")
+	all, _ = src.Changes(0)
+	require.NoError(t, seeded.ApplyChanges(all))
+	assert.Equal(t, src.RangeAll(), seeded.RangeAll())
+}
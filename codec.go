@@ -0,0 +1,147 @@
+package skiphash
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"math"
+)
+
+// Codec converts keys or values to bytes and back for SaveTo, LoadFrom,
+// MarshalBinary and the WAL. Encode must be deterministic. A Codec that also
+// has a Name() string method is identified by that name in persisted data,
+// so a reader with a different codec refuses it; otherwise the name is
+// "custom".
+type Codec[T any] interface {
+	Encode(T) ([]byte, error)
+	Decode([]byte) (T, error)
+}
+
+// WithKeyCodec sets how keys are persisted. Without it, strings, []byte,
+// int, int64, uint64, float64 and bool use a fixed binary layout and other
+// types use encoding/gob.
+func WithKeyCodec[K any](c Codec[K]) Option {
+	return func(cfg *config) {
+		if c != nil {
+			cfg.keyCodec = codecOf(c)
+		}
+	}
+}
+
+// WithValueCodec sets how values are persisted; see WithKeyCodec.
+func WithValueCodec[V any](c Codec[V]) Option {
+	return func(cfg *config) {
+		if c != nil {
+			cfg.valueCodec = codecOf(c)
+		}
+	}
+}
+
+// codec is the resolved form of a Codec. name is recorded alongside the
+// data so a reader can refuse bytes it cannot decode.
+type codec[T any] struct {
+	name   string
+	encode func(T) ([]byte, error)
+	decode func([]byte) (T, error)
+}
+
+func codecOf[T any](c Codec[T]) codec[T] {
+	name := "custom"
+	if named, ok := c.(interface{ Name() string }); ok {
+		name = named.Name()
+	}
+	return codec[T]{name: name, encode: c.Encode, decode: c.Decode}
+}
+
+var errShortVarint = errors.New("skiphash: truncated varint")
+
+// defaultCodec uses a fixed binary layout for common scalar types and
+// encoding/gob for everything else.
+func defaultCodec[T any]() codec[T] {
+	var zero T
+	switch any(zero).(type) {
+	case string:
+		return codec[T]{
+			name:   "string",
+			encode: func(v T) ([]byte, error) { return []byte(any(v).(string)), nil },
+			decode: func(b []byte) (T, error) { return any(string(b)).(T), nil },
+		}
+	case []byte:
+		return codec[T]{
+			name:   "bytes",
+			encode: func(v T) ([]byte, error) { return any(v).([]byte), nil },
+			decode: func(b []byte) (T, error) { return any(bytes.Clone(b)).(T), nil },
+		}
+	case int:
+		return varintCodec[T]("int", func(v T) int64 { return int64(any(v).(int)) }, func(n int64) T { return any(int(n)).(T) })
+	case int64:
+		return varintCodec[T]("int64", func(v T) int64 { return any(v).(int64) }, func(n int64) T { return any(n).(T) })
+	case uint64:
+		return codec[T]{
+			name:   "uint64",
+			encode: func(v T) ([]byte, error) { return binary.AppendUvarint(nil, any(v).(uint64)), nil },
+			decode: func(b []byte) (T, error) {
+				n, size := binary.Uvarint(b)
+				if size <= 0 {
+					return zero, errShortVarint
+				}
+				return any(n).(T), nil
+			},
+		}
+	case float64:
+		return codec[T]{
+			name: "float64",
+			encode: func(v T) ([]byte, error) {
+				return binary.BigEndian.AppendUint64(nil, math.Float64bits(any(v).(float64))), nil
+			},
+			decode: func(b []byte) (T, error) {
+				if len(b) != 8 {
+					return zero, errors.New("skiphash: float64 needs 8 bytes")
+				}
+				return any(math.Float64frombits(binary.BigEndian.Uint64(b))).(T), nil
+			},
+		}
+	case bool:
+		return codec[T]{
+			name:   "bool",
+			encode: func(v T) ([]byte, error) { return []byte{boolByte(any(v).(bool))}, nil },
+			decode: func(b []byte) (T, error) { return any(len(b) == 1 && b[0] == 1).(T), nil },
+		}
+	}
+	return codec[T]{
+		name: "gob",
+		encode: func(v T) ([]byte, error) {
+			var buf bytes.Buffer
+			err := gob.NewEncoder(&buf).Encode(&v)
+			return buf.Bytes(), err
+		},
+		decode: func(b []byte) (T, error) {
+			var v T
+			err := gob.NewDecoder(bytes.NewReader(b)).Decode(&v)
+			return v, err
+		},
+	}
+}
+
+func varintCodec[T any](name string, to func(T) int64, from func(int64) T) codec[T] {
+	return codec[T]{
+		name:   name,
+		encode: func(v T) ([]byte, error) { return binary.AppendVarint(nil, to(v)), nil },
+		decode: func(b []byte) (T, error) {
+			n, size := binary.Varint(b)
+			if size <= 0 {
+				var zero T
+				return zero, errShortVarint
+			}
+			return from(n), nil
+		},
+	}
+}
+
+func boolByte(b bool) byte {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,74 @@
+package skiphash
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+)
+
+// Compressor compresses the records of snapshots written by SaveTo and
+// SaveToFile, including WAL checkpoints. Its name is recorded in the
+// snapshot header, so LoadFrom picks the matching decompressor: gzip is
+// always understood, other formats such as zstd only by a SkipHash created
+// with a Compressor of the same name.
+type Compressor interface {
+	Name() string
+	NewWriter(w io.Writer) (io.WriteCloser, error)
+	NewReader(r io.Reader) (io.ReadCloser, error)
+}
+
+// WithCompression compresses snapshots with c.
+func WithCompression(c Compressor) Option {
+	return func(cfg *config) {
+		if c != nil {
+			cfg.compressor = c
+		}
+	}
+}
+
+// Gzip returns a Compressor using compress/gzip at level, one of the
+// gzip.*Compression constants.
+func Gzip(level int) Compressor {
+	return gzipCompressor{level: level}
+}
+
+type gzipCompressor struct {
+	level int
+}
+
+func (gzipCompressor) Name() string { return "gzip" }
+
+func (c gzipCompressor) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	return gzip.NewWriterLevel(w, c.level)
+}
+
+func (gzipCompressor) NewReader(r io.Reader) (io.ReadCloser, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	// The compressed records end the snapshot; anything after them is not
+	// another gzip member.
+	zr.Multistream(false)
+	return zr, nil
+}
+
+// decompressor returns the Compressor for a name read from a snapshot
+// header, or nil for an uncompressed snapshot.
+func (sh *SkipHash[K, V]) decompressor(name string) (Compressor, error) {
+	switch {
+	case name == "":
+		return nil, nil
+	case sh.compressor != nil && sh.compressor.Name() == name:
+		return sh.compressor, nil
+	case name == "gzip":
+		return Gzip(gzip.DefaultCompression), nil
+	}
+	return nil, fmt.Errorf("%w: unknown compression %q", ErrBadSnapshot, name)
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
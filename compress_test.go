@@ -0,0 +1,98 @@
+package skiphash
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/binary"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompressedSnapshot(t *testing.T) {
+	plain := New[int, string]()
+	packed := New[int, string](WithCompression(Gzip(gzip.BestCompression)))
+	for i := range 1000 {
+		value := strings.Repeat("value", 20)
+		plain.Store(i, value)
+		packed.Store(i, value)
+	}
+	var raw, compressed bytes.Buffer
+	require.NoError(t, plain.SaveTo(&raw))
+	require.NoError(t, packed.SaveTo(&compressed))
+	require.Less(t, compressed.Len()*8, raw.Len())
+
+	// gzip is understood without the option.
+	out := New[int, string]()
+	require.NoError(t, out.LoadFrom(bytes.NewReader(compressed.Bytes())))
+	require.Equal(t, plain.RangeAll(), out.RangeAll())
+
+	// So is a compressed snapshot file.
+	path := filepath.Join(t.TempDir(), "snap")
+	require.NoError(t, packed.SaveToFile(path))
+	out = New[int, string]()
+	require.NoError(t, out.LoadFromFile(path))
+	require.Equal(t, plain.RangeAll(), out.RangeAll())
+
+	// Corruption inside the compressed records is caught by the gzip
+	// checksum.
+	data := bytes.Clone(compressed.Bytes())
+	data[len(data)-6] ^= 0xff
+	require.ErrorIs(t, out.LoadFrom(bytes.NewReader(data)), ErrBadSnapshot)
+	require.Equal(t, plain.RangeAll(), out.RangeAll())
+}
+
+type zlibCompressor struct{}
+
+func (zlibCompressor) Name() string { return "zlib" }
+
+func (zlibCompressor) NewWriter(w io.Writer) (io.WriteCloser, error) { return zlib.NewWriter(w), nil }
+
+func (zlibCompressor) NewReader(r io.Reader) (io.ReadCloser, error) { return zlib.NewReader(r) }
+
+func TestCustomCompressor(t *testing.T) {
+	sh := New[string, int](WithCompression(zlibCompressor{}))
+	sh.Store("a", 1)
+	sh.Store("b", 2)
+	var buf bytes.Buffer
+	require.NoError(t, sh.SaveTo(&buf))
+
+	require.ErrorIs(t, New[string, int]().LoadFrom(bytes.NewReader(buf.Bytes())), ErrBadSnapshot)
+	out := New[string, int](WithCompression(zlibCompressor{}))
+	require.NoError(t, out.LoadFrom(bytes.NewReader(buf.Bytes())))
+	require.Equal(t, sh.RangeAll(), out.RangeAll())
+
+	// WAL checkpoints are compressed too.
+	dir := t.TempDir()
+	logged := New[string, int](WithWAL(dir), WithCompression(zlibCompressor{}))
+	logged.Store("a", 1)
+	require.NoError(t, logged.CheckpointWAL())
+	logged.Store("b", 2)
+	require.NoError(t, logged.CheckpointWAL())
+	require.NoError(t, logged.CloseWAL())
+	_, err := Recover[string, int](dir)
+	require.ErrorIs(t, err, ErrBadSnapshot)
+	got, err := Recover[string, int](dir, WithCompression(zlibCompressor{}))
+	require.NoError(t, err)
+	require.Equal(t, logged.RangeAll(), got.RangeAll())
+	require.NoError(t, got.CloseWAL())
+}
+
+func TestLoadFromFormat1(t *testing.T) {
+	var buf []byte
+	buf = append(buf, snapshotMagic...)
+	buf = binary.AppendUvarint(buf, 1)
+	buf = binary.AppendUvarint(buf, 0)
+	buf = appendChunk(buf, []byte("string"))
+	buf = appendChunk(buf, []byte("string"))
+	buf = binary.AppendUvarint(buf, 1)
+	buf = appendChunk(appendChunk(buf, []byte("k")), []byte("v"))
+
+	sh := New[string, string]()
+	require.NoError(t, sh.LoadFrom(bytes.NewReader(buf)))
+	require.Equal(t, []Entry[string, string]{{Key: "k", Value: "v"}}, sh.RangeAll())
+}
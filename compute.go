@@ -0,0 +1,70 @@
+package skiphash
+
+import "time"
+
+// ComputeOp tells Compute what to do with the value its function returned.
+type ComputeOp uint8
+
+const (
+	// ComputeNoop leaves the entry as it is.
+	ComputeNoop ComputeOp = iota
+	// ComputeStore inserts or replaces the entry with the returned value.
+	ComputeStore
+	// ComputeDelete removes the entry if it exists.
+	ComputeDelete
+)
+
+// Compute reads the value of key, passes it to fn and applies the op fn
+// returns, all under the write lock, so no other write can come between the
+// read and the write. fn must not call into the SkipHash. Compute returns
+// the value now stored for key and whether key is present; a store rejected
+// by a quota or weight budget leaves the entry unchanged. Storing over an
+// entry keeps its TTL.
+func (sh *SkipHash[K, V]) Compute(key K, fn func(old V, exists bool) (V, ComputeOp)) (V, bool) {
+	sh.unsupportedInFineGrained()
+	key = sh.normalizeKey(key)
+	sh.mu.Lock()
+	defer sh.unlock()
+	sh.faultInLocked(key, key)
+
+	node, exists := sh.index.get(key)
+	var old V
+	if exists {
+		old = *node.value.Load()
+	}
+	value, op := fn(old, exists)
+	switch op {
+	case ComputeStore:
+		var err error
+		if exists {
+			sh.touch(node)
+			at := node.expiry.at
+			if err = sh.updateLocked(node, value); err == nil && at != 0 {
+				sh.scheduleLocked(key, time.Unix(0, at))
+			}
+		} else {
+			err = sh.insertLocked(key, value)
+		}
+		if err != nil {
+			return old, exists
+		}
+		return value, true
+	case ComputeDelete:
+		if exists {
+			sh.removeLocked(node)
+		}
+		var zero V
+		return zero, false
+	}
+	return old, exists
+}
+
+// Add adds delta to the value of key under one write lock, storing delta if
+// key is absent, and returns the new value. A write rejected by a quota or
+// weight budget leaves the entry unchanged and returns its value.
+func Add[K any, V Number](sh *SkipHash[K, V], key K, delta V) V {
+	value, _ := sh.Compute(key, func(old V, _ bool) (V, ComputeOp) {
+		return old + delta, ComputeStore
+	})
+	return value
+}
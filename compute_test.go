@@ -0,0 +1,92 @@
+package skiphash
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompute(t *testing.T) {
+	sh := New[string, int]()
+
+	v, ok := sh.Compute("a", func(old int, exists bool) (int, ComputeOp) {
+		assert.False(t, exists)
+		return 1, ComputeStore
+	})
+	assert.True(t, ok)
+	assert.Equal(t, 1, v)
+
+	v, ok = sh.Compute("a", func(old int, exists bool) (int, ComputeOp) {
+		assert.True(t, exists)
+		return old + 10, ComputeStore
+	})
+	assert.True(t, ok)
+	assert.Equal(t, 11, v)
+
+	v, ok = sh.Compute("a", func(old int, _ bool) (int, ComputeOp) { return 99, ComputeNoop })
+	assert.True(t, ok)
+	assert.Equal(t, 11, v)
+
+	_, ok = sh.Compute("a", func(int, bool) (int, ComputeOp) { return 0, ComputeDelete })
+	assert.False(t, ok)
+	assert.False(t, sh.Contains("a"))
+
+	_, ok = sh.Compute("b", func(int, bool) (int, ComputeOp) { return 0, ComputeNoop })
+	assert.False(t, ok)
+	assert.Zero(t, sh.Len())
+}
+
+func TestComputeConcurrent(t *testing.T) {
+	sh := New[int, int]()
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Go(func() {
+			for i := range 500 {
+				sh.Compute(i%10, func(old int, _ bool) (int, ComputeOp) { return old + 1, ComputeStore })
+			}
+		})
+	}
+	wg.Wait()
+	for i := range 10 {
+		v, _ := sh.Get(i)
+		assert.Equal(t, 400, v)
+	}
+}
+
+func TestAdd(t *testing.T) {
+	sh := New[string, float64]()
+	assert.Equal(t, 1.5, Add(sh, "x", 1.5))
+	assert.Equal(t, 4.0, Add(sh, "x", 2.5))
+	assert.Equal(t, -1.0, Add(sh, "y", -1))
+
+	counters := New[int, int64]()
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Go(func() {
+			for range 1000 {
+				Add(counters, 7, 1)
+			}
+		})
+	}
+	wg.Wait()
+	n, _ := counters.Get(7)
+	assert.Equal(t, int64(8000), n)
+}
+
+func TestComputeKeepsTTL(t *testing.T) {
+	sh := New[string, int]()
+	sh.StoreTTL("a", 1, time.Hour)
+	before, _ := sh.TTL("a")
+
+	assert.Equal(t, 3, Add(sh, "a", 2))
+	sh.Compute("a", func(old int, _ bool) (int, ComputeOp) { return old * 2, ComputeStore })
+	ttl, ok := sh.TTL("a")
+	assert.True(t, ok)
+	assert.InDelta(t, before, ttl, float64(time.Second))
+
+	Add(sh, "b", 1)
+	_, ok = sh.TTL("b")
+	assert.False(t, ok)
+}
@@ -0,0 +1,189 @@
+package skiphash
+
+import (
+	"slices"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultMaxStaleness bounds how old the copy served to Eventual reads may be.
+const DefaultMaxStaleness = 50 * time.Millisecond
+
+// Consistency selects how a read is served.
+type Consistency int
+
+const (
+	// Strong reads hold the read lock for the whole operation.
+	Strong Consistency = iota
+	// Snapshot reads observe a single range-coordinator version and release
+	// the lock between nodes, so writers are not blocked by long scans.
+	Snapshot
+	// Eventual reads are served without locking from an immutable copy that
+	// may lag writes by up to the configured staleness.
+	Eventual
+)
+
+// WithMaxStaleness sets how old the copy used by Eventual reads may get
+// before a read rebuilds it.
+func WithMaxStaleness(d time.Duration) Option {
+	return func(cfg *config) {
+		if d > 0 {
+			cfg.maxStaleness = d
+		}
+	}
+}
+
+type eventualView[K any, V any] struct {
+	entries []Entry[K, V]
+	builtAt time.Time
+}
+
+type eventualState[K any, V any] struct {
+	view atomic.Pointer[eventualView[K, V]]
+	// building is held by the one reader rebuilding the view.
+	building sync.Mutex
+
+	// Guarded by the SkipHash lock: once a view is built, writes keeps every
+	// write made since, so a rebuild merges them into a copy of the view
+	// instead of walking the list. Past limit writes, full is set and they
+	// are dropped for a full rebuild, which is then cheaper.
+	tracking bool
+	writes   []eventualWrite[K, V]
+	limit    int
+	full     bool
+}
+
+type eventualWrite[K any, V any] struct {
+	key     K
+	value   V
+	removed bool
+}
+
+func (st *eventualState[K, V]) noteLocked(c change[K, V]) {
+	if !st.tracking || st.full {
+		return
+	}
+	if len(st.writes) >= st.limit {
+		st.writes, st.full = nil, true
+		return
+	}
+	st.writes = append(st.writes, eventualWrite[K, V]{key: c.key, value: c.value, removed: c.kind == ChangeRemove})
+}
+
+// GetWith looks key up with the requested consistency. Snapshot point reads
+// are served like Strong ones, since a single lookup is already atomic.
+func (sh *SkipHash[K, V]) GetWith(key K, c Consistency) (V, bool) {
+	sh.unsupportedInFineGrained()
+	if c != Eventual {
+		return sh.Get(key)
+	}
+	key = sh.normalizeKey(key)
+	entries := sh.eventualEntries()
+	i := sort.Search(len(entries), func(i int) bool { return sh.compare(entries[i].Key, key) >= 0 })
+	if i < len(entries) && sh.compare(entries[i].Key, key) == 0 {
+		return entries[i].Value, true
+	}
+	var zero V
+	return zero, false
+}
+
+// RangeWith returns the live entries in [low, high] with the requested
+// consistency.
+func (sh *SkipHash[K, V]) RangeWith(low, high K, c Consistency) []Entry[K, V] {
+	sh.unsupportedInFineGrained()
+	low, high = sh.normalizeKey(low), sh.normalizeKey(high)
+	if sh.compare(low, high) > 0 {
+		return nil
+	}
+
+	switch c {
+	case Snapshot:
+		sh.faultIn(low, high)
+		return sh.rangeSlow(nil, low, high)
+	case Eventual:
+		entries := sh.eventualEntries()
+		start := sort.Search(len(entries), func(i int) bool { return sh.compare(entries[i].Key, low) >= 0 })
+		end := sort.Search(len(entries), func(i int) bool { return sh.compare(entries[i].Key, high) > 0 })
+		if start >= end {
+			return nil
+		}
+		return append([]Entry[K, V](nil), entries[start:end]...)
+	default:
+		sh.faultIn(low, high)
+		sh.mu.RLock()
+		defer sh.mu.RUnlock()
+		return sh.collectRangeLocked(nil, low, high)
+	}
+}
+
+// eventualEntries returns the sorted copy used by Eventual reads, rebuilding
+// it when it is older than the staleness bound. Only one reader rebuilds at
+// a time; the others keep serving the previous copy meanwhile, or wait for
+// the first one. A rebuild copies the view with the writes made since merged
+// in, which leaves the previous copy intact for the readers still using it.
+func (sh *SkipHash[K, V]) eventualEntries() []Entry[K, V] {
+	st := &sh.eventual
+	view := st.view.Load()
+	if view != nil {
+		if time.Since(view.builtAt) < sh.maxStaleness || !st.building.TryLock() {
+			return view.entries
+		}
+	} else {
+		st.building.Lock()
+	}
+	defer st.building.Unlock()
+	if latest := st.view.Load(); latest != view {
+		return latest.entries
+	}
+
+	full := view == nil
+	var writes []eventualWrite[K, V]
+	if !full {
+		sh.mu.Lock()
+		writes, full = st.writes, st.full
+		st.writes, st.full = nil, false
+		sh.mu.Unlock()
+	}
+	var entries []Entry[K, V]
+	if full {
+		sh.faultInAll()
+		sh.mu.Lock()
+		entries = sh.allEntriesLocked()
+		st.tracking, st.writes, st.full = true, nil, false
+		st.limit = len(entries)/4 + 64
+		sh.mu.Unlock()
+	} else {
+		entries = sh.mergeWrites(view.entries, writes)
+	}
+	st.view.Store(&eventualView[K, V]{entries: entries, builtAt: time.Now()})
+	return entries
+}
+
+// mergeWrites returns a copy of the sorted entries with writes applied in
+// order, or entries itself if there are none.
+func (sh *SkipHash[K, V]) mergeWrites(entries []Entry[K, V], writes []eventualWrite[K, V]) []Entry[K, V] {
+	if len(writes) == 0 {
+		return entries
+	}
+	slices.SortStableFunc(writes, func(a, b eventualWrite[K, V]) int { return sh.compare(a.key, b.key) })
+	out := make([]Entry[K, V], 0, len(entries)+len(writes))
+	i := 0
+	for j, w := range writes {
+		if j+1 < len(writes) && sh.compare(w.key, writes[j+1].key) == 0 {
+			continue
+		}
+		for i < len(entries) && sh.compare(entries[i].Key, w.key) < 0 {
+			out = append(out, entries[i])
+			i++
+		}
+		if i < len(entries) && sh.compare(entries[i].Key, w.key) == 0 {
+			i++
+		}
+		if !w.removed {
+			out = append(out, Entry[K, V]{Key: w.key, Value: w.value})
+		}
+	}
+	return append(out, entries[i:]...)
+}
@@ -0,0 +1,117 @@
+package skiphash
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSkipHashConsistencyLevels(t *testing.T) {
+	sh := New[int, int](WithMaxStaleness(time.Hour))
+	for i := range 10 {
+		sh.Insert(i, i)
+	}
+
+	for _, c := range []Consistency{Strong, Snapshot, Eventual} {
+		got, ok := sh.GetWith(3, c)
+		assert.True(t, ok, "consistency %d", c)
+		assert.Equal(t, 3, got)
+		assert.Len(t, sh.RangeWith(2, 5, c), 4, "consistency %d", c)
+	}
+
+	sh.Store(3, 30)
+	sh.Remove(4)
+
+	got, _ := sh.GetWith(3, Strong)
+	assert.Equal(t, 30, got)
+	assert.Len(t, sh.RangeWith(2, 5, Snapshot), 3)
+
+	got, _ = sh.GetWith(3, Eventual)
+	assert.Equal(t, 3, got, "eventual reads are served from the cached copy")
+	assert.Len(t, sh.RangeWith(2, 5, Eventual), 4)
+	_, ok := sh.GetWith(42, Eventual)
+	assert.False(t, ok)
+	assert.Empty(t, sh.RangeWith(20, 30, Eventual))
+}
+
+func TestSkipHashEventualRefresh(t *testing.T) {
+	sh := New[int, int](WithMaxStaleness(time.Millisecond))
+	sh.Insert(1, 1)
+	got, _ := sh.GetWith(1, Eventual)
+	assert.Equal(t, 1, got)
+
+	sh.Store(1, 2)
+	assert.Eventually(t, func() bool {
+		got, _ := sh.GetWith(1, Eventual)
+		return got == 2
+	}, time.Second, time.Millisecond)
+}
+
+func TestSkipHashEventualConcurrent(t *testing.T) {
+	sh := New[int, int](WithMaxStaleness(time.Microsecond))
+	var wg sync.WaitGroup
+	for w := range 4 {
+		wg.Go(func() {
+			for i := range 500 {
+				sh.Store(i, w)
+				sh.GetWith(i, Eventual)
+				sh.RangeWith(i, i+10, Eventual)
+			}
+		})
+	}
+	wg.Wait()
+	time.Sleep(time.Millisecond)
+	assert.Equal(t, 500, len(sh.RangeWith(0, 1000, Eventual)))
+}
+
+func TestSkipHashEventualMergesWrites(t *testing.T) {
+	for _, descending := range []bool{false, true} {
+		sh := New[int, int](WithMaxStaleness(time.Millisecond))
+		low, high := -1, 2000
+		if descending {
+			sh = New[int, int](WithMaxStaleness(time.Millisecond), WithDescending())
+			low, high = high, low
+		}
+		for i := range 1000 {
+			sh.Insert(i, i)
+		}
+		assert.Len(t, sh.RangeWith(low, high, Eventual), 1000)
+
+		// A few writes are merged into the previous copy, the rest are
+		// past the limit and rebuild it from the list.
+		for _, n := range []int{10, 1000} {
+			for i := range n {
+				k := (i * 7919) % 1500
+				switch i % 3 {
+				case 0:
+					sh.Store(k, -i)
+				case 1:
+					sh.Remove(k)
+				default:
+					sh.Store(k, i)
+					sh.Store(k, i+1)
+				}
+			}
+			time.Sleep(2 * time.Millisecond)
+			assert.Equal(t, sh.RangeAll(), sh.RangeWith(low, high, Eventual))
+		}
+	}
+}
+
+func TestSkipHashEventualBuiltOnce(t *testing.T) {
+	sh := New[int, int](WithMaxStaleness(time.Hour))
+	for i := range 10000 {
+		sh.Insert(i, i)
+	}
+	views := make([][]Entry[int, int], 8)
+	var wg sync.WaitGroup
+	for i := range views {
+		wg.Go(func() { views[i] = sh.eventualEntries() })
+	}
+	wg.Wait()
+	for _, view := range views {
+		assert.Same(t, &views[0][0], &view[0])
+	}
+}
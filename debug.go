@@ -0,0 +1,99 @@
+package skiphash
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+)
+
+// debugMaxSampleKeys bounds the key sample a debug request may ask for.
+const debugMaxSampleKeys = 1000
+
+type debugState[K any] struct {
+	Stats        Stats        `json:"stats"`
+	Len          int          `json:"len"`
+	Tombstones   int          `json:"tombstones"`
+	MaxLevel     int          `json:"max_level"`
+	Version      uint64       `json:"version"`
+	Levels       []int        `json:"height_histogram"`
+	ActiveRanges []debugRange `json:"active_ranges"`
+	SampleKeys   []K          `json:"sample_keys,omitempty"`
+}
+
+type debugRange struct {
+	Version  uint64 `json:"version"`
+	Deferred int    `json:"deferred"`
+}
+
+// DebugHandler returns a read-only http.Handler that reports the structure's
+// counters, Stats, level histogram and active range operations as JSON. A bounded
+// sample of the smallest keys is included when the request carries
+// ?keys=N. The handler is meant to be mounted under /debug/skiphash.
+func (sh *SkipHash[K, V]) DebugHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		sample := 0
+		if raw := r.URL.Query().Get("keys"); raw != "" {
+			n, err := strconv.Atoi(raw)
+			if err != nil || n < 0 {
+				http.Error(w, "invalid keys parameter", http.StatusBadRequest)
+				return
+			}
+			sample = min(n, debugMaxSampleKeys)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(sh.debugState(sample))
+	})
+}
+
+func (sh *SkipHash[K, V]) debugState(sample int) debugState[K] {
+	stats := sh.Stats()
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+
+	state := debugState[K]{
+		Stats:        stats,
+		Len:          stats.Live,
+		MaxLevel:     sh.maxLevel,
+		Version:      sh.rqc.counter,
+		Levels:       make([]int, sh.maxLevel),
+		ActiveRanges: []debugRange{},
+	}
+	if sh.fine != nil {
+		// The entries live in the fine-grained list, which has no
+		// tombstones or range operations.
+		sh.fine.walk(sh.fine.head, func(node *fineNode[K, V]) bool {
+			state.Levels[len(node.next)-1]++
+			if len(state.SampleKeys) < sample {
+				state.SampleKeys = append(state.SampleKeys, node.key)
+			}
+			return true
+		})
+		return state
+	}
+	for node := sh.head.next[0]; node != sh.tail; node = node.next[0] {
+		if node.rTime != 0 {
+			state.Tombstones++
+			continue
+		}
+		state.Levels[node.height-1]++
+		if len(state.SampleKeys) < sample {
+			state.SampleKeys = append(state.SampleKeys, node.key)
+		}
+	}
+	for op := sh.rqc.head; op != nil; op = op.next {
+		state.ActiveRanges = append(state.ActiveRanges, debugRange{
+			Version:  op.ver,
+			Deferred: len(op.deferred),
+		})
+	}
+	return state
+}
@@ -0,0 +1,73 @@
+package skiphash
+
+import (
+	"encoding/json"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSkipHashDebugHandler(t *testing.T) {
+	sh := New[int, string](WithRandSource(rand.NewSource(8)), WithMaxLevel(4))
+	for i := range 10 {
+		sh.Insert(i, "v")
+	}
+
+	sh.mu.Lock()
+	ver := sh.rqc.onRangeLocked()
+	sh.mu.Unlock()
+	sh.Remove(3)
+
+	rec := httptest.NewRecorder()
+	sh.DebugHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/skiphash?keys=3", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	var got debugState[int]
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+	assert.Equal(t, 9, got.Len)
+	assert.Equal(t, 1, got.Tombstones)
+	assert.Equal(t, sh.Stats(), got.Stats)
+	assert.Equal(t, 4, got.MaxLevel)
+	assert.Len(t, got.Levels, 4)
+	total := 0
+	for _, n := range got.Levels {
+		total += n
+	}
+	assert.Equal(t, 9, total)
+	assert.Equal(t, []debugRange{{Version: ver, Deferred: 1}}, got.ActiveRanges)
+	assert.Equal(t, []int{0, 1, 2}, got.SampleKeys)
+
+	rec = httptest.NewRecorder()
+	sh.DebugHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/?keys=-1", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = httptest.NewRecorder()
+	sh.DebugHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
+
+func TestSkipHashDebugHandlerFineGrained(t *testing.T) {
+	sh := New[int, int](WithConcurrencyMode(FineGrained), WithMaxLevel(4))
+	for i := range 10 {
+		sh.Store(i, i)
+	}
+	sh.Remove(3)
+
+	rec := httptest.NewRecorder()
+	sh.DebugHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/?keys=3", nil))
+	var got debugState[int]
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+	assert.Equal(t, 9, got.Len)
+	assert.Equal(t, 9, got.Stats.Live)
+	total := 0
+	for _, n := range got.Levels {
+		total += n
+	}
+	assert.Equal(t, 9, total)
+	assert.Equal(t, []int{0, 1, 2}, got.SampleKeys)
+}
@@ -0,0 +1,145 @@
+package skiphash
+
+import (
+	"fmt"
+	"slices"
+)
+
+// DuplicatePolicy controls how bulk imports treat repeated keys, both within
+// the input and against keys that are already live.
+type DuplicatePolicy int
+
+const (
+	// KeepLast lets later entries overwrite earlier ones. It is the default.
+	KeepLast DuplicatePolicy = iota
+	// KeepFirst keeps the first value seen and ignores later ones.
+	KeepFirst
+	// RejectDuplicates fails the import with ErrDuplicateKey or ErrKeyExists.
+	RejectDuplicates
+	// MergeDuplicates folds duplicates with the resolver from
+	// WithDuplicateResolver.
+	MergeDuplicates
+)
+
+// ImportOption configures InsertAll.
+type ImportOption func(*importConfig)
+
+type importConfig struct {
+	policy   DuplicatePolicy
+	resolver any
+}
+
+// WithDuplicates sets the duplicate policy for an import.
+func WithDuplicates(policy DuplicatePolicy) ImportOption {
+	return func(cfg *importConfig) {
+		cfg.policy = policy
+	}
+}
+
+// WithDuplicateResolver merges duplicates with resolve, which receives the
+// earlier value and the later one. It implies MergeDuplicates.
+func WithDuplicateResolver[K any, V any](resolve func(key K, earlier, later V) V) ImportOption {
+	return func(cfg *importConfig) {
+		cfg.policy = MergeDuplicates
+		cfg.resolver = resolve
+	}
+}
+
+// InsertAll imports entries in any order, resolving repeated keys with the
+// configured DuplicatePolicy. Entries are applied in key order, so duplicates
+// are resolved in input order regardless of where they appear. With
+// RejectDuplicates nothing is written if any key repeats or already exists. It
+// returns the number of keys that were newly inserted; a quota rejection stops
+// the import at that key, as does ErrOverWeight.
+func (sh *SkipHash[K, V]) InsertAll(entries []Entry[K, V], opts ...ImportOption) (int, error) {
+	sh.unsupportedInFineGrained()
+	cfg := importConfig{policy: KeepLast}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	var resolve func(K, V, V) V
+	if cfg.policy == MergeDuplicates {
+		if cfg.resolver == nil {
+			panic("skiphash: MergeDuplicates requires WithDuplicateResolver")
+		}
+		resolve = typedOption[func(K, V, V) V](cfg.resolver, "WithDuplicateResolver")
+	}
+
+	sorted, err := sh.sortForImport(entries, cfg.policy, resolve)
+	if err != nil {
+		return 0, err
+	}
+
+	sh.mu.Lock()
+	defer sh.unlock()
+
+	if len(sorted) > 0 {
+		sh.faultInLocked(sorted[0].Key, sorted[len(sorted)-1].Key)
+	}
+	if cfg.policy == RejectDuplicates {
+		for _, e := range sorted {
+			if _, exists := sh.index.get(e.Key); exists {
+				return 0, fmt.Errorf("%w: key %v", ErrKeyExists, e.Key)
+			}
+		}
+	}
+
+	sh.beginBatchLocked()
+	defer sh.endBatchLocked()
+	inserted := 0
+	for _, e := range sorted {
+		if node, exists := sh.index.get(e.Key); exists {
+			var err error
+			switch cfg.policy {
+			case KeepLast:
+				err = sh.updateLocked(node, e.Value)
+			case MergeDuplicates:
+				err = sh.updateLocked(node, resolve(e.Key, *node.value.Load(), e.Value))
+			}
+			if err != nil {
+				return inserted, fmt.Errorf("key %v: %w", e.Key, err)
+			}
+			continue
+		}
+		if err := sh.insertLocked(e.Key, e.Value); err != nil {
+			return inserted, fmt.Errorf("key %v: %w", e.Key, err)
+		}
+		inserted++
+	}
+	return inserted, nil
+}
+
+// sortForImport returns a normalized copy of entries in key order with
+// duplicates resolved by policy.
+func (sh *SkipHash[K, V]) sortForImport(entries []Entry[K, V], policy DuplicatePolicy, resolve func(K, V, V) V) ([]Entry[K, V], error) {
+	sorted := make([]Entry[K, V], len(entries))
+	for i, e := range entries {
+		sorted[i] = Entry[K, V]{Key: sh.normalizeKey(e.Key), Value: e.Value}
+	}
+	slices.SortStableFunc(sorted, func(a, b Entry[K, V]) int {
+		return sh.compare(a.Key, b.Key)
+	})
+	return dedupSorted(sorted, sh.compare, policy, resolve)
+}
+
+// dedupSorted collapses runs of equal keys in sorted, which must be stably
+// sorted so that each run is in input order. It reuses the backing array.
+func dedupSorted[K any, V any](sorted []Entry[K, V], compare func(K, K) int, policy DuplicatePolicy, resolve func(K, V, V) V) ([]Entry[K, V], error) {
+	out := sorted[:0]
+	for i, e := range sorted {
+		if i == 0 || compare(e.Key, out[len(out)-1].Key) != 0 {
+			out = append(out, e)
+			continue
+		}
+		last := &out[len(out)-1]
+		switch policy {
+		case KeepLast:
+			last.Value = e.Value
+		case RejectDuplicates:
+			return nil, fmt.Errorf("%w: key %v", ErrDuplicateKey, e.Key)
+		case MergeDuplicates:
+			last.Value = resolve(e.Key, last.Value, e.Value)
+		}
+	}
+	return out, nil
+}
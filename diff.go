@@ -0,0 +1,34 @@
+package skiphash
+
+import "reflect"
+
+// Diff reports how other differs from sh: added holds the entries only in
+// other, removed the entries only in sh and changed the entries of other
+// whose value differs from sh's, compared with reflect.DeepEqual. Each slice
+// is in key order. Both maps are read-locked together and walked once, so
+// the result reflects a single moment of each.
+func (sh *SkipHash[K, V]) Diff(other *SkipHash[K, V]) (added, removed, changed []Entry[K, V]) {
+	return sh.DiffFunc(other, func(a, b V) bool { return reflect.DeepEqual(a, b) })
+}
+
+// DiffFunc is Diff with equal deciding whether two values are the same.
+func (sh *SkipHash[K, V]) DiffFunc(other *SkipHash[K, V], equal func(a, b V) bool) (added, removed, changed []Entry[K, V]) {
+	sh.unsupportedInFineGrained()
+	other.unsupportedInFineGrained()
+	sh.faultInAll()
+	other.faultInAll()
+	unlock := rlockPair(sh, other)
+	defer unlock()
+
+	mergeWalkLocked(sh, other, func(mine, theirs *slNode[K, V]) {
+		switch {
+		case mine == nil:
+			added = append(added, Entry[K, V]{Key: theirs.key, Value: *theirs.value.Load()})
+		case theirs == nil:
+			removed = append(removed, Entry[K, V]{Key: mine.key, Value: *mine.value.Load()})
+		case !equal(*mine.value.Load(), *theirs.value.Load()):
+			changed = append(changed, Entry[K, V]{Key: theirs.key, Value: *theirs.value.Load()})
+		}
+	})
+	return added, removed, changed
+}
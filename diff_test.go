@@ -0,0 +1,40 @@
+package skiphash
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	a := New[int, []string]()
+	a.Store(1, []string{"x"})
+	a.Store(2, []string{"y"})
+	a.Store(3, []string{"z"})
+	b := New[int, []string]()
+	b.Store(2, []string{"y"})
+	b.Store(3, []string{"changed"})
+	b.Store(4, []string{"new"})
+
+	added, removed, changed := a.Diff(b)
+	require.Equal(t, []Entry[int, []string]{{Key: 4, Value: []string{"new"}}}, added)
+	require.Equal(t, []Entry[int, []string]{{Key: 1, Value: []string{"x"}}}, removed)
+	require.Equal(t, []Entry[int, []string]{{Key: 3, Value: []string{"changed"}}}, changed)
+
+	added, removed, changed = a.Diff(a)
+	require.Empty(t, added)
+	require.Empty(t, removed)
+	require.Empty(t, changed)
+}
+
+func TestDiffFunc(t *testing.T) {
+	a, b := New[string, float64](), New[string, float64]()
+	a.Store("k", 1.0)
+	b.Store("k", 1.0001)
+	within := func(x, y float64) bool { return x-y < 0.01 && y-x < 0.01 }
+
+	_, _, changed := a.DiffFunc(b, within)
+	require.Empty(t, changed)
+	_, _, changed = a.Diff(b)
+	require.Len(t, changed, 1)
+}
@@ -0,0 +1,178 @@
+package skiphash
+
+import (
+	"bufio"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// WithSnapshotCipher encrypts everything the SkipHash persists with aead:
+// SaveTo streams and snapshot files, WAL records and checkpoints, and
+// WithTiering spill files. Nonces are random, so aead should tolerate that
+// many random nonces, as AES-GCM from cipher.NewGCMWithRandomNonce or
+// XChaCha20-Poly1305 do. Reading data that was not written with the same
+// key fails with ErrBadSnapshot, as does reading unencrypted data: load it
+// into a SkipHash without the option and save it again to migrate.
+func WithSnapshotCipher(aead cipher.AEAD) Option {
+	return func(cfg *config) {
+		if aead != nil {
+			cfg.cipher = aead
+		}
+	}
+}
+
+// seal appends the nonce and the sealed plaintext to dst.
+func seal(aead cipher.AEAD, dst, plaintext, ad []byte) []byte {
+	n := len(dst)
+	dst = append(dst, make([]byte, aead.NonceSize())...)
+	nonce := dst[n:]
+	rand.Read(nonce)
+	return aead.Seal(dst, nonce, plaintext, ad)
+}
+
+// unseal reverses seal, appending the plaintext to dst.
+func unseal(aead cipher.AEAD, dst, sealed, ad []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, fmt.Errorf("%w: sealed data too short", ErrBadSnapshot)
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(dst, nonce, ciphertext, ad)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrBadSnapshot, err)
+	}
+	return plaintext, nil
+}
+
+// Sealed streams are split into chunks, each sealed on its own:
+//
+//	final flag byte | uvarint length | nonce | ciphertext
+//
+// The additional data of a chunk is its index and final flag, so chunks
+// cannot be reordered, dropped or cut off after the last one.
+const sealChunkSize = 64 << 10
+
+func sealChunkAD(index uint64, final bool) []byte {
+	ad := binary.BigEndian.AppendUint64(nil, index)
+	if final {
+		return append(ad, 1)
+	}
+	return append(ad, 0)
+}
+
+type sealWriter struct {
+	aead  cipher.AEAD
+	w     io.Writer
+	index uint64
+	buf   []byte
+	out   []byte
+}
+
+func newSealWriter(aead cipher.AEAD, w io.Writer) *sealWriter {
+	return &sealWriter{aead: aead, w: w, buf: make([]byte, 0, sealChunkSize)}
+}
+
+func (s *sealWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		n := min(len(p), sealChunkSize-len(s.buf))
+		s.buf = append(s.buf, p[:n]...)
+		p = p[n:]
+		written += n
+		if len(s.buf) == sealChunkSize {
+			if err := s.flush(false); err != nil {
+				return written, err
+			}
+		}
+	}
+	return written, nil
+}
+
+// Close seals the final chunk; it does not close the underlying writer.
+func (s *sealWriter) Close() error {
+	return s.flush(true)
+}
+
+func (s *sealWriter) flush(final bool) error {
+	s.out = append(s.out[:0], 0)
+	if final {
+		s.out[0] = 1
+	}
+	sealed := seal(s.aead, nil, s.buf, sealChunkAD(s.index, final))
+	s.out = binary.AppendUvarint(s.out, uint64(len(sealed)))
+	s.out = append(s.out, sealed...)
+	s.index++
+	s.buf = s.buf[:0]
+	_, err := s.w.Write(s.out)
+	return err
+}
+
+type sealReader struct {
+	aead  cipher.AEAD
+	r     *bufio.Reader
+	index uint64
+	done  bool
+	plain []byte
+	buf   []byte
+}
+
+func newSealReader(aead cipher.AEAD, r io.Reader) *sealReader {
+	br, ok := r.(*bufio.Reader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+	return &sealReader{aead: aead, r: br}
+}
+
+func (s *sealReader) Read(p []byte) (int, error) {
+	for len(s.buf) == 0 {
+		if s.done {
+			return 0, io.EOF
+		}
+		if err := s.next(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, s.buf)
+	s.buf = s.buf[n:]
+	return n, nil
+}
+
+func (s *sealReader) next() error {
+	flag, err := s.r.ReadByte()
+	if err != nil {
+		return snapshotReadError(err)
+	}
+	if flag > 1 {
+		return fmt.Errorf("%w: bad sealed chunk", ErrBadSnapshot)
+	}
+	sealed, err := readChunk(s.r)
+	if err != nil {
+		return snapshotReadError(err)
+	}
+	final := flag == 1
+	if s.plain, err = unseal(s.aead, s.plain[:0], sealed, sealChunkAD(s.index, final)); err != nil {
+		return err
+	}
+	s.index++
+	s.done = final
+	s.buf = s.plain
+	return nil
+}
+
+// writeCloserChain closes next after the writer it wraps, for writers
+// stacked on top of one another.
+type writeCloserChain struct {
+	io.WriteCloser
+	next io.Closer
+}
+
+func (c writeCloserChain) Close() error {
+	err := c.WriteCloser.Close()
+	if nextErr := c.next.Close(); err == nil {
+		err = nextErr
+	}
+	return err
+}
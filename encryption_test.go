@@ -0,0 +1,108 @@
+package skiphash
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testAEAD(t *testing.T, key byte) cipher.AEAD {
+	block, err := aes.NewCipher(bytes.Repeat([]byte{key}, 32))
+	require.NoError(t, err)
+	aead, err := cipher.NewGCM(block)
+	require.NoError(t, err)
+	return aead
+}
+
+func TestSnapshotCipher(t *testing.T) {
+	aead := testAEAD(t, 1)
+	for _, opts := range [][]Option{
+		{WithSnapshotCipher(aead)},
+		{WithSnapshotCipher(aead), WithCompression(Gzip(gzip.DefaultCompression))},
+	} {
+		sh := New[int, string](opts...)
+		for i := range 20000 {
+			sh.Store(i, "secret-"+strconv.Itoa(i))
+		}
+		var buf bytes.Buffer
+		require.NoError(t, sh.SaveTo(&buf))
+		data := buf.Bytes()
+		require.NotContains(t, string(data), "secret")
+
+		out := New[int, string](opts...)
+		require.NoError(t, out.LoadFrom(bytes.NewReader(data)))
+		require.Equal(t, sh.RangeAll(), out.RangeAll())
+
+		require.ErrorIs(t, New[int, string]().LoadFrom(bytes.NewReader(data)), ErrBadSnapshot)
+		wrongKey := New[int, string](append(opts[1:], WithSnapshotCipher(testAEAD(t, 2)))...)
+		require.ErrorIs(t, wrongKey.LoadFrom(bytes.NewReader(data)), ErrBadSnapshot)
+		// Truncation and tampering are both caught by authentication.
+		require.ErrorIs(t, out.LoadFrom(bytes.NewReader(data[:len(data)-40])), ErrBadSnapshot)
+		tampered := bytes.Clone(data)
+		tampered[len(tampered)/2] ^= 1
+		require.ErrorIs(t, out.LoadFrom(bytes.NewReader(tampered)), ErrBadSnapshot)
+	}
+
+	var plain bytes.Buffer
+	require.NoError(t, New[int, string]().SaveTo(&plain))
+	require.ErrorIs(t, New[int, string](WithSnapshotCipher(aead)).LoadFrom(&plain), ErrBadSnapshot)
+}
+
+func TestWALCipher(t *testing.T) {
+	aead := testAEAD(t, 1)
+	dir := t.TempDir()
+	sh := New[string, string](WithWAL(dir), WithSnapshotCipher(aead))
+	sh.Store("a", "secret-a")
+	require.NoError(t, sh.CheckpointWAL())
+	sh.Store("b", "secret-b")
+	sh.Remove("a")
+	require.NoError(t, sh.CloseWAL())
+
+	files, err := filepath.Glob(filepath.Join(dir, "*"))
+	require.NoError(t, err)
+	for _, path := range files {
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		require.NotContains(t, string(data), "secret", path)
+	}
+
+	_, err = Recover[string, string](dir)
+	require.ErrorIs(t, err, ErrBadSnapshot)
+	got, err := Recover[string, string](dir, WithSnapshotCipher(aead))
+	require.NoError(t, err)
+	require.Equal(t, sh.RangeAll(), got.RangeAll())
+	require.NoError(t, got.CloseWAL())
+}
+
+func TestTieringCipher(t *testing.T) {
+	dir := t.TempDir()
+	sh := New[int, string](WithTiering(dir), WithSnapshotCipher(testAEAD(t, 1)))
+	for i := range 100 {
+		sh.Insert(i, "secret")
+	}
+	time.Sleep(5 * time.Millisecond)
+	spilled, err := sh.SpillCold(time.Millisecond, 10)
+	require.NoError(t, err)
+	require.Equal(t, 100, spilled)
+
+	files, err := filepath.Glob(filepath.Join(dir, "segment-*"))
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+	for _, path := range files {
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		require.NotContains(t, string(data), "secret")
+	}
+	v, ok := sh.Get(50)
+	require.True(t, ok)
+	require.Equal(t, "secret", v)
+	require.Equal(t, 100, sh.Len())
+}
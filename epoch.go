@@ -0,0 +1,116 @@
+package skiphash
+
+import "sync/atomic"
+
+// reclaimBatch is the number of retired nodes that triggers an attempt to
+// advance the epoch and reclaim.
+const reclaimBatch = 64
+
+// epochs implements three-epoch reclamation for nodes that lock-free readers
+// may still hold after a writer unstitches them. Readers pin the current
+// epoch for the duration of a read; a node retired in epoch e is reclaimed
+// once the epoch reaches e+2, when no reader pinned before the unstitch can
+// remain.
+type epochs[K any, V any] struct {
+	global atomic.Uint64
+	active [3]atomic.Int64
+
+	// Guarded by the SkipHash write lock.
+	limbo     []retiredNode[K, V]
+	retired   uint64
+	reclaimed uint64
+
+	// pool receives reclaimed nodes when WithNodePool is set.
+	pool *nodePool[K, V]
+}
+
+type retiredNode[K any, V any] struct {
+	node  *slNode[K, V]
+	epoch uint64
+}
+
+// ReclamationStats reports the progress of node reclamation.
+type ReclamationStats struct {
+	Epoch     uint64
+	Retired   uint64
+	Reclaimed uint64
+	// Pending counts retired nodes still waiting for readers to move on.
+	Pending int
+	// Reused counts inserts served from the WithNodePool pool.
+	Reused uint64
+}
+
+// pin enters the current epoch and returns it for unpin.
+func (e *epochs[K, V]) pin() uint64 {
+	for {
+		epoch := e.global.Load()
+		e.active[epoch%3].Add(1)
+		if e.global.Load() == epoch {
+			return epoch
+		}
+		e.active[epoch%3].Add(-1)
+	}
+}
+
+func (e *epochs[K, V]) unpin(epoch uint64) {
+	e.active[epoch%3].Add(-1)
+}
+
+// retireLocked queues an unstitched node for reclamation.
+func (e *epochs[K, V]) retireLocked(node *slNode[K, V]) {
+	e.limbo = append(e.limbo, retiredNode[K, V]{node: node, epoch: e.global.Load()})
+	e.retired++
+	if len(e.limbo) >= reclaimBatch {
+		e.reclaimLocked()
+	}
+}
+
+// reclaimLocked advances the epoch as far as pinned readers allow and
+// recycles every node that is now unreachable.
+func (e *epochs[K, V]) reclaimLocked() {
+	for range 2 {
+		epoch := e.global.Load()
+		if e.active[(epoch+2)%3].Load() != 0 {
+			break
+		}
+		e.global.Store(epoch + 1)
+	}
+
+	epoch := e.global.Load()
+	kept := e.limbo[:0]
+	for _, r := range e.limbo {
+		if r.epoch+2 > epoch {
+			kept = append(kept, r)
+			continue
+		}
+		recycle(r.node)
+		if e.pool != nil {
+			e.pool.put(r.node)
+		}
+		e.reclaimed++
+	}
+	clear(e.limbo[len(kept):])
+	e.limbo = kept
+}
+
+// recycle drops the references an unreachable node still holds.
+func recycle[K any, V any](node *slNode[K, V]) {
+	node.value.Store(nil)
+	node.history = nil
+}
+
+// ReclamationStats returns counters for the reclamation of removed nodes.
+func (sh *SkipHash[K, V]) ReclamationStats() ReclamationStats {
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+	stats := ReclamationStats{
+		Epoch:     sh.epochs.global.Load(),
+		Retired:   sh.epochs.retired,
+		Reclaimed: sh.epochs.reclaimed,
+		Pending:   len(sh.epochs.limbo),
+	}
+	if sh.epochs.pool != nil {
+		stats.Reused = sh.epochs.pool.reused
+	}
+	return stats
+}
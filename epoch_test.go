@@ -0,0 +1,103 @@
+package skiphash
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSkipHashReclamation(t *testing.T) {
+	sh := New[int, []byte]()
+	for k := range 1000 {
+		sh.Store(k, make([]byte, 16))
+	}
+	for k := range 1000 {
+		sh.Remove(k)
+	}
+	stats := sh.ReclamationStats()
+	assert.Equal(t, uint64(1000), stats.Retired)
+	assert.NotZero(t, stats.Reclaimed)
+	assert.Equal(t, int(stats.Retired-stats.Reclaimed), stats.Pending)
+	assert.LessOrEqual(t, stats.Pending, reclaimBatch)
+}
+
+func TestSkipHashReclamationWaitsForReaders(t *testing.T) {
+	sh := New[int, int]()
+	epoch := sh.epochs.pin()
+	for k := range 4 * reclaimBatch {
+		sh.Store(k, k)
+		sh.Remove(k)
+	}
+	stats := sh.ReclamationStats()
+	assert.Equal(t, uint64(4*reclaimBatch), stats.Retired)
+	assert.LessOrEqual(t, stats.Epoch, epoch+1, "a pinned reader holds the epoch back")
+	assert.Zero(t, stats.Reclaimed)
+
+	sh.epochs.unpin(epoch)
+	for k := range reclaimBatch {
+		sh.Store(k, k)
+		sh.Remove(k)
+	}
+	assert.NotZero(t, sh.ReclamationStats().Reclaimed)
+}
+
+func TestSkipHashReclamationConcurrentReads(t *testing.T) {
+	sh := New[int, int]()
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for k := 0; ; k = (k + 1) % 128 {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				if v, ok := sh.Get(k); ok {
+					assert.Equal(t, k, v)
+				}
+			}
+		}()
+	}
+	for range 50 {
+		for k := range 128 {
+			sh.Store(k, k)
+		}
+		for k := range 128 {
+			sh.Remove(k)
+		}
+	}
+	close(stop)
+	wg.Wait()
+	assert.NotZero(t, sh.ReclamationStats().Reclaimed)
+}
+
+func TestSkipHashNodePool(t *testing.T) {
+	sh := New[int, int](WithNodePool())
+	for round := range 20 {
+		for k := range 256 {
+			sh.Store(k, k+round)
+		}
+		for k := 0; k < 256; k += 2 {
+			sh.Remove(k)
+		}
+		for k := 0; k < 256; k += 2 {
+			sh.Insert(k, -k)
+		}
+		for k := range 256 {
+			sh.Remove(k)
+		}
+	}
+	for k := range 100 {
+		sh.Store(k, k)
+	}
+	assert.NotZero(t, sh.ReclamationStats().Reused)
+	assert.Equal(t, 100, sh.Len())
+	for i, e := range sh.RangeAll() {
+		assert.Equal(t, Entry[int, int]{Key: i, Value: i}, e)
+		assert.Equal(t, i, sh.Rank(i))
+	}
+}
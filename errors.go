@@ -0,0 +1,35 @@
+package skiphash
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrKeyExists     = errors.New("skiphash: key already exists")
+	ErrQuotaExceeded = errors.New("skiphash: tenant quota exceeded")
+	ErrUnsorted      = errors.New("skiphash: entries are not sorted by key")
+	ErrDuplicateKey  = errors.New("skiphash: duplicate key in input")
+	ErrNoMigration   = errors.New("skiphash: no value migration registered")
+	ErrOverWeight    = errors.New("skiphash: weight budget exceeded")
+	ErrUninitialized = errors.New("skiphash: SkipHash must be created with New or NewFunc")
+	ErrBadSnapshot   = errors.New("skiphash: malformed snapshot")
+	ErrBadCursor     = errors.New("skiphash: malformed cursor")
+	ErrIndexExists   = errors.New("skiphash: index already exists")
+	ErrConflict      = errors.New("skiphash: transaction conflicts with a concurrent write")
+	ErrOverlap       = errors.New("skiphash: key ranges overlap")
+)
+
+// QuotaError is returned when a write would push a tenant above its quota.
+type QuotaError struct {
+	Tenant any
+	Limit  int
+}
+
+func (e *QuotaError) Error() string {
+	return fmt.Sprintf("skiphash: tenant %v reached quota of %d entries", e.Tenant, e.Limit)
+}
+
+func (e *QuotaError) Unwrap() error {
+	return ErrQuotaExceeded
+}
@@ -0,0 +1,83 @@
+package skiphash
+
+// EvictionPolicy selects the entry WithMaxEntries evicts when the SkipHash is
+// over capacity.
+type EvictionPolicy uint8
+
+const (
+	// EvictLRU evicts the least recently accessed entry.
+	EvictLRU EvictionPolicy = iota + 1
+	// EvictLFU evicts the least frequently accessed entry.
+	EvictLFU
+	// EvictOldest evicts the entry written longest ago.
+	EvictOldest
+)
+
+const (
+	// evictionScanLimit is the size up to which eviction compares every entry;
+	// larger maps compare evictionSamples random entries, as Redis does.
+	evictionScanLimit = 64
+	evictionSamples   = 5
+)
+
+// WithMaxEntries caps the number of in-memory entries at n. An insert that
+// exceeds the cap evicts another entry chosen by policy, reported through
+// Hooks.OnEvict and as a ChangeRemove to watchers. On large maps the choice
+// is approximate. With n <= 0 the count is unbounded and policy only
+// applies to WithMaxWeight.
+func WithMaxEntries(n int, policy EvictionPolicy) Option {
+	return func(cfg *config) {
+		if policy >= EvictLRU && policy <= EvictOldest {
+			cfg.maxEntries = max(n, 0)
+			cfg.eviction = policy
+		}
+	}
+}
+
+// enforceCapsLocked evicts entries other than fresh, the node just written,
+// until the SkipHash is back within WithMaxEntries and WithMaxWeight.
+func (sh *SkipHash[K, V]) enforceCapsLocked(fresh *slNode[K, V]) {
+	if sh.eviction == 0 {
+		return
+	}
+	for sh.len > 1 && (sh.maxEntries > 0 && sh.len > sh.maxEntries || sh.maxWeight > 0 && sh.weight > sh.maxWeight) {
+		sh.evictLocked(fresh)
+	}
+}
+
+// evictLocked evicts one entry other than fresh, the node just inserted.
+func (sh *SkipHash[K, V]) evictLocked(fresh *slNode[K, V]) {
+	var victim *slNode[K, V]
+	consider := func(node *slNode[K, V]) {
+		if node != fresh && (victim == nil || sh.evictsBefore(node, victim)) {
+			victim = node
+		}
+	}
+	if sh.len <= evictionScanLimit {
+		for node := sh.firstLiveLocked(); node != nil; node = sh.nextLiveLocked(node) {
+			consider(node)
+		}
+	} else {
+		for range evictionSamples {
+			consider(sh.selectLocked(sh.rng.Intn(sh.len)))
+		}
+		if victim == nil {
+			if victim = sh.firstLiveLocked(); victim == fresh {
+				victim = sh.nextLiveLocked(victim)
+			}
+		}
+	}
+	if victim != nil {
+		sh.dropLocked(victim, true)
+	}
+}
+
+func (sh *SkipHash[K, V]) evictsBefore(a, b *slNode[K, V]) bool {
+	switch sh.eviction {
+	case EvictLRU:
+		return a.lastAccess.Load() < b.lastAccess.Load()
+	case EvictLFU:
+		return a.hits.Load() < b.hits.Load()
+	}
+	return a.version < b.version
+}
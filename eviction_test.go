@@ -0,0 +1,47 @@
+package skiphash
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSkipHashMaxEntries(t *testing.T) {
+	tests := []struct {
+		policy  EvictionPolicy
+		evicted int
+	}{
+		{EvictLRU, 2},
+		{EvictLFU, 1},
+		{EvictOldest, 3},
+	}
+	for _, tt := range tests {
+		var evicted []int
+		sh := New[int, int](WithMaxEntries(3, tt.policy), WithHooks(Hooks[int, int]{
+			OnEvict: func(key, _ int) { evicted = append(evicted, key) },
+		}))
+		sh.Store(3, 3)
+		sh.Store(1, 1)
+		sh.Store(2, 2)
+		for _, k := range []int{2, 2, 2, 3, 3, 1} {
+			sh.Get(k)
+		}
+
+		sh.Store(4, 4)
+		assert.Equal(t, 3, sh.Len(), "policy %d", tt.policy)
+		assert.Equal(t, []int{tt.evicted}, evicted, "policy %d", tt.policy)
+		assert.True(t, sh.Contains(4))
+		checkSpans(t, sh)
+	}
+}
+
+func TestSkipHashMaxEntriesSampled(t *testing.T) {
+	const limit = 4 * evictionScanLimit
+	sh := New[int, int](WithMaxEntries(limit, EvictOldest))
+	for k := range 10 * limit {
+		sh.Store(k, k)
+	}
+	assert.Equal(t, limit, sh.Len())
+	assert.True(t, sh.Contains(10*limit-1), "the newest entry is never evicted")
+	checkSpans(t, sh)
+}
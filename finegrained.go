@@ -0,0 +1,365 @@
+package skiphash
+
+import (
+	"math/bits"
+	"math/rand/v2"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// ConcurrencyMode selects how a SkipHash synchronizes its operations.
+type ConcurrencyMode uint8
+
+const (
+	// GlobalLock guards the whole structure with one RWMutex. It supports
+	// every feature and is the default.
+	GlobalLock ConcurrencyMode = iota
+	// FineGrained locks only the nodes around a write, following the lazy
+	// skip list of Herlihy et al., so writers to disjoint keys do not
+	// serialize; reads take no locks. It supports Get, Contains, Insert,
+	// TryInsert, Store, TryStore, Remove, Len and Stats, the neighbour
+	// reads Ceil, Floor, Succ, Pred, WalkFrom, NextN, PrevN, KSmallest,
+	// KLargest, Rank, CountLess and Select, and the scans Range, RangeAll,
+	// RangeCount, RangeAppend, RangeKeys, RangeValues, RangeBounds,
+	// RangePage, RangeParallel, RangeWhile, RangePrefix and the context
+	// ranges. Other operations, such as snapshots, TTLs, transactions and
+	// watches, panic, and options that need the global structure are
+	// rejected by New. Reads are weakly consistent; RangeCount, Rank and
+	// Select are linear in the entries they count.
+	FineGrained
+)
+
+// WithConcurrencyMode selects the synchronization strategy.
+func WithConcurrencyMode(mode ConcurrencyMode) Option {
+	return func(cfg *config) {
+		cfg.concurrency = mode
+	}
+}
+
+// checkFineGrained panics if cfg combines FineGrained with an option that
+// needs the globally locked structure.
+func checkFineGrained(cfg *config) {
+	incompatible := cfg.quota != nil || cfg.buckets != nil || cfg.hooks != nil ||
+		cfg.tierDir != "" || cfg.historyDepth > 0 || cfg.versionIndex ||
+		cfg.eviction != 0 || cfg.weigher != nil || len(cfg.valueMigrations) > 0 || cfg.walDir != "" ||
+		cfg.rangeStats != nil || len(cfg.aggregates) > 0 || cfg.valueOrder != nil ||
+		cfg.changelog > 0
+	if incompatible {
+		panic("skiphash: FineGrained mode does not support quotas, buckets, hooks, tiering, history, version index, eviction, weights, migrations, a WAL, range aggregates, value order or a changelog")
+	}
+}
+
+// unsupportedInFineGrained panics when an operation that needs the global
+// structure is called in FineGrained mode.
+func (sh *SkipHash[K, V]) unsupportedInFineGrained() {
+	if sh.fine != nil {
+		panic("skiphash: operation not supported in FineGrained mode")
+	}
+}
+
+type fineList[K any, V any] struct {
+	maxLevel int
+	compare  func(a, b K) int
+	head     *fineNode[K, V]
+	len      atomic.Int64
+}
+
+// fineNode is written only under mu; marked is the logical deletion flag
+// and fullyLinked is set once the node is linked at every level.
+type fineNode[K any, V any] struct {
+	mu          sync.Mutex
+	key         K
+	value       atomic.Pointer[V]
+	next        []atomic.Pointer[fineNode[K, V]]
+	marked      atomic.Bool
+	fullyLinked atomic.Bool
+}
+
+func newFineList[K any, V any](compare func(a, b K) int, maxLevel int) *fineList[K, V] {
+	head := &fineNode[K, V]{next: make([]atomic.Pointer[fineNode[K, V]], maxLevel)}
+	head.fullyLinked.Store(true)
+	return &fineList[K, V]{maxLevel: maxLevel, compare: compare, head: head}
+}
+
+// find fills preds and succs (nil past the end) and returns the highest
+// level at which key was found, or -1.
+func (l *fineList[K, V]) find(key K, preds, succs []*fineNode[K, V]) int {
+	found := -1
+	pred := l.head
+	for level := l.maxLevel - 1; level >= 0; level-- {
+		curr := pred.next[level].Load()
+		for curr != nil && l.compare(curr.key, key) < 0 {
+			pred, curr = curr, curr.next[level].Load()
+		}
+		if found == -1 && curr != nil && l.compare(curr.key, key) == 0 {
+			found = level
+		}
+		preds[level], succs[level] = pred, curr
+	}
+	return found
+}
+
+func (l *fineList[K, V]) get(key K) (V, bool) {
+	preds := make([]*fineNode[K, V], l.maxLevel)
+	succs := make([]*fineNode[K, V], l.maxLevel)
+	if found := l.find(key, preds, succs); found != -1 {
+		node := succs[found]
+		if node.fullyLinked.Load() && !node.marked.Load() {
+			return *node.value.Load(), true
+		}
+	}
+	var zero V
+	return zero, false
+}
+
+// put inserts key, or replaces its value when overwrite is set, and
+// reports whether key was inserted.
+func (l *fineList[K, V]) put(key K, value V, overwrite bool) bool {
+	preds := make([]*fineNode[K, V], l.maxLevel)
+	succs := make([]*fineNode[K, V], l.maxLevel)
+	height := min(bits.TrailingZeros64(rand.Uint64())+1, l.maxLevel)
+	for {
+		if found := l.find(key, preds, succs); found != -1 {
+			node := succs[found]
+			if node.marked.Load() {
+				continue
+			}
+			for !node.fullyLinked.Load() {
+				runtime.Gosched()
+			}
+			if !overwrite {
+				return false
+			}
+			node.mu.Lock()
+			if node.marked.Load() {
+				node.mu.Unlock()
+				continue
+			}
+			node.value.Store(&value)
+			node.mu.Unlock()
+			return false
+		}
+
+		locked, valid := l.lockPreds(preds, succs, height, false)
+		if !valid {
+			unlockAll(locked)
+			continue
+		}
+		node := &fineNode[K, V]{key: key, next: make([]atomic.Pointer[fineNode[K, V]], height)}
+		node.value.Store(&value)
+		for level := range height {
+			node.next[level].Store(succs[level])
+		}
+		for level := range height {
+			preds[level].next[level].Store(node)
+		}
+		node.fullyLinked.Store(true)
+		l.len.Add(1)
+		unlockAll(locked)
+		return true
+	}
+}
+
+func (l *fineList[K, V]) remove(key K) bool {
+	preds := make([]*fineNode[K, V], l.maxLevel)
+	succs := make([]*fineNode[K, V], l.maxLevel)
+	var victim *fineNode[K, V]
+	for {
+		found := l.find(key, preds, succs)
+		if victim == nil {
+			if found == -1 {
+				return false
+			}
+			victim = succs[found]
+			if !victim.fullyLinked.Load() || victim.marked.Load() || found != len(victim.next)-1 {
+				return false
+			}
+			victim.mu.Lock()
+			if victim.marked.Load() {
+				victim.mu.Unlock()
+				return false
+			}
+			victim.marked.Store(true)
+			l.len.Add(-1)
+		}
+
+		locked, valid := l.lockPreds(preds, succs, len(victim.next), true)
+		if !valid {
+			unlockAll(locked)
+			continue
+		}
+		for level := len(victim.next) - 1; level >= 0; level-- {
+			preds[level].next[level].Store(victim.next[level].Load())
+		}
+		victim.mu.Unlock()
+		unlockAll(locked)
+		return true
+	}
+}
+
+// lockPreds locks the distinct predecessors below height, bottom-up, and
+// validates that each is unmarked and still points to its successor, which
+// must be unmarked too unless it is the node being removed.
+func (l *fineList[K, V]) lockPreds(preds, succs []*fineNode[K, V], height int, removing bool) ([]*fineNode[K, V], bool) {
+	locked := make([]*fineNode[K, V], 0, height)
+	valid := true
+	for level := 0; valid && level < height; level++ {
+		pred, succ := preds[level], succs[level]
+		if len(locked) == 0 || locked[len(locked)-1] != pred {
+			pred.mu.Lock()
+			locked = append(locked, pred)
+		}
+		valid = !pred.marked.Load() && pred.next[level].Load() == succ
+		if removing {
+			valid = valid && succ == succs[0]
+		} else {
+			valid = valid && (succ == nil || !succ.marked.Load())
+		}
+	}
+	return locked, valid
+}
+
+func unlockAll[K any, V any](nodes []*fineNode[K, V]) {
+	for _, node := range nodes {
+		node.mu.Unlock()
+	}
+}
+
+// live reports whether the node is linked and not removed.
+func (n *fineNode[K, V]) live() bool {
+	return n.fullyLinked.Load() && !n.marked.Load()
+}
+
+func (n *fineNode[K, V]) entry() Entry[K, V] {
+	return Entry[K, V]{Key: n.key, Value: *n.value.Load()}
+}
+
+// before returns the last node below key, or at most key when inclusive; a
+// nil key is past the end. The node may be the head or a removed node.
+func (l *fineList[K, V]) before(key *K, inclusive bool) *fineNode[K, V] {
+	pred := l.head
+	for level := l.maxLevel - 1; level >= 0; level-- {
+		for curr := pred.next[level].Load(); curr != nil; curr = pred.next[level].Load() {
+			if key != nil {
+				if c := l.compare(curr.key, *key); c > 0 || c == 0 && !inclusive {
+					break
+				}
+			}
+			pred = curr
+		}
+	}
+	return pred
+}
+
+// walk calls fn for the live nodes after start, in key order, until fn
+// returns false.
+func (l *fineList[K, V]) walk(start *fineNode[K, V], fn func(node *fineNode[K, V]) bool) {
+	for node := start.next[0].Load(); node != nil; node = node.next[0].Load() {
+		if node.live() && !fn(node) {
+			return
+		}
+	}
+}
+
+// ceil returns the first live entry after key, or at key unless strict.
+func (l *fineList[K, V]) ceil(key K, strict bool) (Entry[K, V], bool) {
+	var out Entry[K, V]
+	found := false
+	l.walk(l.before(&key, strict), func(node *fineNode[K, V]) bool {
+		out, found = node.entry(), true
+		return false
+	})
+	return out, found
+}
+
+// floor returns the last live entry before key, or at key unless strict; a
+// nil key is past the end. Nodes have no back links, so a removed
+// predecessor restarts the search below it.
+func (l *fineList[K, V]) floor(key *K, strict bool) (Entry[K, V], bool) {
+	for {
+		pred := l.before(key, !strict)
+		if pred == l.head {
+			var zero Entry[K, V]
+			return zero, false
+		}
+		if pred.live() {
+			return pred.entry(), true
+		}
+		key, strict = &pred.key, true
+	}
+}
+
+// walkFrom returns up to n live entries strictly beyond key in direction
+// dir, nearest first; a nil key starts at the end the walk moves away from.
+func (l *fineList[K, V]) walkFrom(key *K, n int, dir Direction) []Entry[K, V] {
+	out := make([]Entry[K, V], 0, min(n, defaultEntryCap))
+	if dir == Descending {
+		for len(out) < n {
+			e, ok := l.floor(key, true)
+			if !ok {
+				break
+			}
+			out = append(out, e)
+			key = &out[len(out)-1].Key
+		}
+		return out
+	}
+	start := l.head
+	if key != nil {
+		start = l.before(key, true)
+	}
+	l.walk(start, func(node *fineNode[K, V]) bool {
+		out = append(out, node.entry())
+		return len(out) < n
+	})
+	return out
+}
+
+// rank counts the live keys below key by walking the base level.
+func (l *fineList[K, V]) rank(key K) int {
+	rank := 0
+	l.walk(l.head, func(node *fineNode[K, V]) bool {
+		if l.compare(node.key, key) >= 0 {
+			return false
+		}
+		rank++
+		return true
+	})
+	return rank
+}
+
+// nth returns the n-th smallest live entry by walking the base level.
+func (l *fineList[K, V]) nth(n int) (Entry[K, V], bool) {
+	var out Entry[K, V]
+	found := false
+	if n < 0 {
+		return out, false
+	}
+	l.walk(l.head, func(node *fineNode[K, V]) bool {
+		if n == 0 {
+			out, found = node.entry(), true
+			return false
+		}
+		n--
+		return true
+	})
+	return out, found
+}
+
+// entries returns the live entries in [low, high]; a nil bound is open.
+func (l *fineList[K, V]) entries(low, high *K) []Entry[K, V] {
+	start := l.head
+	if low != nil {
+		start = l.before(low, false)
+	}
+	entries := make([]Entry[K, V], 0, defaultEntryCap)
+	l.walk(start, func(node *fineNode[K, V]) bool {
+		if high != nil && l.compare(node.key, *high) > 0 {
+			return false
+		}
+		entries = append(entries, node.entry())
+		return true
+	})
+	return entries
+}
@@ -0,0 +1,118 @@
+package skiphash
+
+import (
+	"io"
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFineGrainedMatchesGlobalLock(t *testing.T) {
+	fine := New[int, int](WithConcurrencyMode(FineGrained))
+	ref := New[int, int]()
+	r := rand.New(rand.NewSource(5))
+	for range 5000 {
+		k := r.Intn(500)
+		switch r.Intn(3) {
+		case 0:
+			assert.Equal(t, ref.Remove(k), fine.Remove(k))
+		case 1:
+			assert.Equal(t, ref.TryInsert(k, k), fine.TryInsert(k, k))
+		default:
+			assert.Equal(t, ref.Store(k, -k), fine.Store(k, -k))
+		}
+	}
+	assert.Equal(t, ref.Len(), fine.Len())
+	assert.Equal(t, ref.RangeAll(), fine.RangeAll())
+	assert.Equal(t, ref.Range(100, 200), fine.Range(100, 200))
+	assert.Equal(t, ref.RangeCount(100, 200), fine.RangeCount(100, 200))
+	assert.Equal(t, ref.Len(), fine.Stats().Live)
+	assert.Equal(t, ref.KSmallest(10), fine.KSmallest(10))
+	assert.Equal(t, ref.KLargest(10), fine.KLargest(10))
+	for k := -1; k <= 500; k += 7 {
+		for _, f := range []func(*SkipHash[int, int], int) (Entry[int, int], bool){
+			(*SkipHash[int, int]).Ceil, (*SkipHash[int, int]).Floor,
+			(*SkipHash[int, int]).Succ, (*SkipHash[int, int]).Pred,
+		} {
+			want, wantOK := f(ref, k)
+			got, ok := f(fine, k)
+			assert.Equal(t, wantOK, ok)
+			assert.Equal(t, want, got)
+		}
+		assert.Equal(t, ref.NextN(k, 5), fine.NextN(k, 5))
+		assert.Equal(t, ref.PrevN(k, 5), fine.PrevN(k, 5))
+		assert.Equal(t, ref.Rank(k), fine.Rank(k))
+		want, wantOK := ref.Select(k)
+		got, ok := fine.Select(k)
+		assert.Equal(t, wantOK, ok)
+		assert.Equal(t, want, got)
+	}
+	for k := range 500 {
+		want, wantOK := ref.Get(k)
+		got, ok := fine.Get(k)
+		assert.Equal(t, wantOK, ok)
+		assert.Equal(t, want, got)
+		assert.Equal(t, wantOK, fine.Contains(k))
+	}
+}
+
+func TestFineGrainedConcurrent(t *testing.T) {
+	sh := New[int, int](WithConcurrencyMode(FineGrained), WithMaxLevel(8))
+	const workers, keys = 8, 256
+	var wg sync.WaitGroup
+	for w := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r := rand.New(rand.NewSource(int64(w)))
+			for range 5000 {
+				k := r.Intn(keys)
+				switch r.Intn(4) {
+				case 0:
+					sh.Remove(k)
+				case 1:
+					sh.Insert(k, k)
+				case 2:
+					sh.Store(k, k)
+				default:
+					if v, ok := sh.Get(k); ok {
+						assert.Equal(t, k, v)
+					}
+					sh.Range(k, k+16)
+					if e, ok := sh.Floor(k); ok {
+						assert.LessOrEqual(t, e.Key, k)
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	entries := sh.RangeAll()
+	assert.Equal(t, len(entries), sh.Len())
+	for i := 1; i < len(entries); i++ {
+		assert.Less(t, entries[i-1].Key, entries[i].Key)
+	}
+}
+
+func TestFineGrainedUnsupported(t *testing.T) {
+	sh := New[int, int](WithConcurrencyMode(FineGrained))
+	for name, op := range map[string]func(){
+		"Snapshot":  func() { sh.Snapshot() },
+		"SaveTo":    func() { sh.SaveTo(io.Discard) },
+		"InsertTTL": func() { sh.InsertTTL(1, 1, time.Hour) },
+		"Compute":   func() { sh.Compute(1, func(int, bool) (int, ComputeOp) { return 1, ComputeStore }) },
+		"Watch":     func() { sh.Watch(1) },
+		"Rekey":     func() { sh.Rekey(1, 2) },
+		"Union":     func() { New[int, int]().Union(sh) },
+		"GetWith":   func() { sh.GetWith(1, Eventual) },
+	} {
+		assert.Panics(t, op, name)
+	}
+	assert.Panics(t, func() {
+		New[int, int](WithConcurrencyMode(FineGrained), WithHistory(2, 2))
+	})
+}
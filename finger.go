@@ -0,0 +1,54 @@
+package skiphash
+
+// WithFinger remembers where the last ordered search (Ceil, Floor, Pred,
+// Succ, range starts and walks) ended and starts the next one there when its
+// key lies ahead, so a search costs O(log d) for a distance d from the
+// previous one instead of O(log n). It suits sequential access; random
+// access pays a little for keeping the finger up to date.
+func WithFinger() Option {
+	return func(cfg *config) {
+		cfg.finger = true
+	}
+}
+
+// searchStartLocked returns the node and level a search for the last node
+// before key should descend from; strict excludes key itself. Without a
+// usable finger that is the head at the top level. Otherwise it climbs from
+// the finger while the next node at the current level is still before key.
+func (sh *SkipHash[K, V]) searchStartLocked(key K, strict bool) (*slNode[K, V], int) {
+	top := sh.topLevelLocked() - 1
+	if !sh.fingers {
+		return sh.head, top
+	}
+	before := func(node *slNode[K, V]) bool {
+		if node == sh.tail {
+			return false
+		}
+		c := sh.compare(node.key, key)
+		return c < 0 || !strict && c == 0
+	}
+	cur := sh.finger.Load()
+	if cur == nil || !before(cur) {
+		return sh.head, top
+	}
+	level := 0
+	for level < top {
+		if level+1 < int(cur.height) {
+			level++
+			continue
+		}
+		next := cur.next[level]
+		if !before(next) {
+			break
+		}
+		cur = next
+	}
+	return cur, level
+}
+
+// moveFingerLocked records where a search ended.
+func (sh *SkipHash[K, V]) moveFingerLocked(node *slNode[K, V]) {
+	if sh.fingers && node != sh.head && sh.finger.Load() != node {
+		sh.finger.Store(node)
+	}
+}
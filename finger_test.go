@@ -0,0 +1,66 @@
+package skiphash
+
+import (
+	"math/rand"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSkipHashFinger(t *testing.T) {
+	sh := New[int, int](WithFinger(), WithRandSource(rand.NewSource(3)))
+	for k := range 2000 {
+		sh.Store(2*k, k)
+	}
+	for k := 0; k < 3900; k++ {
+		want := k + k%2
+		for !sh.Contains(want) {
+			want += 2
+		}
+		e, ok := sh.Ceil(k)
+		assert.True(t, ok)
+		assert.Equal(t, want, e.Key)
+		if k%7 == 0 {
+			sh.Remove(k + 10)
+		}
+	}
+
+	r := rand.New(rand.NewSource(4))
+	for range 1000 {
+		k := r.Intn(4000)
+		floor, ok := sh.Floor(k)
+		want := k - k%2
+		for want >= 0 && !sh.Contains(want) {
+			want -= 2
+		}
+		assert.Equal(t, want >= 0, ok)
+		if ok {
+			assert.Equal(t, want, floor.Key)
+		}
+	}
+}
+
+func TestSkipHashFingerConcurrent(t *testing.T) {
+	sh := New[int, int](WithFinger())
+	for k := range 1000 {
+		sh.Store(k, k)
+	}
+	var wg sync.WaitGroup
+	for w := range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for k := w; k < 1000; k++ {
+				if e, ok := sh.Succ(k); ok {
+					assert.Greater(t, e.Key, k)
+				}
+			}
+		}()
+	}
+	for k := 0; k < 1000; k += 3 {
+		sh.Remove(k)
+	}
+	wg.Wait()
+	assert.Equal(t, 666, sh.Len())
+}
@@ -0,0 +1,116 @@
+package skiphash
+
+// pastValue is a superseded value that was current for reads at versions in
+// (from, to].
+type pastValue[V any] struct {
+	value    V
+	from, to uint64
+}
+
+// WithHistory keeps up to depth superseded values per entry and keeps up to
+// retain removed entries linked, so GetAsOf and RangeAsOf can answer for
+// recent versions. Without it only current values, and removed entries still
+// pinned by a range or snapshot, are visible to AsOf reads.
+func WithHistory(depth, retain int) Option {
+	return func(cfg *config) {
+		if depth > 0 {
+			cfg.historyDepth = depth
+			cfg.historyRetain = max(retain, 0)
+		}
+	}
+}
+
+// CurrentVersion seals the writes completed so far and returns a version
+// that identifies that state: AsOf reads at the returned version see every
+// earlier write and none of the later ones.
+func (sh *SkipHash[K, V]) CurrentVersion() uint64 {
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	sh.rqc.counter++
+	return sh.rqc.counter
+}
+
+// GetAsOf returns the value key had at version, as returned by
+// CurrentVersion or SnapshotView.Version. It reports false if the key was
+// absent then, or if that state has fallen out of the retained history.
+func (sh *SkipHash[K, V]) GetAsOf(key K, version uint64) (V, bool) {
+	sh.unsupportedInFineGrained()
+	key = sh.normalizeKey(key)
+	sh.faultIn(key, key)
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+
+	for node := sh.lowerBoundLocked(key); node != sh.tail && sh.compare(node.key, key) == 0; node = node.next[0] {
+		if value, ok := sh.valueAtLocked(node, version); ok {
+			return value, true
+		}
+	}
+	var zero V
+	return zero, false
+}
+
+// RangeAsOf returns the entries in [low, high] as they were at version, within
+// the limits of the retained history.
+func (sh *SkipHash[K, V]) RangeAsOf(low, high K, version uint64) []Entry[K, V] {
+	sh.unsupportedInFineGrained()
+	low, high = sh.normalizeKey(low), sh.normalizeKey(high)
+	if sh.compare(low, high) > 0 {
+		return nil
+	}
+	sh.faultIn(low, high)
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+
+	entries := make([]Entry[K, V], 0, defaultEntryCap)
+	for node := sh.lowerBoundLocked(low); node != sh.tail && sh.compare(node.key, high) <= 0; node = node.next[0] {
+		if value, ok := sh.valueAtLocked(node, version); ok {
+			entries = append(entries, Entry[K, V]{Key: node.key, Value: value})
+		}
+	}
+	return entries
+}
+
+func (sh *SkipHash[K, V]) valueAtLocked(node *slNode[K, V], ver uint64) (V, bool) {
+	if sh.visibleAtLocked(node, ver) {
+		if node.writtenAt < ver {
+			return *node.value.Load(), true
+		}
+		for i := len(node.history) - 1; i >= 0; i-- {
+			if h := node.history[i]; h.from < ver && ver <= h.to {
+				return h.value, true
+			}
+		}
+	}
+	var zero V
+	return zero, false
+}
+
+// recordHistoryLocked saves node's current value before it is overwritten.
+func (sh *SkipHash[K, V]) recordHistoryLocked(node *slNode[K, V]) {
+	now := sh.rqc.onUpdateLocked()
+	if sh.historyDepth == 0 || node.writtenAt == now {
+		// A value replaced within the same version was never observable.
+		return
+	}
+	if len(node.history) == sh.historyDepth {
+		copy(node.history, node.history[1:])
+		node.history = node.history[:len(node.history)-1]
+	}
+	node.history = append(node.history, pastValue[V]{
+		value: *node.value.Load(),
+		from:  node.writtenAt,
+		to:    now,
+	})
+}
+
+// retainLocked keeps a removed node linked for AsOf reads, handing the
+// oldest retained node to the range coordinator once the budget is full.
+func (sh *SkipHash[K, V]) retainLocked(node *slNode[K, V]) {
+	sh.retained = append(sh.retained, node)
+	for len(sh.retained) > sh.historyRetain {
+		oldest := sh.retained[0]
+		sh.retained[0] = nil
+		sh.retained = sh.retained[1:]
+		sh.rqc.afterRemoveLocked(sh, oldest)
+	}
+}
@@ -0,0 +1,89 @@
+package skiphash
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSkipHashGetAsOf(t *testing.T) {
+	sh := New[string, int](WithHistory(4, 16))
+
+	sh.Insert("a", 1)
+	v1 := sh.CurrentVersion()
+	sh.Store("a", 2)
+	sh.Insert("b", 10)
+	v2 := sh.CurrentVersion()
+	sh.Store("a", 3)
+	sh.Remove("b")
+	v3 := sh.CurrentVersion()
+	sh.Insert("b", 20)
+	v4 := sh.CurrentVersion()
+
+	cases := []struct {
+		key  string
+		ver  uint64
+		want int
+		ok   bool
+	}{
+		{"a", v1, 1, true},
+		{"a", v2, 2, true},
+		{"a", v3, 3, true},
+		{"b", v1, 0, false},
+		{"b", v2, 10, true},
+		{"b", v3, 0, false},
+		{"b", v4, 20, true},
+	}
+	for _, tc := range cases {
+		got, ok := sh.GetAsOf(tc.key, tc.ver)
+		assert.Equal(t, tc.ok, ok, "%s@%d", tc.key, tc.ver)
+		assert.Equal(t, tc.want, got, "%s@%d", tc.key, tc.ver)
+	}
+
+	assert.Equal(t, []Entry[string, int]{{"a", 2}, {"b", 10}}, sh.RangeAsOf("a", "z", v2))
+	assert.Equal(t, []Entry[string, int]{{"a", 3}}, sh.RangeAsOf("a", "z", v3))
+	assert.Equal(t, []Entry[string, int]{{"a", 3}, {"b", 20}}, sh.RangeAsOf("a", "z", v4))
+	assert.Equal(t, 2, sh.Len())
+	assert.Len(t, sh.Range("a", "z"), 2)
+	checkSpans(t, sh)
+}
+
+func TestSkipHashHistoryBounds(t *testing.T) {
+	sh := New[int, int](WithHistory(2, 1))
+	var vers []uint64
+	for i := range 5 {
+		sh.Store(1, i)
+		vers = append(vers, sh.CurrentVersion())
+	}
+	for i, ver := range vers {
+		got, ok := sh.GetAsOf(1, ver)
+		if i < 2 {
+			assert.False(t, ok, "value %d should have been trimmed", i)
+			continue
+		}
+		assert.True(t, ok)
+		assert.Equal(t, i, got)
+	}
+
+	sh.Insert(2, 2)
+	before := sh.CurrentVersion()
+	sh.Remove(2)
+	sh.Insert(3, 3)
+	sh.Remove(3)
+	_, ok := sh.GetAsOf(2, before)
+	assert.False(t, ok, "only one removed entry is retained")
+	assert.Len(t, sh.TombstonesAll(), 1)
+}
+
+func TestSkipHashGetAsOfWithoutHistory(t *testing.T) {
+	sh := New[int, int]()
+	sh.Insert(1, 1)
+	v := sh.CurrentVersion()
+	got, ok := sh.GetAsOf(1, v)
+	assert.True(t, ok)
+	assert.Equal(t, 1, got)
+
+	sh.Store(1, 2)
+	_, ok = sh.GetAsOf(1, v)
+	assert.False(t, ok, "overwritten values are not kept without history")
+}
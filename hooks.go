@@ -0,0 +1,57 @@
+package skiphash
+
+// Hooks are user callbacks invoked after mutations. Nil fields are skipped.
+//
+// Hooks run on the goroutine that made the change, after the SkipHash lock
+// has been released, so they may call back into the SkipHash. The hooks of
+// one call run in the order the changes were made; hooks of concurrent
+// writers may interleave.
+type Hooks[K any, V any] struct {
+	OnInsert func(key K, value V)
+	OnUpdate func(key K, old, value V)
+	OnRemove func(key K, value V)
+	// OnEvict fires instead of OnRemove when an entry is dropped by the
+	// SkipHash itself rather than by a caller.
+	OnEvict func(key K, value V)
+}
+
+// WithHooks registers mutation callbacks. Instances derived from the
+// SkipHash, such as set operation results, do not inherit them.
+func WithHooks[K any, V any](hooks Hooks[K, V]) Option {
+	return func(cfg *config) {
+		cfg.hooks = hooks
+	}
+}
+
+// unlock releases the write lock and then runs the hooks queued by the
+// mutations made under it.
+func (sh *SkipHash[K, V]) unlock() {
+	pending := sh.pendingHooks
+	sh.pendingHooks = nil
+	sh.mu.Unlock()
+
+	for _, c := range pending {
+		sh.hooks.run(c)
+	}
+}
+
+func (h *Hooks[K, V]) run(c change[K, V]) {
+	switch {
+	case c.evicted:
+		if h.OnEvict != nil {
+			h.OnEvict(c.key, c.value)
+		}
+	case c.kind == ChangeInsert:
+		if h.OnInsert != nil {
+			h.OnInsert(c.key, c.value)
+		}
+	case c.kind == ChangeUpdate:
+		if h.OnUpdate != nil {
+			h.OnUpdate(c.key, c.old, c.value)
+		}
+	case c.kind == ChangeRemove:
+		if h.OnRemove != nil {
+			h.OnRemove(c.key, c.value)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package skiphash
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSkipHashHooks(t *testing.T) {
+	var log []string
+	var sh *SkipHash[int, string]
+	sh = New[int, string](WithHooks(Hooks[int, string]{
+		OnInsert: func(key int, value string) {
+			log = append(log, fmt.Sprintf("insert %d=%s", key, value))
+			// Hooks run after the lock is released.
+			assert.True(t, sh.Contains(key))
+		},
+		OnUpdate: func(key int, old, value string) {
+			log = append(log, fmt.Sprintf("update %d %s->%s", key, old, value))
+		},
+		OnRemove: func(key int, value string) {
+			log = append(log, fmt.Sprintf("remove %d=%s", key, value))
+		},
+	}))
+
+	sh.Insert(1, "a")
+	sh.Store(1, "b")
+	sh.Insert(1, "c")
+	sh.Remove(1)
+	sh.Remove(1)
+	_, err := sh.InsertAll([]Entry[int, string]{{3, "x"}, {2, "y"}})
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"insert 1=a",
+		"update 1 a->b",
+		"remove 1=b",
+		"insert 2=y",
+		"insert 3=x",
+	}, log)
+}
+
+func TestSkipHashHooksNotInherited(t *testing.T) {
+	calls := 0
+	s := NewSet[int](WithHooks(Hooks[int, struct{}]{
+		OnInsert: func(int, struct{}) { calls++ },
+	}))
+	s.Add(1)
+	s.Add(2)
+	u := s.Union(NewSet[int]())
+	assert.Equal(t, 2, u.Len())
+	assert.Equal(t, 2, calls)
+}
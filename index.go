@@ -0,0 +1,152 @@
+package skiphash
+
+import (
+	"hash/maphash"
+	"sync/atomic"
+)
+
+// keyIndex is the hash side of the structure: it maps every live key to its
+// node in the skip list. Writes happen under the SkipHash write lock; get
+// may run concurrently with them.
+type keyIndex[K any, V any] interface {
+	get(key K) (*slNode[K, V], bool)
+	// set indexes node under key, replacing the node indexed before.
+	set(key K, node *slNode[K, V])
+	delete(key K)
+	// reserve sizes the index for n more keys ahead of a bulk load.
+	reserve(n int)
+	// empty returns a new, empty index of the same kind.
+	empty() keyIndex[K, V]
+}
+
+const initialIndexBuckets = 16
+
+// syncIndex is a chained hash table that readers walk without a lock. Only
+// node pointers are published: writers link a new entry at the head of its
+// chain, unlink removed entries and swap the node of an existing one with
+// atomic stores, so a write copies nothing that readers may hold. Growing
+// publishes a new table built from fresh entries; readers still on the old
+// one see the contents as of the switch.
+type syncIndex[K any, V any] struct {
+	hash  func(K) uint64
+	equal func(a, b K) bool
+	table atomic.Pointer[indexTable[K, V]]
+	count int
+}
+
+type indexTable[K any, V any] struct {
+	buckets []atomic.Pointer[indexEntry[K, V]]
+}
+
+type indexEntry[K any, V any] struct {
+	hash uint64
+	key  K
+	node atomic.Pointer[slNode[K, V]]
+	next atomic.Pointer[indexEntry[K, V]]
+}
+
+func newSyncIndex[K any, V any](hash func(K) uint64, equal func(a, b K) bool) *syncIndex[K, V] {
+	ix := &syncIndex[K, V]{hash: hash, equal: equal}
+	ix.table.Store(&indexTable[K, V]{buckets: make([]atomic.Pointer[indexEntry[K, V]], initialIndexBuckets)})
+	return ix
+}
+
+// newMapIndex backs SkipHash values whose keys are comparable.
+func newMapIndex[K comparable, V any]() *syncIndex[K, V] {
+	seed := maphash.MakeSeed()
+	return newSyncIndex[K, V](
+		func(key K) uint64 { return maphash.Comparable(seed, key) },
+		func(a, b K) bool { return a == b },
+	)
+}
+
+// newHashIndex backs SkipHash values built by NewFunc. Keys are bucketed by
+// the user hash and resolved within a bucket with the comparator.
+func newHashIndex[K any, V any](hash func(K) uint64, compare func(a, b K) int) *syncIndex[K, V] {
+	return newSyncIndex[K, V](hash, func(a, b K) bool { return compare(a, b) == 0 })
+}
+
+func (t *indexTable[K, V]) bucket(hash uint64) *atomic.Pointer[indexEntry[K, V]] {
+	return &t.buckets[hash&uint64(len(t.buckets)-1)]
+}
+
+func (ix *syncIndex[K, V]) get(key K) (*slNode[K, V], bool) {
+	h := ix.hash(key)
+	for e := ix.table.Load().bucket(h).Load(); e != nil; e = e.next.Load() {
+		if e.hash == h && ix.equal(e.key, key) {
+			return e.node.Load(), true
+		}
+	}
+	return nil, false
+}
+
+func (ix *syncIndex[K, V]) set(key K, node *slNode[K, V]) {
+	h := ix.hash(key)
+	slot := ix.table.Load().bucket(h)
+	for e := slot.Load(); e != nil; e = e.next.Load() {
+		if e.hash == h && ix.equal(e.key, key) {
+			e.node.Store(node)
+			return
+		}
+	}
+	e := &indexEntry[K, V]{hash: h, key: key}
+	e.node.Store(node)
+	e.next.Store(slot.Load())
+	slot.Store(e)
+
+	ix.count++
+	if ix.count > len(ix.table.Load().buckets) {
+		ix.grow()
+	}
+}
+
+// delete unlinks the entry of key. A reader standing on it still follows
+// its next pointer to the rest of the chain.
+func (ix *syncIndex[K, V]) delete(key K) {
+	h := ix.hash(key)
+	link := ix.table.Load().bucket(h)
+	for e := link.Load(); e != nil; link, e = &e.next, e.next.Load() {
+		if e.hash == h && ix.equal(e.key, key) {
+			link.Store(e.next.Load())
+			ix.count--
+			return
+		}
+	}
+}
+
+func (ix *syncIndex[K, V]) reserve(n int) {
+	size := len(ix.table.Load().buckets)
+	for size < ix.count+n {
+		size *= 2
+	}
+	ix.resize(size)
+}
+
+// grow publishes a table with twice the buckets.
+func (ix *syncIndex[K, V]) grow() {
+	ix.resize(2 * len(ix.table.Load().buckets))
+}
+
+// resize publishes a table with size buckets. The entries are copied, as
+// readers may still be walking the old chains.
+func (ix *syncIndex[K, V]) resize(size int) {
+	old := ix.table.Load()
+	if size == len(old.buckets) {
+		return
+	}
+	table := &indexTable[K, V]{buckets: make([]atomic.Pointer[indexEntry[K, V]], size)}
+	for i := range old.buckets {
+		for e := old.buckets[i].Load(); e != nil; e = e.next.Load() {
+			slot := table.bucket(e.hash)
+			moved := &indexEntry[K, V]{hash: e.hash, key: e.key}
+			moved.node.Store(e.node.Load())
+			moved.next.Store(slot.Load())
+			slot.Store(moved)
+		}
+	}
+	ix.table.Store(table)
+}
+
+func (ix *syncIndex[K, V]) empty() keyIndex[K, V] {
+	return newSyncIndex[K, V](ix.hash, ix.equal)
+}
@@ -0,0 +1,197 @@
+package skiphash
+
+import (
+	"bytes"
+	"math/rand"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSkipHashNewFuncTimeKeys(t *testing.T) {
+	sh := NewFunc[time.Time, string](
+		func(a, b time.Time) bool { return a.Before(b) },
+		func(k time.Time) uint64 { return uint64(k.UnixNano()) },
+		WithRandSource(rand.NewSource(13)),
+	)
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, h := range []int{5, 1, 3, 2, 4} {
+		assert.True(t, sh.Insert(base.Add(time.Duration(h)*time.Hour), "v"))
+	}
+	assert.False(t, sh.Insert(base.Add(2*time.Hour), "dup"))
+
+	// The same instant in another location must resolve to the same entry.
+	_, ok := sh.Get(base.Add(3 * time.Hour).In(time.FixedZone("x", 3600)))
+	assert.True(t, ok)
+
+	entries := sh.Range(base.Add(2*time.Hour), base.Add(4*time.Hour))
+	assert.Len(t, entries, 3)
+	assert.True(t, entries[0].Key.Equal(base.Add(2*time.Hour)))
+
+	floor, ok := sh.Floor(base.Add(150 * time.Minute))
+	assert.True(t, ok)
+	assert.True(t, floor.Key.Equal(base.Add(2*time.Hour)))
+	checkSpans(t, sh)
+}
+
+func TestSkipHashNewFuncHashCollisions(t *testing.T) {
+	type point struct{ x, y int }
+	sh := NewFunc[point, int](
+		func(a, b point) bool { return a.x < b.x || a.x == b.x && a.y < b.y },
+		func(point) uint64 { return 42 },
+	)
+	for i := range 10 {
+		assert.True(t, sh.Insert(point{i % 3, i}, i))
+	}
+	for i := range 10 {
+		got, ok := sh.Get(point{i % 3, i})
+		assert.True(t, ok)
+		assert.Equal(t, i, got)
+	}
+	assert.True(t, sh.Remove(point{1, 4}))
+	assert.False(t, sh.Contains(point{1, 4}))
+	assert.True(t, sh.Contains(point{1, 7}))
+	assert.Equal(t, 9, sh.Len())
+
+	first, _ := sh.Select(0)
+	assert.Equal(t, point{0, 0}, first.Key)
+}
+
+func TestSkipHashNewFuncRequiresFuncs(t *testing.T) {
+	assert.Panics(t, func() { NewFunc[int, int](nil, func(int) uint64 { return 0 }) })
+}
+
+func TestSkipHashNewBytes(t *testing.T) {
+	sh := NewBytes[int](WithRandSource(rand.NewSource(14)))
+	for i, k := range []string{"b/2", "a/1", "b/1", "c", "a/2"} {
+		assert.True(t, sh.Insert([]byte(k), i))
+	}
+	assert.False(t, sh.Insert([]byte("c"), 9))
+
+	got, ok := sh.Get([]byte("b/1"))
+	assert.True(t, ok)
+	assert.Equal(t, 2, got)
+
+	var keys []string
+	for _, e := range sh.Range([]byte("a/2"), []byte("b/2")) {
+		keys = append(keys, string(e.Key))
+	}
+	assert.Equal(t, []string{"a/2", "b/1", "b/2"}, keys)
+
+	assert.True(t, sh.Remove([]byte("a/1")))
+	assert.Equal(t, 0, sh.Rank([]byte("a/2")))
+}
+
+func TestSkipHashGetDoesNotTakeLock(t *testing.T) {
+	sh := New[int, string]()
+	for k := range 100 {
+		sh.Store(k, "v")
+	}
+	sh.Store(7, "seven")
+
+	sh.mu.Lock()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		v, ok := sh.Get(7)
+		assert.True(t, ok)
+		assert.Equal(t, "seven", v)
+		assert.True(t, sh.Contains(99))
+		assert.False(t, sh.Contains(100))
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Error("Get blocked on the write lock")
+	}
+	sh.mu.Unlock()
+	<-done
+}
+
+func TestSkipHashGetDuringUpdates(t *testing.T) {
+	sh := New[int, int]()
+	for k := range 64 {
+		sh.Store(k, 0)
+	}
+	// An open snapshot makes updates replace nodes instead of writing in
+	// place; readers must never find the key missing in between.
+	view := sh.Snapshot()
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Go(func() {
+			last := make([]int, 64)
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				for k := range 64 {
+					v, ok := sh.Get(k)
+					if !assert.True(t, ok) || !assert.GreaterOrEqual(t, v, last[k]) {
+						return
+					}
+					last[k] = v
+				}
+			}
+		})
+	}
+	for i := 1; i <= 200; i++ {
+		for k := range 64 {
+			sh.Store(k, i)
+		}
+		if i == 100 {
+			view.Close()
+		}
+	}
+	close(stop)
+	wg.Wait()
+}
+
+func TestSkipHashGetDuringBatchWrites(t *testing.T) {
+	sh := New[int, int]()
+	for k := range 2000 {
+		sh.Store(k, k)
+	}
+	var snapshot bytes.Buffer
+	require.NoError(t, sh.SaveTo(&snapshot))
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Go(func() {
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				if !assert.True(t, sh.Contains(1999)) {
+					return
+				}
+				runtime.Gosched()
+			}
+		})
+	}
+	// Each of these writes several keys; readers see it all or not at all.
+	for range 20 {
+		require.NoError(t, sh.LoadFrom(bytes.NewReader(snapshot.Bytes())))
+		require.NoError(t, sh.Merge(New[int, int](), nil))
+		_, err := sh.InsertAll([]Entry[int, int]{{1999, 1}, {2000, 2}})
+		require.NoError(t, err)
+		sh.TrimBelow(0)
+		require.NoError(t, sh.Txn(func(tx *Txn[int, int]) error {
+			tx.Remove(1999)
+			tx.Store(1999, 1999)
+			return nil
+		}))
+	}
+	close(stop)
+	wg.Wait()
+}
@@ -0,0 +1,108 @@
+package skiphash
+
+import (
+	"cmp"
+	"hash/maphash"
+)
+
+// Interval is the half-open interval [Start, End).
+type Interval[K cmp.Ordered] struct {
+	Start, End K
+}
+
+// IntervalMap maps half-open intervals to values and finds the intervals
+// that contain a point or overlap a range. Intervals are kept in a SkipHash
+// ordered by start, with each level summarizing the largest end below it, so
+// a query skips every run of intervals that ends too early and costs
+// O((k+1) log n) for k results.
+type IntervalMap[K cmp.Ordered, V any] struct {
+	sh     *SkipHash[Interval[K], V]
+	maxEnd *monoid[Interval[K], V, K]
+}
+
+// NewIntervalMap creates an empty IntervalMap.
+func NewIntervalMap[K cmp.Ordered, V any]() *IntervalMap[K, V] {
+	seed := maphash.MakeSeed()
+	sh := NewFunc[Interval[K], V](
+		func(a, b Interval[K]) bool {
+			if c := cmp.Compare(a.Start, b.Start); c != 0 {
+				return c < 0
+			}
+			return cmp.Less(a.End, b.End)
+		},
+		func(iv Interval[K]) uint64 { return maphash.Comparable(seed, iv) },
+		WithAggregate(func(a, b K) K { return max(a, b) }, func(iv Interval[K], _ V) K { return iv.End }),
+	)
+	return &IntervalMap[K, V]{
+		sh:     sh,
+		maxEnd: sh.augs[0].(*monoid[Interval[K], V, K]),
+	}
+}
+
+func (m *IntervalMap[K, V]) Len() int {
+	return m.sh.Len()
+}
+
+// Store maps [start, end) to value and reports whether the interval is new.
+// Empty intervals, where end is not after start, are ignored.
+func (m *IntervalMap[K, V]) Store(start, end K, value V) bool {
+	if !cmp.Less(start, end) {
+		return false
+	}
+	return m.sh.Store(Interval[K]{start, end}, value)
+}
+
+func (m *IntervalMap[K, V]) Get(start, end K) (V, bool) {
+	return m.sh.Get(Interval[K]{start, end})
+}
+
+func (m *IntervalMap[K, V]) Remove(start, end K) bool {
+	return m.sh.Remove(Interval[K]{start, end})
+}
+
+// Stab returns the intervals that contain point, ordered by start.
+func (m *IntervalMap[K, V]) Stab(point K) []Entry[Interval[K], V] {
+	return m.query(point, func(start K) bool { return start <= point })
+}
+
+// Overlapping returns the intervals that share at least one point with
+// [low, high), ordered by start.
+func (m *IntervalMap[K, V]) Overlapping(low, high K) []Entry[Interval[K], V] {
+	if !cmp.Less(low, high) {
+		return nil
+	}
+	return m.query(low, func(start K) bool { return start < high })
+}
+
+// query returns the intervals that end after low and whose start satisfies
+// startOK, which must hold for a prefix of the start order.
+func (m *IntervalMap[K, V]) query(low K, startOK func(K) bool) []Entry[Interval[K], V] {
+	sh := m.sh
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+
+	var out []Entry[Interval[K], V]
+	// visit scans the runs at level from node up to stop and descends into
+	// those holding an interval that ends after low. It returns false once
+	// the starts are past the query.
+	var visit func(node *slNode[Interval[K], V], level int, stop *slNode[Interval[K], V]) bool
+	visit = func(node *slNode[Interval[K], V], level int, stop *slNode[Interval[K], V]) bool {
+		for ; node != stop; node = node.next[level] {
+			if node != sh.head && !startOK(node.key.Start) {
+				return false
+			}
+			cell := node.aug[m.maxEnd.slot].([]aggCell[K])[level]
+			if !cell.ok || cell.value <= low {
+				continue
+			}
+			if level == 0 {
+				out = append(out, Entry[Interval[K], V]{Key: node.key, Value: *node.value.Load()})
+			} else if !visit(node, level-1, node.next[level]) {
+				return false
+			}
+		}
+		return true
+	}
+	visit(sh.head, sh.maxLevel-1, sh.tail)
+	return out
+}
@@ -0,0 +1,76 @@
+package skiphash
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntervalMapMatchesScan(t *testing.T) {
+	m := NewIntervalMap[int, int]()
+	ref := make(map[Interval[int]]int)
+	r := rand.New(rand.NewSource(1))
+	for i := range 2000 {
+		start := r.Intn(1000)
+		end := start + 1 + r.Intn(50)
+		if r.Intn(10) == 0 {
+			end = start + r.Intn(500)
+		}
+		iv := Interval[int]{start, end}
+		if r.Intn(4) == 0 {
+			_, had := ref[iv]
+			assert.Equal(t, had, m.Remove(start, end))
+			delete(ref, iv)
+			continue
+		}
+		_, had := ref[iv]
+		assert.Equal(t, !had && start < end, m.Store(start, end, i))
+		if start < end {
+			ref[iv] = i
+		}
+	}
+	require.Equal(t, len(ref), m.Len())
+
+	scan := func(keep func(Interval[int]) bool) []Entry[Interval[int], int] {
+		var out []Entry[Interval[int], int]
+		for iv, v := range ref {
+			if keep(iv) {
+				out = append(out, Entry[Interval[int], int]{Key: iv, Value: v})
+			}
+		}
+		return out
+	}
+	for range 200 {
+		p := r.Intn(1100) - 50
+		want := scan(func(iv Interval[int]) bool { return iv.Start <= p && p < iv.End })
+		assert.ElementsMatch(t, want, m.Stab(p), "stab %d", p)
+
+		low := r.Intn(1100) - 50
+		high := low + 1 + r.Intn(30)
+		want = scan(func(iv Interval[int]) bool { return iv.Start < high && low < iv.End })
+		got := m.Overlapping(low, high)
+		assert.ElementsMatch(t, want, got, "overlap [%d, %d)", low, high)
+		for i := 1; i < len(got); i++ {
+			assert.LessOrEqual(t, got[i-1].Key.Start, got[i].Key.Start)
+		}
+	}
+}
+
+func TestIntervalMapStab(t *testing.T) {
+	m := NewIntervalMap[string, string]()
+	m.Store("a", "m", "first half")
+	m.Store("m", "z", "second half")
+	m.Store("c", "e", "short")
+	assert.False(t, m.Store("q", "q", "empty"))
+
+	assert.Equal(t, []Entry[Interval[string], string]{
+		{Key: Interval[string]{"a", "m"}, Value: "first half"},
+		{Key: Interval[string]{"c", "e"}, Value: "short"},
+	}, m.Stab("d"))
+	assert.Len(t, m.Stab("m"), 1, "ends are exclusive")
+	assert.Empty(t, m.Stab("z"))
+	assert.Len(t, m.Overlapping("e", "n"), 2)
+	assert.Nil(t, m.Overlapping("n", "e"))
+}
@@ -0,0 +1,75 @@
+package skiphash
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// janitorBatch bounds how many expired entries one janitor pass removes per
+// write-lock acquisition, so sweeping a large backlog never stalls readers
+// for long.
+const janitorBatch = 256
+
+// JanitorStats reports the work done by the janitors started with
+// StartJanitor.
+type JanitorStats struct {
+	Runs      uint64
+	Reclaimed uint64
+	// LastRun is when the last pass finished; zero if none has run.
+	LastRun time.Time
+}
+
+type janitorStats struct {
+	runs      atomic.Uint64
+	reclaimed atomic.Uint64
+	lastRun   atomic.Int64
+}
+
+// StartJanitor removes expired entries every interval until ctx is done.
+// Each pass takes the write lock once per batch of at most janitorBatch
+// entries.
+func (sh *SkipHash[K, V]) StartJanitor(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		panic("skiphash: StartJanitor requires a positive interval")
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				sh.sweepExpired(ctx)
+			}
+		}
+	}()
+}
+
+// JanitorStats returns the counters of the janitors started with
+// StartJanitor.
+func (sh *SkipHash[K, V]) JanitorStats() JanitorStats {
+	stats := JanitorStats{
+		Runs:      sh.janitor.runs.Load(),
+		Reclaimed: sh.janitor.reclaimed.Load(),
+	}
+	if last := sh.janitor.lastRun.Load(); last != 0 {
+		stats.LastRun = time.Unix(0, last)
+	}
+	return stats
+}
+
+func (sh *SkipHash[K, V]) sweepExpired(ctx context.Context) {
+	for ctx.Err() == nil && sh.nextDeadline.Load() != 0 {
+		sh.mu.Lock()
+		n := sh.expireDueLocked(janitorBatch)
+		sh.unlock()
+		sh.janitor.reclaimed.Add(uint64(n))
+		if n < janitorBatch {
+			break
+		}
+	}
+	sh.janitor.runs.Add(1)
+	sh.janitor.lastRun.Store(time.Now().UnixNano())
+}
@@ -0,0 +1,31 @@
+package skiphash
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSkipHashJanitor(t *testing.T) {
+	sh := New[int, int]()
+	for k := range 3 * janitorBatch {
+		sh.InsertTTL(k, k, 100*time.Millisecond)
+	}
+	sh.InsertTTL(-1, -1, time.Hour)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sh.StartJanitor(ctx, 5*time.Millisecond)
+
+	assert.Eventually(t, func() bool {
+		return sh.JanitorStats().Reclaimed == 3*janitorBatch
+	}, 2*time.Second, 5*time.Millisecond)
+
+	stats := sh.JanitorStats()
+	assert.NotZero(t, stats.Runs)
+	assert.False(t, stats.LastRun.IsZero())
+	assert.Equal(t, 1, sh.Len())
+	checkSpans(t, sh)
+}
@@ -0,0 +1,46 @@
+package skiphash
+
+import "encoding/json"
+
+type jsonEntry[K any, V any] struct {
+	Key   K `json:"key"`
+	Value V `json:"value"`
+}
+
+// MarshalJSON encodes the live entries as an array of {"key", "value"}
+// objects in key order.
+func (sh *SkipHash[K, V]) MarshalJSON() ([]byte, error) {
+	entries := sh.RangeAll()
+	out := make([]jsonEntry[K, V], len(entries))
+	for i, e := range entries {
+		out[i] = jsonEntry[K, V](e)
+	}
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON replaces the contents of sh with the entries of a document
+// written by MarshalJSON. The array does not need to be sorted; a repeated
+// key keeps its last value. sh must have been created with New or NewFunc,
+// since the zero value carries no key order.
+func (sh *SkipHash[K, V]) UnmarshalJSON(data []byte) error {
+	if sh.compare == nil {
+		return ErrUninitialized
+	}
+	sh.unsupportedInFineGrained()
+	var in []jsonEntry[K, V]
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	entries := make([]Entry[K, V], len(in))
+	for i, e := range in {
+		entries[i] = Entry[K, V](e)
+	}
+	sorted, err := sh.sortForImport(entries, KeepLast, nil)
+	if err != nil {
+		return err
+	}
+
+	sh.mu.Lock()
+	defer sh.unlock()
+	return sh.replaceAllLocked(sorted)
+}
@@ -0,0 +1,43 @@
+package skiphash
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONRoundTrip(t *testing.T) {
+	sh := New[string, int]()
+	sh.Store("b", 2)
+	sh.Store("a", 1)
+	sh.Store("c", 3)
+
+	data, err := json.Marshal(sh)
+	require.NoError(t, err)
+	require.JSONEq(t, `[{"key":"a","value":1},{"key":"b","value":2},{"key":"c","value":3}]`, string(data))
+
+	out := New[string, int]()
+	out.Store("stale", 9)
+	require.NoError(t, json.Unmarshal(data, out))
+	require.Equal(t, sh.RangeAll(), out.RangeAll())
+	require.Equal(t, 3, out.Len())
+}
+
+func TestJSONUnmarshalUnsortedAndEmbedded(t *testing.T) {
+	var doc struct {
+		Limits *SkipHash[int, string] `json:"limits"`
+	}
+	doc.Limits = New[int, string]()
+	require.NoError(t, json.Unmarshal([]byte(`{"limits":[{"key":3,"value":"c"},{"key":1,"value":"a"},{"key":3,"value":"z"}]}`), &doc))
+	require.Equal(t, []Entry[int, string]{{Key: 1, Value: "a"}, {Key: 3, Value: "z"}}, doc.Limits.RangeAll())
+
+	empty, err := json.Marshal(New[int, int]())
+	require.NoError(t, err)
+	require.Equal(t, "[]", string(empty))
+}
+
+func TestJSONUnmarshalZeroValue(t *testing.T) {
+	var sh SkipHash[int, int]
+	require.ErrorIs(t, json.Unmarshal([]byte(`[]`), &sh), ErrUninitialized)
+}
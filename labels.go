@@ -0,0 +1,155 @@
+package skiphash
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// WithCallerLabels enables per-caller operation metrics for operations made
+// through Scope. See CallerStats.
+func WithCallerLabels() Option {
+	return func(cfg *config) {
+		cfg.callerLabels = true
+	}
+}
+
+type callerLabelKey struct{}
+
+// WithCallerLabel returns a context carrying label, which Scope uses to
+// attribute operations to the calling subsystem.
+func WithCallerLabel(ctx context.Context, label string) context.Context {
+	return context.WithValue(ctx, callerLabelKey{}, label)
+}
+
+// CallerLabel returns the label carried by ctx, or "" if there is none.
+func CallerLabel(ctx context.Context) string {
+	label, _ := ctx.Value(callerLabelKey{}).(string)
+	return label
+}
+
+// OpStats counts calls of one operation and their total latency.
+type OpStats struct {
+	Count   uint64
+	Latency time.Duration
+}
+
+// CallerStats breaks operation metrics down for one caller label.
+// SlowRanges counts the ranges that fell back to the versioned slow path.
+type CallerStats struct {
+	Get, Store, Remove, Range OpStats
+	SlowRanges                uint64
+}
+
+type callerMetrics struct {
+	mu      sync.RWMutex
+	byLabel map[string]*callerCounters
+}
+
+type opCounters struct {
+	count atomic.Uint64
+	nanos atomic.Int64
+}
+
+type callerCounters struct {
+	get, store, remove, rng opCounters
+	slowRanges              atomic.Uint64
+}
+
+func (m *callerMetrics) counters(label string) *callerCounters {
+	m.mu.RLock()
+	c, ok := m.byLabel[label]
+	m.mu.RUnlock()
+	if ok {
+		return c
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if c, ok = m.byLabel[label]; !ok {
+		c = &callerCounters{}
+		m.byLabel[label] = c
+	}
+	return c
+}
+
+func (c *opCounters) observe(start time.Time) {
+	c.count.Add(1)
+	c.nanos.Add(int64(time.Since(start)))
+}
+
+func (c *opCounters) snapshot() OpStats {
+	return OpStats{Count: c.count.Load(), Latency: time.Duration(c.nanos.Load())}
+}
+
+// CallerStats returns the metrics recorded per caller label. It returns nil
+// unless WithCallerLabels is set.
+func (sh *SkipHash[K, V]) CallerStats() map[string]CallerStats {
+	if sh.callers == nil {
+		return nil
+	}
+	sh.callers.mu.RLock()
+	defer sh.callers.mu.RUnlock()
+	out := make(map[string]CallerStats, len(sh.callers.byLabel))
+	for label, c := range sh.callers.byLabel {
+		out[label] = CallerStats{
+			Get:        c.get.snapshot(),
+			Store:      c.store.snapshot(),
+			Remove:     c.remove.snapshot(),
+			Range:      c.rng.snapshot(),
+			SlowRanges: c.slowRanges.Load(),
+		}
+	}
+	return out
+}
+
+// Scope is a view of a SkipHash that attributes its operations to the caller
+// label of a context.
+type Scope[K any, V any] struct {
+	sh       *SkipHash[K, V]
+	counters *callerCounters
+}
+
+// Scope returns a view recording metrics under CallerLabel(ctx). Without
+// WithCallerLabels it records nothing.
+func (sh *SkipHash[K, V]) Scope(ctx context.Context) Scope[K, V] {
+	s := Scope[K, V]{sh: sh}
+	if sh.callers != nil {
+		s.counters = sh.callers.counters(CallerLabel(ctx))
+	}
+	return s
+}
+
+func (s Scope[K, V]) Get(key K) (V, bool) {
+	if s.counters != nil {
+		defer s.counters.get.observe(time.Now())
+	}
+	return s.sh.Get(key)
+}
+
+func (s Scope[K, V]) Store(key K, value V) bool {
+	if s.counters != nil {
+		defer s.counters.store.observe(time.Now())
+	}
+	return s.sh.Store(key, value)
+}
+
+func (s Scope[K, V]) Remove(key K) bool {
+	if s.counters != nil {
+		defer s.counters.remove.observe(time.Now())
+	}
+	return s.sh.Remove(key)
+}
+
+func (s Scope[K, V]) Range(low, high K) []Entry[K, V] {
+	if s.counters == nil {
+		return s.sh.Range(low, high)
+	}
+	defer s.counters.rng.observe(time.Now())
+	entries, slow := s.sh.rangeReportingPath(nil, low, high)
+	if slow {
+		s.counters.slowRanges.Add(1)
+	}
+	return entries
+}
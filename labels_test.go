@@ -0,0 +1,36 @@
+package skiphash
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSkipHashCallerStats(t *testing.T) {
+	sh := New[int, int](WithCallerLabels())
+	ingest := sh.Scope(WithCallerLabel(context.Background(), "ingest"))
+	api := sh.Scope(WithCallerLabel(context.Background(), "api"))
+
+	for k := range 5 {
+		ingest.Store(k, k)
+	}
+	ingest.Remove(4)
+	api.Get(1)
+	api.Range(0, 3)
+	sh.Scope(context.Background()).Get(2)
+
+	stats := sh.CallerStats()
+	assert.Len(t, stats, 3)
+	assert.Equal(t, uint64(5), stats["ingest"].Store.Count)
+	assert.Equal(t, uint64(1), stats["ingest"].Remove.Count)
+	assert.Zero(t, stats["ingest"].Get.Count)
+	assert.Equal(t, uint64(1), stats["api"].Get.Count)
+	assert.Equal(t, uint64(1), stats["api"].Range.Count)
+	assert.Equal(t, uint64(1), stats[""].Get.Count)
+
+	plain := New[int, int]()
+	plain.Scope(WithCallerLabel(context.Background(), "api")).Store(1, 1)
+	assert.Nil(t, plain.CallerStats())
+	assert.Equal(t, 1, plain.Len())
+}
@@ -0,0 +1,318 @@
+package skiphash
+
+import (
+	"cmp"
+	"math/bits"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+)
+
+// LockFree is an ordered map built on a lock-free skip list: links are
+// swapped with CAS on markable references, following Herlihy and Shavit, and
+// point lookups go through a concurrent hash index. No operation takes a
+// lock. A lookup that misses the index walks the list instead, as the index
+// learns of an insert only after it takes effect. Range is weakly
+// consistent: it sees every entry that stays present for the whole scan,
+// and may or may not see entries changed during it.
+//
+// LockFree covers the core map operations only; options other than
+// WithMaxLevel and WithDescending are ignored.
+type LockFree[K comparable, V any] struct {
+	maxLevel int
+	compare  func(a, b K) int
+	head     *lfNode[K, V]
+	// deleted is stored as the value of logically removed nodes; swapping
+	// it in is the linearization point of Remove.
+	deleted *V
+	index   sync.Map // K -> *lfNode[K, V]
+	len     atomic.Int64
+}
+
+// lfRef is an immutable (successor, marked) pair. A marked reference in
+// next[i] means its node is being unlinked at level i. A nil successor is
+// the end of the list.
+type lfRef[K comparable, V any] struct {
+	node   *lfNode[K, V]
+	marked bool
+}
+
+type lfNode[K comparable, V any] struct {
+	key   K
+	value atomic.Pointer[V]
+	next  []atomic.Pointer[lfRef[K, V]]
+}
+
+// NewLockFree creates an empty LockFree map.
+func NewLockFree[K cmp.Ordered, V any](opts ...Option) *LockFree[K, V] {
+	cfg := config{maxLevel: DefaultMaxLevel}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&cfg)
+		}
+	}
+	if cfg.maxLevel <= 0 {
+		cfg.maxLevel = DefaultMaxLevel
+	}
+	compare := cmp.Compare[K]
+	if cfg.descending {
+		compare = func(a, b K) int { return cmp.Compare(b, a) }
+	}
+	return &LockFree[K, V]{
+		maxLevel: cfg.maxLevel,
+		compare:  compare,
+		head:     newLFNode[K, V](*new(K), cfg.maxLevel),
+		deleted:  new(V),
+	}
+}
+
+func newLFNode[K comparable, V any](key K, height int) *lfNode[K, V] {
+	node := &lfNode[K, V]{key: key, next: make([]atomic.Pointer[lfRef[K, V]], height)}
+	for i := range node.next {
+		node.next[i].Store(&lfRef[K, V]{})
+	}
+	return node
+}
+
+func (m *LockFree[K, V]) Len() int {
+	return int(m.len.Load())
+}
+
+func (m *LockFree[K, V]) Get(key K) (V, bool) {
+	if n, ok := m.index.Load(key); ok {
+		if v := n.(*lfNode[K, V]).value.Load(); v != m.deleted {
+			return *v, true
+		}
+	}
+	// A node is published to the index only after it is linked, so a miss
+	// may be an insert that has already taken effect.
+	if node := m.search(key); node != nil {
+		if v := node.value.Load(); v != m.deleted {
+			return *v, true
+		}
+	}
+	var zero V
+	return zero, false
+}
+
+func (m *LockFree[K, V]) Contains(key K) bool {
+	_, ok := m.Get(key)
+	return ok
+}
+
+// Insert adds key and fails if it is already present.
+func (m *LockFree[K, V]) Insert(key K, value V) bool {
+	return m.put(key, value, false)
+}
+
+// Store inserts or replaces the value for key and reports whether key was
+// inserted.
+func (m *LockFree[K, V]) Store(key K, value V) bool {
+	return m.put(key, value, true)
+}
+
+func (m *LockFree[K, V]) put(key K, value V, overwrite bool) bool {
+	preds := make([]*lfNode[K, V], m.maxLevel)
+	succs := make([]*lfNode[K, V], m.maxLevel)
+	height := m.randomLevel()
+	for {
+		if m.find(key, preds, succs) {
+			node := succs[0]
+			old := node.value.Load()
+			if old == m.deleted {
+				// A remover is unlinking it; help, so the retry finds
+				// the gap rather than the same node.
+				m.mark(node)
+				m.find(key, preds, succs)
+				continue
+			}
+			if !overwrite {
+				return false
+			}
+			if node.value.CompareAndSwap(old, &value) {
+				return false
+			}
+			continue
+		}
+
+		node := newLFNode[K, V](key, height)
+		node.value.Store(&value)
+		for i := range height {
+			node.next[i].Store(&lfRef[K, V]{node: succs[i]})
+		}
+		if !m.casNext(preds[0], 0, succs[0], node) {
+			continue
+		}
+		m.len.Add(1)
+		m.linkUpper(node, preds, succs)
+		m.publish(node)
+		return true
+	}
+}
+
+// linkUpper links node above the base level, stopping early if it is
+// removed meanwhile.
+func (m *LockFree[K, V]) linkUpper(node *lfNode[K, V], preds, succs []*lfNode[K, V]) {
+	for i := 1; i < len(node.next); i++ {
+		for {
+			ref := node.next[i].Load()
+			if ref.marked {
+				return
+			}
+			if ref.node != succs[i] && !node.next[i].CompareAndSwap(ref, &lfRef[K, V]{node: succs[i]}) {
+				continue
+			}
+			if m.casNext(preds[i], i, succs[i], node) {
+				break
+			}
+			if !m.find(node.key, preds, succs) || succs[0] != node {
+				return
+			}
+		}
+	}
+}
+
+// publish points the index at node unless a newer live node for the same
+// key already took its place, which can only happen once node is removed.
+func (m *LockFree[K, V]) publish(node *lfNode[K, V]) {
+	for {
+		cur, ok := m.index.Load(node.key)
+		if !ok {
+			if _, loaded := m.index.LoadOrStore(node.key, node); !loaded {
+				return
+			}
+			continue
+		}
+		other := cur.(*lfNode[K, V])
+		if other == node || other.value.Load() != m.deleted {
+			return
+		}
+		if m.index.CompareAndSwap(node.key, other, node) {
+			return
+		}
+	}
+}
+
+func (m *LockFree[K, V]) Remove(key K) bool {
+	preds := make([]*lfNode[K, V], m.maxLevel)
+	succs := make([]*lfNode[K, V], m.maxLevel)
+	if !m.find(key, preds, succs) {
+		return false
+	}
+	node := succs[0]
+	for {
+		old := node.value.Load()
+		if old == m.deleted {
+			return false
+		}
+		if node.value.CompareAndSwap(old, m.deleted) {
+			break
+		}
+	}
+	m.len.Add(-1)
+	m.mark(node)
+	m.index.CompareAndDelete(key, node)
+	m.find(key, preds, succs) // unlinks the marked node
+	return true
+}
+
+// mark marks every level of a removed node, top down, so find unlinks it.
+// Both the remover and a put that runs into the node call it.
+func (m *LockFree[K, V]) mark(node *lfNode[K, V]) {
+	for i := len(node.next) - 1; i >= 0; i-- {
+		for {
+			ref := node.next[i].Load()
+			if ref.marked || node.next[i].CompareAndSwap(ref, &lfRef[K, V]{node: ref.node, marked: true}) {
+				break
+			}
+		}
+	}
+}
+
+// Range returns the live entries in [low, high], in order.
+func (m *LockFree[K, V]) Range(low, high K) []Entry[K, V] {
+	if m.compare(low, high) > 0 {
+		return nil
+	}
+	preds := make([]*lfNode[K, V], m.maxLevel)
+	succs := make([]*lfNode[K, V], m.maxLevel)
+	m.find(low, preds, succs)
+
+	entries := make([]Entry[K, V], 0, defaultEntryCap)
+	for node := succs[0]; node != nil && m.compare(node.key, high) <= 0; {
+		ref := node.next[0].Load()
+		if v := node.value.Load(); !ref.marked && v != m.deleted {
+			entries = append(entries, Entry[K, V]{Key: node.key, Value: *v})
+		}
+		node = ref.node
+	}
+	return entries
+}
+
+// find fills preds and succs with the neighbors of key at every level,
+// unlinking marked nodes on the way, and reports whether succs[0] holds key.
+func (m *LockFree[K, V]) find(key K, preds, succs []*lfNode[K, V]) bool {
+retry:
+	for {
+		pred := m.head
+		for level := m.maxLevel - 1; level >= 0; level-- {
+			curr := pred.next[level].Load().node
+			for curr != nil {
+				ref := curr.next[level].Load()
+				if ref.marked {
+					if !m.casNext(pred, level, curr, ref.node) {
+						continue retry
+					}
+					curr = ref.node
+					continue
+				}
+				if m.compare(curr.key, key) >= 0 {
+					break
+				}
+				pred, curr = curr, ref.node
+			}
+			preds[level] = pred
+			succs[level] = curr
+		}
+		return succs[0] != nil && m.compare(succs[0].key, key) == 0
+	}
+}
+
+// search returns the node holding key, if one is linked, walking past
+// marked nodes without unlinking them as find does.
+func (m *LockFree[K, V]) search(key K) *lfNode[K, V] {
+	pred := m.head
+	var curr *lfNode[K, V]
+	for level := m.maxLevel - 1; level >= 0; level-- {
+		curr = pred.next[level].Load().node
+		for curr != nil {
+			ref := curr.next[level].Load()
+			if ref.marked {
+				curr = ref.node
+				continue
+			}
+			if m.compare(curr.key, key) >= 0 {
+				break
+			}
+			pred, curr = curr, ref.node
+		}
+	}
+	if curr != nil && m.compare(curr.key, key) == 0 {
+		return curr
+	}
+	return nil
+}
+
+// casNext swings pred.next[level] from an unmarked reference to expect to
+// one to next.
+func (m *LockFree[K, V]) casNext(pred *lfNode[K, V], level int, expect, next *lfNode[K, V]) bool {
+	ref := pred.next[level].Load()
+	if ref.node != expect || ref.marked {
+		return false
+	}
+	return pred.next[level].CompareAndSwap(ref, &lfRef[K, V]{node: next})
+}
+
+func (m *LockFree[K, V]) randomLevel() int {
+	return min(bits.TrailingZeros64(rand.Uint64())+1, m.maxLevel)
+}
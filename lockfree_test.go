@@ -0,0 +1,124 @@
+package skiphash
+
+import (
+	"math/rand"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLockFreeMatchesSkipHash(t *testing.T) {
+	lf := NewLockFree[int, int]()
+	ref := New[int, int]()
+	r := rand.New(rand.NewSource(3))
+	for range 5000 {
+		k := r.Intn(500)
+		switch r.Intn(3) {
+		case 0:
+			assert.Equal(t, ref.Remove(k), lf.Remove(k))
+		case 1:
+			assert.Equal(t, ref.Insert(k, k), lf.Insert(k, k))
+		default:
+			assert.Equal(t, ref.Store(k, -k), lf.Store(k, -k))
+		}
+	}
+	assert.Equal(t, ref.Len(), lf.Len())
+	assert.Equal(t, ref.Range(0, 499), lf.Range(0, 499))
+	assert.Equal(t, ref.Range(100, 200), lf.Range(100, 200))
+	assert.Nil(t, lf.Range(5, 1))
+	for k := range 500 {
+		want, wantOK := ref.Get(k)
+		got, ok := lf.Get(k)
+		assert.Equal(t, wantOK, ok)
+		assert.Equal(t, want, got)
+	}
+
+	desc := NewLockFree[int, int](WithDescending())
+	for k := range 5 {
+		desc.Store(k, k)
+	}
+	assert.Equal(t, []Entry[int, int]{{3, 3}, {2, 2}, {1, 1}}, desc.Range(3, 1))
+}
+
+func TestLockFreeConcurrent(t *testing.T) {
+	lf := NewLockFree[int, int]()
+	const workers, keys = 8, 256
+	var wg sync.WaitGroup
+	for w := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r := rand.New(rand.NewSource(int64(w)))
+			for range 5000 {
+				k := r.Intn(keys)
+				switch r.Intn(4) {
+				case 0:
+					lf.Remove(k)
+				case 1:
+					lf.Insert(k, k)
+				case 2:
+					lf.Store(k, k)
+				default:
+					if v, ok := lf.Get(k); ok {
+						assert.Equal(t, k, v)
+					}
+					lf.Range(k, k+16)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	entries := lf.Range(0, keys)
+	assert.Equal(t, len(entries), lf.Len())
+	for i, e := range entries {
+		if i > 0 {
+			assert.Less(t, entries[i-1].Key, e.Key)
+		}
+		v, ok := lf.Get(e.Key)
+		assert.True(t, ok)
+		assert.Equal(t, e.Key, v)
+	}
+}
+
+func TestLockFreeGetSeesLinkedInserts(t *testing.T) {
+	lf := NewLockFree[int, int]()
+	const keys = 20000
+	var wg sync.WaitGroup
+	wg.Go(func() {
+		for k := range keys {
+			lf.Insert(k, k)
+		}
+	})
+	// Nothing is removed, so a key a scan has seen must stay visible to Get.
+	wg.Go(func() {
+		for k := 0; k < keys; {
+			if len(lf.Range(k, k)) == 0 {
+				continue
+			}
+			_, ok := lf.Get(k)
+			assert.True(t, ok, k)
+			k++
+		}
+	})
+	wg.Wait()
+}
+
+func TestLockFreeStoreHelpsStalledRemove(t *testing.T) {
+	lf := NewLockFree[int, int]()
+	for k := range 10 {
+		lf.Insert(k, k)
+	}
+	// A remover that has taken the value but stalled before unlinking the
+	// node must not hold up a Store of the same key.
+	node, ok := lf.index.Load(5)
+	assert.True(t, ok)
+	node.(*lfNode[int, int]).value.Store(lf.deleted)
+
+	assert.True(t, lf.Store(5, 50))
+	v, ok := lf.Get(5)
+	assert.True(t, ok)
+	assert.Equal(t, 50, v)
+	assert.Equal(t, []int{4, 5, 6}, keysOf(lf.Range(4, 6)))
+}
@@ -0,0 +1,151 @@
+package skiphash
+
+import (
+	"cmp"
+	"time"
+)
+
+// LWWRecord is one entry of an LWWMap as exchanged between replicas. Time
+// (UnixNano) and Actor stamp the write; of two writes to a key the one with
+// the later Time wins, and the greater Actor breaks ties. Deleted marks a
+// tombstone, whose Value is unused.
+type LWWRecord[K any, V any] struct {
+	Key     K
+	Value   V
+	Time    int64
+	Actor   string
+	Deleted bool
+}
+
+// newer reports whether r's write wins over o's.
+func (r LWWRecord[K, V]) newer(o LWWRecord[K, V]) bool {
+	if r.Time != o.Time {
+		return r.Time > o.Time
+	}
+	return r.Actor > o.Actor
+}
+
+// LWWMap is a last-writer-wins map for replicas that exchange their records
+// with Merge and converge to the same contents whatever the order of
+// merges. Removals leave tombstones so that they win over older writes
+// arriving later; a tombstone is dropped once its write is older than the
+// window given to NewLWWMap, after which an older write may resurrect the
+// key.
+type LWWMap[K cmp.Ordered, V any] struct {
+	sh     *SkipHash[K, LWWRecord[K, V]]
+	actor  string
+	window time.Duration
+	// live counts the entries that are not tombstones; it is guarded by
+	// the SkipHash lock.
+	live int
+}
+
+// NewLWWMap creates an empty LWWMap whose own writes are stamped with actor,
+// which must differ between replicas.
+func NewLWWMap[K cmp.Ordered, V any](actor string, tombstoneWindow time.Duration) *LWWMap[K, V] {
+	return &LWWMap[K, V]{sh: New[K, LWWRecord[K, V]](), actor: actor, window: tombstoneWindow}
+}
+
+// Len returns the number of keys that are not removed.
+func (m *LWWMap[K, V]) Len() int {
+	m.sh.mu.RLock()
+	defer m.sh.mu.RUnlock()
+	return m.live
+}
+
+func (m *LWWMap[K, V]) Get(key K) (V, bool) {
+	rec, ok := m.sh.Get(key)
+	if !ok || rec.Deleted {
+		var zero V
+		return zero, false
+	}
+	return rec.Value, true
+}
+
+// Store writes value under key, stamped to win over every write to key this
+// replica has seen.
+func (m *LWWMap[K, V]) Store(key K, value V) {
+	m.apply(LWWRecord[K, V]{Key: key, Value: value}, true)
+}
+
+// Remove writes a tombstone for key.
+func (m *LWWMap[K, V]) Remove(key K) {
+	m.apply(LWWRecord[K, V]{Key: key, Deleted: true}, true)
+}
+
+// Range returns the keys in [low, high] that are not removed.
+func (m *LWWMap[K, V]) Range(low, high K) []Entry[K, V] {
+	var out []Entry[K, V]
+	for _, e := range m.sh.Range(low, high) {
+		if !e.Value.Deleted {
+			out = append(out, Entry[K, V]{Key: e.Key, Value: e.Value.Value})
+		}
+	}
+	return out
+}
+
+// Records returns every record, tombstones included, in key order, for
+// MergeRecords on another replica.
+func (m *LWWMap[K, V]) Records() []LWWRecord[K, V] {
+	entries := m.sh.RangeAll()
+	out := make([]LWWRecord[K, V], len(entries))
+	for i, e := range entries {
+		out[i] = e.Value
+	}
+	return out
+}
+
+// Merge folds in the records of other; see MergeRecords.
+func (m *LWWMap[K, V]) Merge(other *LWWMap[K, V]) {
+	m.MergeRecords(other.Records())
+}
+
+// MergeRecords keeps, for every key, whichever of the local and the given
+// record wins. Merging is commutative, associative and idempotent.
+func (m *LWWMap[K, V]) MergeRecords(records []LWWRecord[K, V]) {
+	for _, rec := range records {
+		m.apply(rec, false)
+	}
+}
+
+// apply writes rec unless the record held for its key wins. A local write
+// is first stamped to win.
+func (m *LWWMap[K, V]) apply(rec LWWRecord[K, V], local bool) {
+	sh := m.sh
+	key := rec.Key
+	sh.mu.Lock()
+	defer sh.unlock()
+	sh.faultInLocked(key, key)
+
+	node, exists := sh.index.get(key)
+	if local {
+		rec.Time, rec.Actor = time.Now().UnixNano(), m.actor
+		if exists && !rec.newer(*node.value.Load()) {
+			rec.Time = node.value.Load().Time + 1
+		}
+	} else if exists && !rec.newer(*node.value.Load()) {
+		return
+	}
+	if exists && !node.value.Load().Deleted {
+		m.live--
+	}
+
+	expires := time.Unix(0, rec.Time).Add(m.window)
+	if rec.Deleted && !time.Now().Before(expires) {
+		// The tombstone would already be gone; only its removal matters.
+		if exists {
+			sh.removeLocked(node)
+		}
+		return
+	}
+	if exists {
+		_ = sh.updateLocked(node, rec)
+	} else {
+		_ = sh.insertLocked(key, rec)
+	}
+	if rec.Deleted {
+		sh.scheduleLocked(key, expires)
+	} else {
+		m.live++
+	}
+}
@@ -0,0 +1,94 @@
+package skiphash
+
+import (
+	"math/rand"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLWWMap(t *testing.T) {
+	east := NewLWWMap[string, int]("east", time.Hour)
+	west := NewLWWMap[string, int]("west", time.Hour)
+
+	east.Store("a", 1)
+	west.Store("b", 2)
+	west.Store("a", 3) // later than east's write
+	east.Remove("b")   // later than west's write
+	east.Store("c", 4)
+
+	east.Merge(west)
+	west.Merge(east)
+	assert.Equal(t, []Entry[string, int]{{"a", 3}, {"c", 4}}, east.Range("", "z"))
+	assert.Equal(t, east.Records(), west.Records())
+	assert.Equal(t, 2, east.Len())
+	assert.Equal(t, 2, west.Len())
+	_, ok := west.Get("b")
+	assert.False(t, ok)
+
+	// A write made after seeing a record always wins over it, even with a
+	// clock that lags behind.
+	ahead := LWWRecord[string, int]{Key: "a", Value: 9, Time: time.Now().Add(time.Minute).UnixNano(), Actor: "north"}
+	east.MergeRecords([]LWWRecord[string, int]{ahead})
+	v, _ := east.Get("a")
+	assert.Equal(t, 9, v)
+	east.Store("a", 10)
+	v, _ = east.Get("a")
+	assert.Equal(t, 10, v)
+}
+
+func TestLWWMapTombstoneWindow(t *testing.T) {
+	m := NewLWWMap[string, int]("a", 20*time.Millisecond)
+	m.Store("k", 1)
+	old := m.Records()
+	m.Remove("k")
+
+	m.MergeRecords(old)
+	_, ok := m.Get("k")
+	assert.False(t, ok, "the tombstone beats the older write")
+	assert.Len(t, m.Records(), 1)
+
+	time.Sleep(40 * time.Millisecond)
+	assert.Empty(t, m.Records(), "the tombstone expired")
+	m.MergeRecords(old)
+	_, ok = m.Get("k")
+	assert.True(t, ok, "an older write resurrects the key after the window")
+
+	expired := LWWRecord[string, int]{Key: "k", Time: time.Now().Add(-time.Hour).UnixNano(), Actor: "z", Deleted: true}
+	m.Store("x", 1)
+	m.MergeRecords([]LWWRecord[string, int]{expired})
+	assert.Equal(t, 2, m.Len(), "an expired tombstone loses to newer writes")
+}
+
+func TestLWWMapConverges(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	replicas := make([]*LWWMap[int, string], 3)
+	for i := range replicas {
+		replicas[i] = NewLWWMap[int, string](strconv.Itoa(i), time.Hour)
+	}
+	for range 500 {
+		m := replicas[r.Intn(len(replicas))]
+		key := r.Intn(40)
+		switch r.Intn(4) {
+		case 0:
+			m.Remove(key)
+		case 1:
+			m.Merge(replicas[r.Intn(len(replicas))])
+		default:
+			m.Store(key, strconv.Itoa(r.Int()))
+		}
+	}
+	for range 2 {
+		for _, a := range replicas {
+			for _, b := range replicas {
+				a.Merge(b)
+			}
+		}
+	}
+	for _, m := range replicas[1:] {
+		assert.Equal(t, replicas[0].Records(), m.Records())
+		assert.Equal(t, replicas[0].Len(), m.Len())
+	}
+}
@@ -0,0 +1,70 @@
+package skiphash
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+// Merge folds the live entries of other into sh. Keys only in other are
+// inserted; for keys in both, the value becomes resolve(key, mine, theirs),
+// or theirs when resolve is nil. Both base levels are walked once in key
+// order with other read-locked and sh write-locked, and readers of sh see
+// the merge all at once. Both must use the same ordering. A quota or weight
+// rejection stops the merge at that key, leaving the keys before it merged,
+// and is returned.
+func (sh *SkipHash[K, V]) Merge(other *SkipHash[K, V], resolve func(key K, a, b V) V) error {
+	sh.unsupportedInFineGrained()
+	other.unsupportedInFineGrained()
+	if sh == other {
+		return nil
+	}
+	sh.faultInAll()
+	other.faultInAll()
+
+	if uintptr(unsafe.Pointer(sh)) < uintptr(unsafe.Pointer(other)) {
+		sh.mu.Lock()
+		other.mu.RLock()
+	} else {
+		other.mu.RLock()
+		sh.mu.Lock()
+	}
+	defer sh.unlock()
+
+	var updates, inserts []Entry[K, V]
+	mergeWalkLocked(sh, other, func(mine, theirs *slNode[K, V]) {
+		switch {
+		case theirs == nil:
+		case mine == nil:
+			inserts = append(inserts, Entry[K, V]{Key: theirs.key, Value: *theirs.value.Load()})
+		case resolve != nil:
+			updates = append(updates, Entry[K, V]{Key: mine.key, Value: resolve(mine.key, *mine.value.Load(), *theirs.value.Load())})
+		default:
+			updates = append(updates, Entry[K, V]{Key: mine.key, Value: *theirs.value.Load()})
+		}
+	})
+	other.mu.RUnlock()
+
+	// Entries are applied only after the walk so that evictions triggered
+	// by the writes cannot disturb it.
+	sh.beginBatchLocked()
+	defer sh.endBatchLocked()
+	for _, e := range updates {
+		if node, ok := sh.index.get(e.Key); ok {
+			if err := sh.updateLocked(node, e.Value); err != nil {
+				return fmt.Errorf("key %v: %w", e.Key, err)
+			}
+		}
+	}
+	if sh.len == 0 && len(updates) == 0 {
+		return sh.loadSortedLocked(inserts)
+	}
+	for _, e := range inserts {
+		if _, ok := sh.index.get(e.Key); ok {
+			continue
+		}
+		if err := sh.insertLocked(e.Key, e.Value); err != nil {
+			return fmt.Errorf("key %v: %w", e.Key, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package skiphash
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMerge(t *testing.T) {
+	a := New[string, int]()
+	a.Store("a", 1)
+	a.Store("b", 2)
+	b := New[string, int]()
+	b.Store("b", 20)
+	b.Store("c", 30)
+
+	require.NoError(t, a.Merge(b, func(_ string, x, y int) int { return x + y }))
+	require.Equal(t, []Entry[string, int]{{Key: "a", Value: 1}, {Key: "b", Value: 22}, {Key: "c", Value: 30}}, a.RangeAll())
+	require.Equal(t, 2, b.Len(), "other is left untouched")
+	checkSpans(t, a)
+
+	require.NoError(t, a.Merge(b, nil))
+	v, _ := a.Get("b")
+	require.Equal(t, 20, v)
+
+	empty := New[string, int]()
+	require.NoError(t, empty.Merge(b, nil))
+	require.Equal(t, b.RangeAll(), empty.RangeAll())
+	checkSpans(t, empty)
+
+	require.NoError(t, a.Merge(a, nil))
+}
+
+func TestMergeBothDirectionsConcurrently(t *testing.T) {
+	a, b := New[int, int](), New[int, int]()
+	for i := range 100 {
+		a.Store(i, i)
+		b.Store(i+50, i)
+	}
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(2)
+		go func() { defer wg.Done(); require.NoError(t, a.Merge(b, nil)) }()
+		go func() { defer wg.Done(); require.NoError(t, b.Merge(a, nil)) }()
+	}
+	wg.Wait()
+	require.Equal(t, 150, a.Len())
+	require.Equal(t, 150, b.Len())
+}
@@ -0,0 +1,53 @@
+package skiphash
+
+import "time"
+
+// WithEntryTimestamps records the wall-clock time of each entry's insert and
+// last write for GetEntryMeta. It costs a clock read per write.
+func WithEntryTimestamps() Option {
+	return func(cfg *config) {
+		cfg.timestamps = true
+	}
+}
+
+// EntryMeta describes the writes that produced an entry. Versions are write
+// versions as returned by WriteVersion.
+type EntryMeta struct {
+	InsertVersion uint64
+	// Version is the write version of the last insert or update.
+	Version uint64
+	// InsertedAt and UpdatedAt are zero unless the SkipHash was created
+	// with WithEntryTimestamps.
+	InsertedAt time.Time
+	UpdatedAt  time.Time
+	// ExpiresAt is the TTL deadline, or zero if the entry does not expire.
+	ExpiresAt time.Time
+}
+
+// GetEntryMeta returns the metadata of key, or false if key is absent.
+func (sh *SkipHash[K, V]) GetEntryMeta(key K) (EntryMeta, bool) {
+	sh.unsupportedInFineGrained()
+	key = sh.normalizeKey(key)
+	sh.faultIn(key, key)
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+	node, ok := sh.index.get(key)
+	if !ok {
+		return EntryMeta{}, false
+	}
+	meta := EntryMeta{
+		InsertVersion: node.created,
+		Version:       node.version,
+		InsertedAt:    unixTime(node.insertedAt),
+		UpdatedAt:     unixTime(node.updatedAt),
+		ExpiresAt:     unixTime(node.expiry.at),
+	}
+	return meta, true
+}
+
+func unixTime(nanos int64) time.Time {
+	if nanos == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nanos)
+}
@@ -0,0 +1,50 @@
+package skiphash
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetEntryMeta(t *testing.T) {
+	sh := New[string, int](WithEntryTimestamps())
+	before := time.Now()
+	sh.Store("a", 1)
+	sh.Store("b", 2)
+
+	meta, ok := sh.GetEntryMeta("a")
+	require.True(t, ok)
+	assert.Equal(t, uint64(1), meta.InsertVersion)
+	assert.Equal(t, uint64(1), meta.Version)
+	assert.False(t, meta.InsertedAt.Before(before))
+	assert.Equal(t, meta.InsertedAt, meta.UpdatedAt)
+	assert.True(t, meta.ExpiresAt.IsZero())
+
+	time.Sleep(time.Millisecond)
+	sh.Store("a", 3)
+	updated, _ := sh.GetEntryMeta("a")
+	assert.Equal(t, uint64(1), updated.InsertVersion)
+	assert.Equal(t, uint64(3), updated.Version)
+	assert.Equal(t, meta.InsertedAt, updated.InsertedAt)
+	assert.True(t, updated.UpdatedAt.After(meta.UpdatedAt))
+
+	// An update while a snapshot pins the node replaces the node but keeps
+	// the insert metadata.
+	snap := sh.Snapshot()
+	sh.Store("b", 4)
+	snap.Close()
+	pinned, _ := sh.GetEntryMeta("b")
+	assert.Equal(t, uint64(2), pinned.InsertVersion)
+	assert.Equal(t, uint64(4), pinned.Version)
+
+	_, ok = sh.GetEntryMeta("missing")
+	assert.False(t, ok)
+
+	plain := New[string, int]()
+	plain.Store("a", 1)
+	meta, _ = plain.GetEntryMeta("a")
+	assert.True(t, meta.InsertedAt.IsZero())
+	assert.Equal(t, uint64(1), meta.InsertVersion)
+}
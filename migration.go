@@ -0,0 +1,150 @@
+package skiphash
+
+import (
+	"fmt"
+	"time"
+)
+
+type valueMigration[V any] struct {
+	from    int
+	to      int
+	migrate func(old []byte) (V, error)
+}
+
+// WithValueMigration registers how values persisted with schema version from
+// are decoded into the current V. The highest to across all registered
+// migrations becomes the value schema version written by the persistence
+// layer. Migrations chain: when to is older than the current version, the
+// result is encoded with the value codec and passed to the migration
+// registered from to, so that codec must write the format to expects.
+//
+// LoadFrom keeps values saved under an older version as they are and
+// migrates them on first access, a run of neighbouring keys at a time, as it
+// does when a tiered segment is read back in. Until then indexes, watchers
+// and the WAL do not see them, and a migration that fails leaves its run
+// invisible and is reported by TieringStats.LastError.
+func WithValueMigration[V any](from, to int, migrate func(old []byte) (V, error)) Option {
+	return func(cfg *config) {
+		if migrate == nil || from >= to {
+			return
+		}
+		cfg.valueMigrations = append(cfg.valueMigrations, valueMigration[V]{
+			from:    from,
+			to:      to,
+			migrate: migrate,
+		})
+	}
+}
+
+func (sh *SkipHash[K, V]) applyValueMigrations(specs []any) {
+	for _, spec := range specs {
+		m := typedOption[valueMigration[V]](spec, "WithValueMigration")
+		if sh.migrations == nil {
+			sh.migrations = make(map[int]valueMigration[V])
+		}
+		sh.migrations[m.from] = m
+		sh.valueSchema = max(sh.valueSchema, m.to)
+	}
+}
+
+// migrateValue upgrades a value persisted under an older schema version,
+// one registered step at a time.
+func (sh *SkipHash[K, V]) migrateValue(version int, raw []byte) (V, error) {
+	var zero V
+	from := version
+	for {
+		m, ok := sh.migrations[version]
+		if !ok {
+			return zero, fmt.Errorf("%w: from version %d to %d", ErrNoMigration, version, sh.valueSchema)
+		}
+		v, err := m.migrate(raw)
+		if err != nil {
+			return zero, fmt.Errorf("skiphash: migrate value from version %d: %w", version, err)
+		}
+		if m.to >= sh.valueSchema {
+			return v, nil
+		}
+		if raw, err = sh.valueCodec.encode(v); err != nil {
+			return zero, fmt.Errorf("skiphash: migrate value from version %d: re-encode at version %d: %w", from, m.to, err)
+		}
+		version = m.to
+	}
+}
+
+// unmigrated is a run of entries LoadFrom keeps in a segment with their
+// values still in an older schema.
+type unmigrated[K any] struct {
+	schema int
+	keys   []K
+	raw    [][]byte
+	expiry []int64
+}
+
+// unmigratedRun is how many entries share one unmigrated segment.
+const unmigratedRun = 256
+
+// keepUnmigratedLocked registers the sorted keys, whose values raw are in
+// schema, as segments to migrate on first access. sh must be empty.
+func (sh *SkipHash[K, V]) keepUnmigratedLocked(schema int, keys []K, raw [][]byte, expiry []int64) {
+	for start := 0; start < len(keys); start += unmigratedRun {
+		end := min(start+unmigratedRun, len(keys))
+		sh.insertSegmentLocked(&segment[K]{
+			first: keys[start],
+			last:  keys[end-1],
+			count: end - start,
+			unmigrated: &unmigrated[K]{
+				schema: schema,
+				keys:   keys[start:end:end],
+				raw:    raw[start:end:end],
+				expiry: expiry[start:end:end],
+			},
+		})
+		sh.tier.spilled += end - start
+	}
+}
+
+// migrateLocked migrates and inserts the entries of run, or none of them if
+// a value fails to migrate. Entries whose TTL ran out meanwhile are dropped.
+func (sh *SkipHash[K, V]) migrateLocked(run *unmigrated[K]) error {
+	values := make([]V, len(run.raw))
+	for i, raw := range run.raw {
+		v, err := sh.migrateValue(run.schema, raw)
+		if err != nil {
+			return fmt.Errorf("key %v: %w", run.keys[i], err)
+		}
+		values[i] = v
+	}
+	now := time.Now().UnixNano()
+	sh.beginBatchLocked()
+	defer sh.endBatchLocked()
+	for i, key := range run.keys {
+		at := run.expiry[i]
+		if at != 0 && at <= now {
+			continue
+		}
+		node := sh.attachLocked(key, values[i])
+		sh.noteWriteLocked(node)
+		sh.changedLocked(change[K, V]{kind: ChangeInsert, key: key, value: values[i]})
+		if at != 0 {
+			sh.scheduleLocked(key, time.Unix(0, at))
+		}
+	}
+	return nil
+}
+
+// dropUnmigratedLocked forgets the entries still waiting for migration,
+// which nothing outside the segments has seen.
+func (sh *SkipHash[K, V]) dropUnmigratedLocked() {
+	t := sh.tier
+	kept := t.segments[:0]
+	for _, seg := range t.segments {
+		if seg.unmigrated == nil {
+			kept = append(kept, seg)
+		} else {
+			t.spilled -= seg.count
+		}
+	}
+	clear(t.segments[len(kept):])
+	t.segments = kept
+	t.pending.Store(int32(len(kept)))
+}
@@ -0,0 +1,111 @@
+package skiphash
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSkipHashValueMigration(t *testing.T) {
+	sh := New[string, int](
+		WithValueMigration(1, 3, func(old []byte) (int, error) {
+			return strconv.Atoi(string(old))
+		}),
+		WithValueMigration(2, 3, func(old []byte) (int, error) {
+			return len(old), nil
+		}),
+	)
+	assert.Equal(t, 3, sh.valueSchema)
+
+	v, err := sh.migrateValue(1, []byte("42"))
+	require.NoError(t, err)
+	assert.Equal(t, 42, v)
+
+	v, err = sh.migrateValue(2, []byte("abcd"))
+	require.NoError(t, err)
+	assert.Equal(t, 4, v)
+
+	_, err = sh.migrateValue(1, []byte("nan"))
+	assert.ErrorIs(t, err, strconv.ErrSyntax)
+
+	_, err = sh.migrateValue(0, nil)
+	assert.True(t, errors.Is(err, ErrNoMigration))
+}
+
+func TestSkipHashValueMigrationChains(t *testing.T) {
+	sh := New[string, string](
+		WithValueMigration(1, 2, func(old []byte) (string, error) {
+			return strings.ToUpper(string(old)), nil
+		}),
+		WithValueMigration(2, 3, func(old []byte) (string, error) {
+			return string(old) + "!", nil
+		}),
+	)
+	v, err := sh.migrateValue(1, []byte("hi"))
+	require.NoError(t, err)
+	assert.Equal(t, "HI!", v)
+	v, err = sh.migrateValue(2, []byte("hi"))
+	require.NoError(t, err)
+	assert.Equal(t, "hi!", v)
+
+	gap := New[string, string](WithValueMigration(1, 2, func(old []byte) (string, error) {
+		return string(old), nil
+	}), WithValueMigration(3, 4, func(old []byte) (string, error) {
+		return string(old), nil
+	}))
+	_, err = gap.migrateValue(1, []byte("hi"))
+	assert.ErrorIs(t, err, ErrNoMigration)
+}
+
+func TestLoadFromMigratesLazily(t *testing.T) {
+	legacy := New[int, string]()
+	for i := range 1000 {
+		legacy.Store(i, strconv.Itoa(i))
+	}
+	var buf bytes.Buffer
+	require.NoError(t, legacy.SaveTo(&buf))
+
+	migrated := 0
+	sh := New[int, string](WithValueMigration(0, 1, func(old []byte) (string, error) {
+		migrated++
+		return "v" + string(old), nil
+	}))
+	require.NoError(t, sh.LoadFrom(bytes.NewReader(buf.Bytes())))
+	assert.Zero(t, migrated)
+	assert.Equal(t, 1000, sh.Len())
+
+	v, ok := sh.Get(600)
+	assert.True(t, ok)
+	assert.Equal(t, "v600", v)
+	assert.Equal(t, unmigratedRun, migrated, "only the run holding the key is migrated")
+
+	assert.Len(t, sh.RangeAll(), 1000)
+	assert.Equal(t, 1000, migrated)
+	assert.Equal(t, 1000, sh.Len())
+	checkSpans(t, sh)
+
+	// A value that fails to migrate hides its run and is reported.
+	broken := New[int, string](WithValueMigration(0, 1, func(old []byte) (string, error) {
+		if string(old) == "7" {
+			return "", errors.New("bad")
+		}
+		return string(old), nil
+	}))
+	require.NoError(t, broken.LoadFrom(bytes.NewReader(buf.Bytes())))
+	_, ok = broken.Get(3)
+	assert.False(t, ok)
+	assert.Error(t, broken.TieringStats().LastError)
+	v, _ = broken.Get(900)
+	assert.Equal(t, "900", v)
+}
+
+func TestSkipHashValueMigrationTypeMismatch(t *testing.T) {
+	assert.Panics(t, func() {
+		New[string, string](WithValueMigration(1, 2, func([]byte) (int, error) { return 0, nil }))
+	})
+}
@@ -0,0 +1,73 @@
+package skiphash
+
+import "sync"
+
+// WithNodePool recycles the nodes of removed entries for later inserts once
+// epoch reclamation has proven them unreachable, together with their link
+// arrays. It pays off for workloads that insert and remove at a high rate.
+func WithNodePool() Option {
+	return func(cfg *config) {
+		cfg.nodePool = true
+	}
+}
+
+// nodePool keeps one sync.Pool per node height so a reused node keeps its
+// next and prev pointers in a single backing array.
+type nodePool[K any, V any] struct {
+	heights []sync.Pool
+	reused  uint64
+}
+
+func newNodePool[K any, V any](maxLevel int) *nodePool[K, V] {
+	return &nodePool[K, V]{heights: make([]sync.Pool, maxLevel)}
+}
+
+// get returns a cleared node of the given height, or nil if there is none.
+// It runs under the write lock.
+func (p *nodePool[K, V]) get(height uint8) *slNode[K, V] {
+	node, ok := p.heights[height-1].Get().(*slNode[K, V])
+	if ok {
+		p.reused++
+	}
+	return node
+}
+
+// put clears node and makes it available to get. The caller guarantees
+// that nothing can reach node any more.
+func (p *nodePool[K, V]) put(node *slNode[K, V]) {
+	var zeroKey K
+	node.key = zeroKey
+	node.value.Store(nil)
+	node.rTime, node.iTime, node.version, node.created, node.writtenAt = 0, 0, 0, 0, 0
+	node.insertedAt, node.updatedAt = 0, 0
+	node.lastAccess.Store(0)
+	node.hits.Store(0)
+	node.unstitched = false
+	node.history = nil
+	node.expiry = deadline{}
+	clear(node.next)
+	clear(node.prev)
+	clear(node.span)
+	p.heights[node.height-1].Put(node)
+}
+
+// newNodeLocked returns an unlinked node for key, drawn from the pool when
+// WithNodePool is set and otherwise from the arena, if any, or the heap.
+func (sh *SkipHash[K, V]) newNodeLocked(key K, value V, height uint8) *slNode[K, V] {
+	var node *slNode[K, V]
+	if sh.epochs.pool != nil {
+		node = sh.epochs.pool.get(height)
+	}
+	if node == nil && sh.arena != nil {
+		node = sh.arena.alloc(height)
+	}
+	if node == nil {
+		node = &slNode[K, V]{height: height}
+		node.initLinks()
+	}
+	node.key = key
+	node.value.Store(&value)
+	node.iTime = sh.rqc.onUpdateLocked()
+	node.writtenAt = node.iTime
+	return node
+}
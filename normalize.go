@@ -0,0 +1,20 @@
+package skiphash
+
+// WithKeyNormalizer canonicalizes every key before it reaches the index or
+// the ordered list, e.g. strings.ToLower for case-insensitive string keys.
+// The normalizer is applied to keys passed to every operation, and the
+// normalized form is what Range and friends return.
+func WithKeyNormalizer[K any](normalize func(K) K) Option {
+	return func(cfg *config) {
+		if normalize != nil {
+			cfg.keyNormalizer = normalize
+		}
+	}
+}
+
+func (sh *SkipHash[K, V]) normalizeKey(key K) K {
+	if sh.normalize == nil {
+		return key
+	}
+	return sh.normalize(key)
+}
@@ -0,0 +1,123 @@
+package skiphash
+
+// OptimisticTxn buffers writes and records the write version of every key
+// it reads without holding any lock between calls. Commit applies the writes
+// atomically only if none of those keys has been written since it was read.
+type OptimisticTxn[K any, V any] struct {
+	sh     *SkipHash[K, V]
+	reads  []optimisticRead[K]
+	writes []optimisticWrite[K, V]
+	done   bool
+}
+
+// optimisticRead is the write version a key had when first read, or 0 if it
+// was absent.
+type optimisticRead[K any] struct {
+	key     K
+	version uint64
+}
+
+type optimisticWrite[K any, V any] struct {
+	key    K
+	value  V
+	remove bool
+}
+
+// Begin starts an optimistic transaction. It suits read-mostly work whose
+// user code should not hold the write lock, unlike Txn; on ErrConflict the
+// caller retries with a new transaction.
+func (sh *SkipHash[K, V]) Begin() *OptimisticTxn[K, V] {
+	sh.unsupportedInFineGrained()
+	return &OptimisticTxn[K, V]{sh: sh}
+}
+
+// Get returns the value of key, including the writes buffered so far.
+func (tx *OptimisticTxn[K, V]) Get(key K) (V, bool) {
+	tx.checkOpen()
+	sh := tx.sh
+	key = sh.normalizeKey(key)
+	for i := len(tx.writes) - 1; i >= 0; i-- {
+		if w := tx.writes[i]; sh.compare(w.key, key) == 0 {
+			return w.value, !w.remove
+		}
+	}
+
+	sh.faultIn(key, key)
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+	var value V
+	read := optimisticRead[K]{key: key}
+	node, ok := sh.index.get(key)
+	if ok {
+		value, read.version = *node.value.Load(), node.version
+	}
+	tx.recordRead(read)
+	return value, ok
+}
+
+// recordRead keeps the first version seen for each key, so a key that
+// changes between two reads conflicts at Commit.
+func (tx *OptimisticTxn[K, V]) recordRead(read optimisticRead[K]) {
+	for i := range tx.reads {
+		if tx.sh.compare(tx.reads[i].key, read.key) == 0 {
+			if tx.reads[i].version != read.version {
+				tx.reads[i].version = ^uint64(0)
+			}
+			return
+		}
+	}
+	tx.reads = append(tx.reads, read)
+}
+
+// Store buffers a write of value to key.
+func (tx *OptimisticTxn[K, V]) Store(key K, value V) {
+	tx.checkOpen()
+	tx.writes = append(tx.writes, optimisticWrite[K, V]{key: tx.sh.normalizeKey(key), value: value})
+}
+
+// Remove buffers the removal of key.
+func (tx *OptimisticTxn[K, V]) Remove(key K) {
+	tx.checkOpen()
+	tx.writes = append(tx.writes, optimisticWrite[K, V]{key: tx.sh.normalizeKey(key), remove: true})
+}
+
+// Commit checks under the write lock that every key read still has the
+// version it was read at and then applies the buffered writes as one Txn.
+// It returns ErrConflict if a read key changed, or the error of a rejected
+// write; in both cases nothing is written. A transaction commits once.
+func (tx *OptimisticTxn[K, V]) Commit() error {
+	tx.checkOpen()
+	tx.done = true
+	return tx.sh.Txn(func(t *Txn[K, V]) error {
+		sh := t.sh
+		for _, read := range tx.reads {
+			sh.faultInLocked(read.key, read.key)
+			var version uint64
+			if node, ok := sh.index.get(read.key); ok {
+				version = node.version
+			}
+			if version != read.version {
+				return ErrConflict
+			}
+		}
+		for _, w := range tx.writes {
+			if w.remove {
+				t.Remove(w.key)
+			} else if _, err := t.Store(w.key, w.value); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Discard abandons the transaction.
+func (tx *OptimisticTxn[K, V]) Discard() {
+	tx.done = true
+}
+
+func (tx *OptimisticTxn[K, V]) checkOpen() {
+	if tx.done {
+		panic("skiphash: OptimisticTxn used after Commit or Discard")
+	}
+}
@@ -0,0 +1,78 @@
+package skiphash
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptimisticTxn(t *testing.T) {
+	sh := New[string, int]()
+	sh.Store("a", 1)
+
+	tx := sh.Begin()
+	a, _ := tx.Get("a")
+	tx.Store("b", a+1)
+	tx.Remove("a")
+	_, ok := tx.Get("a")
+	assert.False(t, ok)
+	b, _ := tx.Get("b")
+	assert.Equal(t, 2, b)
+	assert.Equal(t, []Entry[string, int]{{"a", 1}}, sh.RangeAll())
+
+	require.NoError(t, tx.Commit())
+	assert.Equal(t, []Entry[string, int]{{"b", 2}}, sh.RangeAll())
+	assert.Panics(t, func() { tx.Get("b") })
+}
+
+func TestOptimisticTxnConflict(t *testing.T) {
+	sh := New[string, int]()
+	sh.Store("a", 1)
+
+	tx := sh.Begin()
+	tx.Get("a")
+	tx.Get("missing")
+	tx.Store("c", 3)
+	sh.Store("a", 1)
+	assert.ErrorIs(t, tx.Commit(), ErrConflict)
+	assert.False(t, sh.Contains("c"))
+
+	tx = sh.Begin()
+	tx.Get("missing")
+	sh.Store("missing", 0)
+	tx.Store("c", 3)
+	assert.ErrorIs(t, tx.Commit(), ErrConflict)
+
+	tx = sh.Begin()
+	tx.Get("a")
+	sh.Store("other", 0)
+	tx.Store("c", 3)
+	assert.NoError(t, tx.Commit())
+}
+
+func TestOptimisticTxnCounter(t *testing.T) {
+	sh := New[string, int]()
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Go(func() {
+			for range 200 {
+				for {
+					tx := sh.Begin()
+					n, _ := tx.Get("n")
+					tx.Store("n", n+1)
+					err := tx.Commit()
+					if !errors.Is(err, ErrConflict) {
+						require.NoError(t, err)
+						break
+					}
+				}
+			}
+		})
+	}
+	wg.Wait()
+	n, _ := sh.Get("n")
+	assert.Equal(t, 1600, n)
+}
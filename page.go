@@ -0,0 +1,87 @@
+package skiphash
+
+import (
+	"encoding/base64"
+	"fmt"
+)
+
+// Cursor marks where a RangePage left off. It is an opaque, URL-safe string
+// holding the last key returned, encoded with the key codec, so it can be
+// handed to API clients and survives concurrent writes: the next page starts
+// after that key whether or not it still exists. The zero Cursor starts at
+// the beginning of the range.
+type Cursor string
+
+// RangePage returns up to pageSize live entries of [low, high] after cursor,
+// and the cursor for the page that follows, which is empty once the range is
+// exhausted. Each page is read under one read lock. It fails only on a
+// cursor that does not decode.
+func (sh *SkipHash[K, V]) RangePage(low, high K, pageSize int, cursor Cursor) ([]Entry[K, V], Cursor, error) {
+	low, high = sh.normalizeKey(low), sh.normalizeKey(high)
+	after, err := sh.decodeCursor(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+	if pageSize <= 0 || sh.compare(low, high) > 0 {
+		return nil, "", nil
+	}
+
+	var page []Entry[K, V]
+	more := false
+	if sh.fine != nil {
+		page = sh.fine.entries(&low, &high)
+		if after != nil {
+			for len(page) > 0 && sh.compare(page[0].Key, *after) <= 0 {
+				page = page[1:]
+			}
+		}
+		if more = len(page) > pageSize; more {
+			page = page[:pageSize]
+		}
+	} else {
+		sh.faultIn(low, high)
+		sh.mu.RLock()
+		node := sh.firstLiveGELocked(low)
+		if after != nil && sh.compare(*after, low) >= 0 {
+			node = sh.firstLiveGELocked(*after)
+		}
+		page = make([]Entry[K, V], 0, min(pageSize, defaultEntryCap))
+		for ; node != sh.tail && sh.compare(node.key, high) <= 0; node = node.next[0] {
+			if node.rTime != 0 || after != nil && sh.compare(node.key, *after) <= 0 {
+				continue
+			}
+			if len(page) == pageSize {
+				more = true
+				break
+			}
+			sh.touch(node)
+			page = append(page, Entry[K, V]{Key: node.key, Value: *node.value.Load()})
+		}
+		sh.mu.RUnlock()
+	}
+
+	if !more {
+		return page, "", nil
+	}
+	next, err := sh.keyCodec.encode(page[len(page)-1].Key)
+	if err != nil {
+		return nil, "", fmt.Errorf("skiphash: encode cursor: %w", err)
+	}
+	return page, Cursor(base64.RawURLEncoding.EncodeToString(next)), nil
+}
+
+func (sh *SkipHash[K, V]) decodeCursor(cursor Cursor) (*K, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(string(cursor))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrBadCursor, err)
+	}
+	key, err := sh.keyCodec.decode(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrBadCursor, err)
+	}
+	key = sh.normalizeKey(key)
+	return &key, nil
+}
@@ -0,0 +1,64 @@
+package skiphash
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRangePage(t *testing.T) {
+	sh := New[int, int]()
+	for i := range 25 {
+		sh.Store(i, i)
+	}
+
+	var keys []int
+	var cursor Cursor
+	pages := 0
+	for {
+		page, next, err := sh.RangePage(5, 19, 4, cursor)
+		require.NoError(t, err)
+		keys = append(keys, keysOf(page)...)
+		pages++
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+	require.Equal(t, 4, pages)
+	want := make([]int, 0, 15)
+	for i := 5; i <= 19; i++ {
+		want = append(want, i)
+	}
+	require.Equal(t, want, keys)
+}
+
+func TestRangePageToleratesWrites(t *testing.T) {
+	sh := New[string, int]()
+	for _, k := range []string{"a", "b", "c", "d", "e"} {
+		sh.Store(k, 0)
+	}
+
+	page, next, err := sh.RangePage("a", "z", 2, "")
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b"}, keysOf(page))
+
+	// The cursor key disappears and a key is inserted behind it.
+	sh.Remove("b")
+	sh.Store("aa", 0)
+	sh.Store("bb", 0)
+	page, next, err = sh.RangePage("a", "z", 2, next)
+	require.NoError(t, err)
+	require.Equal(t, []string{"bb", "c"}, keysOf(page))
+
+	page, next, err = sh.RangePage("a", "z", 2, next)
+	require.NoError(t, err)
+	require.Equal(t, []string{"d", "e"}, keysOf(page))
+	require.Empty(t, next)
+}
+
+func TestRangePageBadCursor(t *testing.T) {
+	sh := New[int, int]()
+	_, _, err := sh.RangePage(0, 10, 5, "!!!")
+	require.ErrorIs(t, err, ErrBadCursor)
+}
@@ -0,0 +1,69 @@
+package skiphash
+
+import "sync"
+
+// RangeParallel calls fn for every live entry in [low, high] as of the start
+// of the scan, splitting the interval into up to workers runs of about equal
+// size and scanning them concurrently. The split points come from the span
+// counts, so finding them costs O(workers log n). fn is called from several
+// goroutines at once and in no particular order across runs; no lock is held
+// while it runs.
+func (sh *SkipHash[K, V]) RangeParallel(low, high K, workers int, fn func(key K, value V)) {
+	low, high = sh.normalizeKey(low), sh.normalizeKey(high)
+	if sh.compare(low, high) > 0 {
+		return
+	}
+	workers = max(workers, 1)
+	if sh.fine != nil {
+		entries := sh.fine.entries(&low, &high)
+		var wg sync.WaitGroup
+		for i := range workers {
+			run := entries[i*len(entries)/workers : (i+1)*len(entries)/workers]
+			wg.Go(func() {
+				for _, e := range run {
+					fn(e.Key, e.Value)
+				}
+			})
+		}
+		wg.Wait()
+		return
+	}
+	sh.faultIn(low, high)
+
+	sh.mu.Lock()
+	first := sh.rankLocked(low, false)
+	n := sh.rankLocked(high, true) - first
+	if n == 0 {
+		sh.mu.Unlock()
+		return
+	}
+	starts := make([]*slNode[K, V], min(workers, n))
+	for i := range starts {
+		starts[i] = sh.selectLocked(first + i*n/len(starts))
+	}
+	ver := sh.rqc.onRangeLocked()
+	sh.mu.Unlock()
+	defer func() {
+		sh.mu.Lock()
+		sh.rqc.afterRangeLocked(sh, ver)
+		sh.mu.Unlock()
+	}()
+
+	var wg sync.WaitGroup
+	for i, start := range starts {
+		var stop *K
+		if i+1 < len(starts) {
+			stop = &starts[i+1].key
+		}
+		wg.Go(func() {
+			sh.walkAtVersion(start, &high, ver, func(key K, value V) bool {
+				if stop != nil && sh.compare(key, *stop) >= 0 {
+					return false
+				}
+				fn(key, value)
+				return true
+			})
+		})
+	}
+	wg.Wait()
+}
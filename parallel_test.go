@@ -0,0 +1,40 @@
+package skiphash
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRangeParallel(t *testing.T) {
+	for _, mode := range []ConcurrencyMode{GlobalLock, FineGrained} {
+		sh := New[int, int](WithConcurrencyMode(mode))
+		for k := range 10_000 {
+			sh.Store(k, k)
+		}
+		for k := 0; k < 10_000; k += 3 {
+			sh.Remove(k)
+		}
+
+		for _, workers := range []int{0, 1, 7, 100_000} {
+			var mu sync.Mutex
+			seen := make(map[int]int)
+			sh.RangeParallel(1000, 8999, workers, func(k, v int) {
+				mu.Lock()
+				seen[k] += v
+				mu.Unlock()
+			})
+			want := make(map[int]int)
+			for _, e := range sh.Range(1000, 8999) {
+				want[e.Key] = e.Value
+			}
+			assert.Equal(t, want, seen, "workers %d", workers)
+		}
+
+		called := false
+		sh.RangeParallel(9, 3, 4, func(int, int) { called = true })
+		sh.RangeParallel(20_000, 30_000, 4, func(int, int) { called = true })
+		assert.False(t, called)
+	}
+}
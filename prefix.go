@@ -0,0 +1,94 @@
+package skiphash
+
+// RangePrefix returns the live entries whose key starts with prefix, in list
+// order. It scans only [prefix, end), where end is the smallest string
+// greater than every key with the prefix, so sh must order keys bytewise as
+// New does (optionally reversed by WithDescending).
+func RangePrefix[V any](sh *SkipHash[string, V], prefix string) []Entry[string, V] {
+	prefix = sh.normalizeKey(prefix)
+	end, bounded := prefixEnd(prefix)
+	descending := sh.compare("a", "b") > 0
+	if sh.fine != nil {
+		lo, hi := &prefix, &end
+		if !bounded {
+			hi = nil
+		}
+		if descending {
+			lo, hi = hi, lo
+		}
+		entries := sh.fine.entries(lo, hi)
+		if bounded && len(entries) > 0 {
+			if descending && entries[0].Key == end {
+				entries = entries[1:]
+			} else if !descending && entries[len(entries)-1].Key == end {
+				entries = entries[:len(entries)-1]
+			}
+		}
+		return entries
+	}
+	sh.faultInAll()
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+
+	var node *slNode[string, V]
+	inBlock := func(key string) bool { return !bounded || sh.compare(key, end) < 0 }
+	switch {
+	case !descending:
+		node = sh.firstLiveGELocked(prefix)
+	case bounded:
+		node = sh.firstLiveGELocked(end)
+		inBlock = func(key string) bool { return sh.compare(key, prefix) <= 0 }
+	default:
+		node = sh.head.next[0]
+		inBlock = func(key string) bool { return sh.compare(key, prefix) <= 0 }
+	}
+	out := make([]Entry[string, V], 0, defaultEntryCap)
+	for ; node != sh.tail && inBlock(node.key); node = node.next[0] {
+		if node.rTime == 0 && (!descending || !bounded || node.key != end) {
+			sh.touch(node)
+			out = append(out, Entry[string, V]{Key: node.key, Value: *node.value.Load()})
+		}
+	}
+	return out
+}
+
+// CountPrefix returns how many live keys start with prefix in O(log n),
+// using the span counts like RangeCount. The ordering requirement of
+// RangePrefix applies.
+func CountPrefix[V any](sh *SkipHash[string, V], prefix string) int {
+	if sh.fine != nil {
+		return len(RangePrefix(sh, prefix))
+	}
+	prefix = sh.normalizeKey(prefix)
+	end, bounded := prefixEnd(prefix)
+	sh.faultInAll()
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+
+	if sh.compare("a", "b") > 0 {
+		n := sh.rankLocked(prefix, true)
+		if bounded {
+			n -= sh.rankLocked(end, true)
+		}
+		return n
+	}
+	n := sh.len
+	if bounded {
+		n = sh.rankLocked(end, false)
+	}
+	return n - sh.rankLocked(prefix, false)
+}
+
+// prefixEnd returns the smallest string greater than every string with the
+// given prefix. It reports false when there is none: the prefix is empty or
+// made only of 0xff bytes.
+func prefixEnd(prefix string) (string, bool) {
+	end := []byte(prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return string(end[:i+1]), true
+		}
+	}
+	return "", false
+}
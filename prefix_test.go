@@ -0,0 +1,45 @@
+package skiphash
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func prefixFixture(opts ...Option) *SkipHash[string, int] {
+	sh := New[string, int](opts...)
+	for i, k := range []string{"t1/", "t1/a", "t1/b", "t1/b/c", "t10/a", "t2/a", "t1", "u\xff", "u\xff\xff"} {
+		sh.Store(k, i)
+	}
+	return sh
+}
+
+func TestRangePrefix(t *testing.T) {
+	sh := prefixFixture()
+	require.Equal(t, []string{"t1/", "t1/a", "t1/b", "t1/b/c"}, keysOf(RangePrefix(sh, "t1/")))
+	require.Equal(t, []string{"t1", "t1/", "t1/a", "t1/b", "t1/b/c", "t10/a"}, keysOf(RangePrefix(sh, "t1")))
+	require.Equal(t, []string{"u\xff", "u\xff\xff"}, keysOf(RangePrefix(sh, "u\xff")))
+	require.Empty(t, RangePrefix(sh, "v"))
+	require.Len(t, RangePrefix(sh, ""), sh.Len())
+
+	require.Equal(t, 4, CountPrefix(sh, "t1/"))
+	require.Equal(t, 6, CountPrefix(sh, "t1"))
+	require.Equal(t, 2, CountPrefix(sh, "u\xff"))
+	require.Equal(t, sh.Len(), CountPrefix(sh, ""))
+	require.Zero(t, CountPrefix(sh, "v"))
+}
+
+func TestRangePrefixDescending(t *testing.T) {
+	sh := prefixFixture(WithDescending())
+	sh.Store("t2", 0)
+	require.Equal(t, []string{"t1/b/c", "t1/b", "t1/a", "t1/"}, keysOf(RangePrefix(sh, "t1/")))
+	require.Equal(t, 4, CountPrefix(sh, "t1/"))
+	require.Equal(t, []string{"u\xff\xff", "u\xff"}, keysOf(RangePrefix(sh, "u\xff")))
+	require.Equal(t, 2, CountPrefix(sh, "u\xff"))
+}
+
+func TestRangePrefixFineGrained(t *testing.T) {
+	sh := prefixFixture(WithConcurrencyMode(FineGrained))
+	require.Equal(t, []string{"t1/", "t1/a", "t1/b", "t1/b/c"}, keysOf(RangePrefix(sh, "t1/")))
+	require.Equal(t, 6, CountPrefix(sh, "t1"))
+}
@@ -0,0 +1,55 @@
+package skiphash
+
+import "maps"
+
+type quotaTracker[K any] interface {
+	admit(key K) error
+	added(key K)
+	removed(key K)
+}
+
+type tenantQuota[K any, T comparable] struct {
+	extract func(K) T
+	limits  map[T]int
+	counts  map[T]int
+}
+
+// WithQuota limits how many live entries each tenant may own. The tenant of
+// a key is computed by extractTenant; tenants missing from limits are
+// unbounded. Counts are maintained under the write lock, so the limit holds
+// even with concurrent writers.
+func WithQuota[K any, T comparable](extractTenant func(K) T, limits map[T]int) Option {
+	limits = maps.Clone(limits)
+	return func(cfg *config) {
+		if extractTenant == nil {
+			return
+		}
+		cfg.quota = func() quotaTracker[K] {
+			return &tenantQuota[K, T]{
+				extract: extractTenant,
+				limits:  limits,
+				counts:  make(map[T]int),
+			}
+		}
+	}
+}
+
+func (q *tenantQuota[K, T]) admit(key K) error {
+	tenant := q.extract(key)
+	limit, ok := q.limits[tenant]
+	if !ok || q.counts[tenant] < limit {
+		return nil
+	}
+	return &QuotaError{Tenant: tenant, Limit: limit}
+}
+
+func (q *tenantQuota[K, T]) added(key K) {
+	q.counts[q.extract(key)]++
+}
+
+func (q *tenantQuota[K, T]) removed(key K) {
+	tenant := q.extract(key)
+	if q.counts[tenant]--; q.counts[tenant] <= 0 {
+		delete(q.counts, tenant)
+	}
+}
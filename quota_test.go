@@ -0,0 +1,66 @@
+package skiphash
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func tenantOf(key string) string {
+	tenant, _, _ := strings.Cut(key, "/")
+	return tenant
+}
+
+func TestSkipHashQuota(t *testing.T) {
+	sh := New[string, int](WithQuota(tenantOf, map[string]int{"a": 2}))
+
+	assert.NoError(t, sh.TryInsert("a/1", 1))
+	assert.NoError(t, sh.TryInsert("a/2", 2))
+
+	err := sh.TryInsert("a/3", 3)
+	var qerr *QuotaError
+	assert.True(t, errors.As(err, &qerr), "expected quota error, got %v", err)
+	assert.ErrorIs(t, err, ErrQuotaExceeded)
+	assert.Equal(t, "a", qerr.Tenant)
+	assert.Equal(t, 2, qerr.Limit)
+	assert.False(t, sh.Contains("a/3"), "rejected key must not be stored")
+
+	inserted, err := sh.TryStore("a/1", 10)
+	assert.NoError(t, err, "replacing a live key must not hit the quota")
+	assert.False(t, inserted)
+
+	assert.False(t, sh.Store("a/4", 4), "store above quota must fail")
+	assert.ErrorIs(t, sh.TryInsert("a/1", 1), ErrKeyExists)
+
+	assert.True(t, sh.Insert("b/1", 1), "tenants without limits are unbounded")
+
+	assert.True(t, sh.Remove("a/2"))
+	assert.NoError(t, sh.TryInsert("a/3", 3), "removal must free quota")
+}
+
+func TestSkipHashQuotaConcurrent(t *testing.T) {
+	const limit = 50
+	sh := New[string, int](WithQuota(tenantOf, map[string]int{"t": limit}))
+
+	var wg sync.WaitGroup
+	for w := range 8 {
+		wg.Go(func() {
+			for i := range 100 {
+				sh.Store(fmt.Sprintf("t/%d/%d", w, i), i)
+			}
+		})
+	}
+	wg.Wait()
+
+	assert.Equal(t, limit, sh.Len(), "quota must hold under concurrent writers")
+}
+
+func TestSkipHashQuotaKeyTypeMismatch(t *testing.T) {
+	assert.Panics(t, func() {
+		New[int, int](WithQuota(tenantOf, map[string]int{"a": 1}))
+	})
+}
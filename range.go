@@ -7,30 +7,53 @@ import (
 const defaultEntryCap = 16
 
 func (sh *SkipHash[K, V]) Range(low, high K) []Entry[K, V] {
-	if low > high {
-		return nil
+	entries, _ := sh.rangeReportingPath(nil, low, high)
+	return entries
+}
+
+// RangeAppend is Range appending to dst, so a caller can reuse one buffer
+// across queries. It returns the extended slice.
+func (sh *SkipHash[K, V]) RangeAppend(dst []Entry[K, V], low, high K) []Entry[K, V] {
+	entries, _ := sh.rangeReportingPath(dst, low, high)
+	return entries
+}
+
+// rangeReportingPath is RangeAppend, also reporting whether the slow path
+// ran. A nil dst gets a fresh buffer.
+func (sh *SkipHash[K, V]) rangeReportingPath(dst []Entry[K, V], low, high K) ([]Entry[K, V], bool) {
+	low, high = sh.normalizeKey(low), sh.normalizeKey(high)
+	if sh.compare(low, high) > 0 {
+		return dst, false
+	}
+	if sh.fine != nil {
+		if dst == nil {
+			return sh.fine.entries(&low, &high), false
+		}
+		return append(dst, sh.fine.entries(&low, &high)...), false
 	}
-	if entries, ok := sh.rangeFast(low, high); ok {
-		return entries
+	sh.faultIn(low, high)
+	if entries, ok := sh.rangeFast(dst, low, high); ok {
+		return entries, false
 	}
-	return sh.rangeSlow(low, high)
+	return sh.rangeSlow(dst, low, high), true
 }
 
-func (sh *SkipHash[K, V]) rangeFast(low, high K) ([]Entry[K, V], bool) {
+// entryBuffer returns dst, or a fresh buffer when dst is nil.
+func entryBuffer[K any, V any](dst []Entry[K, V]) []Entry[K, V] {
+	if dst == nil {
+		return make([]Entry[K, V], 0, defaultEntryCap)
+	}
+	return dst
+}
+
+func (sh *SkipHash[K, V]) rangeFast(dst []Entry[K, V], low, high K) ([]Entry[K, V], bool) {
 	for try := 0; try < sh.fastPathTries; try++ {
 		if !sh.mu.TryRLock() {
 			runtime.Gosched()
 			continue
 		}
 
-		entries := make([]Entry[K, V], 0, defaultEntryCap)
-
-		for node := sh.lowerBoundLocked(low); node != sh.tail && node.key <= high; node = node.next[0] {
-			if node.rTime == 0 {
-				entries = append(entries, Entry[K, V]{Key: node.key, Value: node.value})
-			}
-		}
-
+		entries := sh.collectRangeLocked(dst, low, high)
 		sh.mu.RUnlock()
 
 		return entries, true
@@ -39,7 +62,18 @@ func (sh *SkipHash[K, V]) rangeFast(low, high K) ([]Entry[K, V], bool) {
 	return nil, false
 }
 
-func (sh *SkipHash[K, V]) rangeSlow(low, high K) []Entry[K, V] {
+func (sh *SkipHash[K, V]) collectRangeLocked(dst []Entry[K, V], low, high K) []Entry[K, V] {
+	entries := entryBuffer(dst)
+	for node := sh.lowerBoundLocked(low); node != sh.tail && sh.compare(node.key, high) <= 0; node = node.next[0] {
+		if node.rTime == 0 {
+			sh.touch(node)
+			entries = append(entries, Entry[K, V]{Key: node.key, Value: *node.value.Load()})
+		}
+	}
+	return entries
+}
+
+func (sh *SkipHash[K, V]) rangeSlow(dst []Entry[K, V], low, high K) []Entry[K, V] {
 	var (
 		start *slNode[K, V]
 		ver   uint64
@@ -50,13 +84,44 @@ func (sh *SkipHash[K, V]) rangeSlow(low, high K) []Entry[K, V] {
 	ver = sh.rqc.onRangeLocked()
 	sh.mu.Unlock()
 
-	entries := make([]Entry[K, V], 0, defaultEntryCap)
+	entries := sh.collectAtVersion(dst, start, high, ver)
+
+	sh.mu.Lock()
+	sh.rqc.afterRangeLocked(sh, ver)
+	sh.mu.Unlock()
+
+	return entries
+}
+
+// collectAtVersion walks from start up to high, taking the read lock per
+// node, and appends the entries visible at ver to dst. The caller must keep
+// ver registered with the range coordinator for the duration of the walk.
+func (sh *SkipHash[K, V]) collectAtVersion(dst []Entry[K, V], start *slNode[K, V], high K, ver uint64) []Entry[K, V] {
+	entries := entryBuffer(dst)
+	sh.walkAtVersion(start, &high, ver, func(key K, value V) bool {
+		entries = append(entries, Entry[K, V]{Key: key, Value: value})
+		return true
+	})
+	return entries
+}
+
+// walkAtVersion is collectAtVersion calling yield for each entry, without
+// holding the lock, until yield returns false. A nil high walks to the end.
+func (sh *SkipHash[K, V]) walkAtVersion(start *slNode[K, V], high *K, ver uint64, yield func(K, V) bool) {
+	sh.walkEntriesAtVersion(start, high, ver, func(key K, value V, _ int64) bool {
+		return yield(key, value)
+	})
+}
+
+// walkEntriesAtVersion is walkAtVersion, also passing each entry's TTL
+// deadline, 0 for none.
+func (sh *SkipHash[K, V]) walkEntriesAtVersion(start *slNode[K, V], high *K, ver uint64, yield func(K, V, int64) bool) {
 	node := start
 	for {
 		sh.mu.RLock()
-		if node == sh.tail || node.key > high {
+		if node == sh.tail || (high != nil && sh.compare(node.key, *high) > 0) {
 			sh.mu.RUnlock()
-			break
+			return
 		}
 
 		include := node != sh.head &&
@@ -64,20 +129,21 @@ func (sh *SkipHash[K, V]) rangeSlow(low, high K) []Entry[K, V] {
 			(node.rTime == 0 || node.rTime >= ver)
 		next := sh.nextSafeLocked(node, ver)
 		key := node.key
-		value := node.value
+		var value V
+		var expiry int64
+		if include {
+			value, expiry = *node.value.Load(), node.expiry.at
+		}
 		sh.mu.RUnlock()
 
 		if include {
-			entries = append(entries, Entry[K, V]{Key: key, Value: value})
+			sh.touch(node)
+			if !yield(key, value, expiry) {
+				return
+			}
 		}
 		node = next
 	}
-
-	sh.mu.Lock()
-	sh.rqc.afterRangeLocked(sh, ver)
-	sh.mu.Unlock()
-
-	return entries
 }
 
 func (sh *SkipHash[K, V]) nextSafeLocked(node *slNode[K, V], ver uint64) *slNode[K, V] {
@@ -97,3 +163,75 @@ func (sh *SkipHash[K, V]) isSafeLocked(node *slNode[K, V], ver uint64) bool {
 	}
 	return node.rTime == 0 || node.rTime >= ver
 }
+
+// RangeKeys returns the live keys in [low, high], in order, without
+// copying their values.
+func (sh *SkipHash[K, V]) RangeKeys(low, high K) []K {
+	var keys []K
+	sh.rangeEach(low, high, func(key K, _ *V) {
+		keys = append(keys, key)
+	})
+	return keys
+}
+
+// RangeValues returns the values of the live keys in [low, high], in key
+// order.
+func (sh *SkipHash[K, V]) RangeValues(low, high K) []V {
+	var values []V
+	sh.rangeEach(low, high, func(_ K, value *V) {
+		values = append(values, *value)
+	})
+	return values
+}
+
+// rangeEach calls fn under the read lock for each live entry in [low, high].
+// value points at the stored value so projections copy only what they keep.
+func (sh *SkipHash[K, V]) rangeEach(low, high K, fn func(key K, value *V)) {
+	low, high = sh.normalizeKey(low), sh.normalizeKey(high)
+	if sh.compare(low, high) > 0 {
+		return
+	}
+	if sh.fine != nil {
+		entries := sh.fine.entries(&low, &high)
+		for i := range entries {
+			fn(entries[i].Key, &entries[i].Value)
+		}
+		return
+	}
+	sh.faultIn(low, high)
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+	for node := sh.lowerBoundLocked(low); node != sh.tail && sh.compare(node.key, high) <= 0; node = node.next[0] {
+		if node.rTime == 0 {
+			sh.touch(node)
+			fn(node.key, node.value.Load())
+		}
+	}
+}
+
+// RangeLimit returns at most limit live entries in [low, high]. When more
+// entries remain in the interval, more is true and nextKey is the key to pass
+// as low to resume the scan.
+func (sh *SkipHash[K, V]) RangeLimit(low, high K, limit int) (entries []Entry[K, V], nextKey K, more bool) {
+	sh.unsupportedInFineGrained()
+	low, high = sh.normalizeKey(low), sh.normalizeKey(high)
+	if limit <= 0 || sh.compare(low, high) > 0 {
+		return nil, nextKey, false
+	}
+	sh.faultIn(low, high)
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+
+	entries = make([]Entry[K, V], 0, min(limit, defaultEntryCap))
+	for node := sh.lowerBoundLocked(low); node != sh.tail && sh.compare(node.key, high) <= 0; node = node.next[0] {
+		if node.rTime != 0 {
+			continue
+		}
+		if len(entries) == limit {
+			return entries, node.key, true
+		}
+		sh.touch(node)
+		entries = append(entries, Entry[K, V]{Key: node.key, Value: *node.value.Load()})
+	}
+	return entries, nextKey, false
+}
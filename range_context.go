@@ -0,0 +1,90 @@
+package skiphash
+
+import (
+	"context"
+	"iter"
+)
+
+// RangeContext is Range for long scans that must stop when ctx is done. It
+// always takes the versioned path, holding the read lock one node at a time,
+// and checks ctx between nodes. On cancellation it returns the entries
+// collected so far and ctx.Err().
+func (sh *SkipHash[K, V]) RangeContext(ctx context.Context, low, high K) ([]Entry[K, V], error) {
+	entries := make([]Entry[K, V], 0, defaultEntryCap)
+	for key, value := range sh.RangeIterContext(ctx, low, high) {
+		entries = append(entries, Entry[K, V]{Key: key, Value: value})
+	}
+	if err := ctx.Err(); err != nil {
+		return entries, err
+	}
+	return entries, nil
+}
+
+// RangeIterContext iterates over the live entries in [low, high] as of the
+// start of the iteration, stopping early when ctx is done; check ctx.Err()
+// after the loop to tell the two apart. The iteration holds no lock while
+// the loop body runs, and its version is released when the loop ends.
+func (sh *SkipHash[K, V]) RangeIterContext(ctx context.Context, low, high K) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		low, high := sh.normalizeKey(low), sh.normalizeKey(high)
+		if ctx.Err() != nil || sh.compare(low, high) > 0 {
+			return
+		}
+		sh.walkBounded(&low, &high, func(key K, value V) bool {
+			return ctx.Err() == nil && yield(key, value)
+		})
+	}
+}
+
+// RangeStream sends the live entries in [low, high], as of the start of the
+// scan, on the returned channel and closes it when done. The scan runs in its
+// own goroutine and keeps its version registered until it finishes, so the
+// consumer must either drain the channel or cancel ctx.
+func (sh *SkipHash[K, V]) RangeStream(ctx context.Context, low, high K) <-chan Entry[K, V] {
+	out := make(chan Entry[K, V], defaultEntryCap)
+	go func() {
+		defer close(out)
+		for key, value := range sh.RangeIterContext(ctx, low, high) {
+			select {
+			case out <- Entry[K, V]{Key: key, Value: value}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// walkBounded calls yield for the live entries between the normalized
+// bounds as of the start of the walk; a nil bound is open. It holds no lock
+// while yield runs.
+func (sh *SkipHash[K, V]) walkBounded(low, high *K, yield func(K, V) bool) {
+	if sh.fine != nil {
+		for _, e := range sh.fine.entries(low, high) {
+			if !yield(e.Key, e.Value) {
+				return
+			}
+		}
+		return
+	}
+	if low != nil && high != nil {
+		sh.faultIn(*low, *high)
+	} else {
+		sh.faultInAll()
+	}
+
+	sh.mu.Lock()
+	start := sh.head
+	if low != nil {
+		start = sh.firstLiveGELocked(*low)
+	}
+	ver := sh.rqc.onRangeLocked()
+	sh.mu.Unlock()
+	defer func() {
+		sh.mu.Lock()
+		sh.rqc.afterRangeLocked(sh, ver)
+		sh.mu.Unlock()
+	}()
+
+	sh.walkAtVersion(start, high, ver, yield)
+}
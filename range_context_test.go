@@ -0,0 +1,82 @@
+package skiphash
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRangeContextMatchesRange(t *testing.T) {
+	sh := New[int, int]()
+	for i := range 100 {
+		sh.Store(i, i*10)
+	}
+
+	entries, err := sh.RangeContext(context.Background(), 10, 20)
+	require.NoError(t, err)
+	require.Equal(t, sh.Range(10, 20), entries)
+	require.Nil(t, sh.rqc.tail)
+}
+
+func TestRangeContextCancelled(t *testing.T) {
+	sh := New[int, int]()
+	for i := range 100 {
+		sh.Store(i, i)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	entries, err := sh.RangeContext(ctx, 0, 99)
+	require.ErrorIs(t, err, context.Canceled)
+	require.Empty(t, entries)
+}
+
+func TestRangeIterContextStopsMidScan(t *testing.T) {
+	sh := New[int, int]()
+	for i := range 100 {
+		sh.Store(i, i)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var keys []int
+	for key := range sh.RangeIterContext(ctx, 0, 99) {
+		keys = append(keys, key)
+		if key == 4 {
+			cancel()
+		}
+	}
+	require.Equal(t, []int{0, 1, 2, 3, 4}, keys)
+	require.ErrorIs(t, ctx.Err(), context.Canceled)
+	require.Nil(t, sh.rqc.tail, "cancelled scan must release its version")
+
+	// A removal after the scan is unstitched immediately again.
+	require.True(t, sh.Remove(50))
+	_, ok := sh.Get(50)
+	require.False(t, ok)
+}
+
+func TestRangeStream(t *testing.T) {
+	sh := New[int, int]()
+	for i := range 1000 {
+		sh.Store(i, i)
+	}
+
+	var got []Entry[int, int]
+	for e := range sh.RangeStream(context.Background(), 100, 899) {
+		got = append(got, e)
+	}
+	require.Equal(t, sh.Range(100, 899), got)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := sh.RangeStream(ctx, 0, 999)
+	first := <-stream
+	require.Equal(t, 0, first.Key)
+	cancel()
+	for range stream {
+	}
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+	require.Nil(t, sh.rqc.tail, "the version is released after cancellation")
+}
@@ -0,0 +1,165 @@
+package skiphash
+
+import "math"
+
+// Rank returns the number of live keys strictly less than key.
+func (sh *SkipHash[K, V]) Rank(key K) int {
+	key = sh.normalizeKey(key)
+	if sh.fine != nil {
+		return sh.fine.rank(key)
+	}
+	sh.faultInAll()
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+	return sh.rankLocked(key, false)
+}
+
+// Select returns the n-th smallest live entry, counting from zero, so that
+// Select(Rank(k)) yields k whenever k is present.
+func (sh *SkipHash[K, V]) Select(n int) (Entry[K, V], bool) {
+	if sh.fine != nil {
+		return sh.fine.nth(n)
+	}
+	sh.faultInAll()
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+
+	node := sh.selectLocked(n)
+	if node == nil {
+		var zero Entry[K, V]
+		return zero, false
+	}
+	return Entry[K, V]{
+		Key:   node.key,
+		Value: *node.value.Load(),
+	}, true
+}
+
+// rankLocked counts the live keys below key, or at most key when inclusive.
+func (sh *SkipHash[K, V]) rankLocked(key K, inclusive bool) int {
+	rank := 0
+	cur := sh.head
+	for level := sh.topLevelLocked() - 1; level >= 0; level-- {
+		next := cur.next[level]
+		for next != sh.tail && (sh.compare(next.key, key) < 0 || inclusive && sh.compare(next.key, key) == 0) {
+			rank += cur.span[level]
+			cur = next
+			next = cur.next[level]
+		}
+	}
+	return rank
+}
+
+// selectLocked returns the live node with exactly n live nodes before it, or
+// nil when n is out of bounds.
+func (sh *SkipHash[K, V]) selectLocked(n int) *slNode[K, V] {
+	if n < 0 || n >= sh.len {
+		return nil
+	}
+	traversed := 0
+	cur := sh.head
+	for level := sh.topLevelLocked() - 1; level >= 0; level-- {
+		for cur.next[level] != sh.tail && traversed+cur.span[level] <= n {
+			traversed += cur.span[level]
+			cur = cur.next[level]
+		}
+	}
+	// Tombstones have zero span, so the descent already stepped past them and
+	// the base-level successor is the live node we are looking for.
+	return cur.next[0]
+}
+
+// CountLess returns the number of live keys strictly less than key; it is
+// Rank.
+func (sh *SkipHash[K, V]) CountLess(key K) int {
+	return sh.Rank(key)
+}
+
+// CountGreater returns the number of live keys strictly greater than key.
+func (sh *SkipHash[K, V]) CountGreater(key K) int {
+	sh.unsupportedInFineGrained()
+	key = sh.normalizeKey(key)
+	sh.faultInAll()
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+	return sh.len - sh.rankLocked(key, true)
+}
+
+// CountBetween returns the number of live keys between low and high, each
+// bound included or excluded as requested. Like RangeCount it runs in
+// O(log n).
+func (sh *SkipHash[K, V]) CountBetween(low, high K, inclusiveLow, inclusiveHigh bool) int {
+	sh.unsupportedInFineGrained()
+	low, high = sh.normalizeKey(low), sh.normalizeKey(high)
+	if sh.compare(low, high) > 0 {
+		return 0
+	}
+	sh.faultIn(low, high)
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+	return max(sh.rankLocked(high, inclusiveHigh)-sh.rankLocked(low, !inclusiveLow), 0)
+}
+
+// Quantile returns the key at quantile q of the live keys, with 0 the
+// smallest and 1 the largest, by the nearest-rank method. It reports false
+// when the SkipHash is empty or q is outside [0, 1]. It costs one Select.
+func (sh *SkipHash[K, V]) Quantile(q float64) (K, bool) {
+	keys := sh.Quantiles([]float64{q})
+	if keys == nil {
+		var zero K
+		return zero, false
+	}
+	return keys[0], true
+}
+
+// Quantiles returns the key at each quantile in qs as Quantile does, all
+// from the same state of the SkipHash. It returns nil when the SkipHash is
+// empty or any quantile is outside [0, 1].
+func (sh *SkipHash[K, V]) Quantiles(qs []float64) []K {
+	sh.unsupportedInFineGrained()
+	sh.faultInAll()
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+
+	if sh.len == 0 {
+		return nil
+	}
+	keys := make([]K, len(qs))
+	for i, q := range qs {
+		if !(q >= 0 && q <= 1) {
+			return nil
+		}
+		rank := max(int(math.Ceil(q*float64(sh.len)))-1, 0)
+		keys[i] = sh.selectLocked(rank).key
+	}
+	return keys
+}
+
+// Histogram counts the live keys in the buckets that boundaries delimit:
+// below boundaries[0], then [boundaries[i-1], boundaries[i]) for each i,
+// then from the last boundary on, so it returns len(boundaries)+1 counts.
+// Each bucket costs one rank query. It panics if boundaries are not sorted
+// in key order.
+func (sh *SkipHash[K, V]) Histogram(boundaries []K) []int {
+	sh.unsupportedInFineGrained()
+	bounds := make([]K, len(boundaries))
+	for i, b := range boundaries {
+		bounds[i] = sh.normalizeKey(b)
+		if i > 0 && sh.compare(bounds[i-1], bounds[i]) > 0 {
+			panic("skiphash: Histogram boundaries are not sorted")
+		}
+	}
+	sh.faultInAll()
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+
+	counts := make([]int, len(bounds)+1)
+	below := 0
+	for i, b := range bounds {
+		rank := sh.rankLocked(b, false)
+		counts[i] = rank - below
+		below = rank
+	}
+	counts[len(bounds)] = sh.len - below
+	return counts
+}
@@ -0,0 +1,161 @@
+package skiphash
+
+import (
+	"math"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// checkSpans verifies that every span matches the live nodes it covers.
+func checkSpans[K any, V any](t *testing.T, sh *SkipHash[K, V]) {
+	t.Helper()
+	for level := 0; level < sh.maxLevel; level++ {
+		for cur := sh.head; cur != sh.tail; cur = cur.next[level] {
+			want := 0
+			for n := cur.next[0]; ; n = n.next[0] {
+				if n != sh.tail && n.rTime == 0 {
+					want++
+				}
+				if n == cur.next[level] {
+					break
+				}
+			}
+			require.Equal(t, want, cur.span[level], "bad span at level %d", level)
+		}
+	}
+}
+
+func TestSkipHashRankSelect(t *testing.T) {
+	sh := New[int, int](WithRandSource(rand.NewSource(5)))
+	r := rand.New(rand.NewSource(6))
+	live := map[int]bool{}
+	for range 2000 {
+		k := r.Intn(500)
+		if r.Intn(3) == 0 {
+			sh.Remove(k)
+			delete(live, k)
+		} else {
+			sh.Store(k, k*2)
+			live[k] = true
+		}
+	}
+	checkSpans(t, sh)
+
+	keys := make([]int, 0, len(live))
+	for k := range live {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	for i, k := range keys {
+		assert.Equal(t, i, sh.Rank(k), "unexpected rank for key=%d", k)
+		e, ok := sh.Select(i)
+		assert.True(t, ok)
+		assert.Equal(t, k, e.Key, "unexpected select(%d)", i)
+		assert.Equal(t, k*2, e.Value)
+	}
+	assert.Equal(t, len(keys), sh.Rank(1000))
+	assert.Equal(t, 0, sh.Rank(-1))
+
+	_, ok := sh.Select(len(keys))
+	assert.False(t, ok)
+	_, ok = sh.Select(-1)
+	assert.False(t, ok)
+}
+
+func TestSkipHashRankWithDeferredUnstitch(t *testing.T) {
+	sh := New[int, int](WithRandSource(rand.NewSource(7)))
+	for i := range 100 {
+		sh.Insert(i, i)
+	}
+
+	sh.mu.Lock()
+	ver := sh.rqc.onRangeLocked()
+	sh.mu.Unlock()
+
+	for i := 0; i < 100; i += 2 {
+		sh.Remove(i)
+	}
+	sh.Insert(10, 10)
+	checkSpans(t, sh)
+	assert.Equal(t, 5, sh.Rank(10))
+	e, _ := sh.Select(5)
+	assert.Equal(t, 10, e.Key)
+
+	sh.mu.Lock()
+	sh.rqc.afterRangeLocked(sh, ver)
+	sh.mu.Unlock()
+	checkSpans(t, sh)
+	assert.Equal(t, 5, sh.Rank(10))
+	e, _ = sh.Select(50)
+	assert.Equal(t, 99, e.Key)
+}
+
+func TestSkipHashCountBounds(t *testing.T) {
+	sh := New[string, int]()
+	for _, k := range []string{"a", "b", "c", "d", "e"} {
+		sh.Store(k, 0)
+	}
+	sh.Remove("c")
+
+	assert.Equal(t, 2, sh.CountLess("c"))
+	assert.Equal(t, 1, sh.CountLess("b"))
+	assert.Equal(t, 2, sh.CountGreater("c"))
+	assert.Equal(t, 1, sh.CountGreater("d"))
+	assert.Equal(t, 0, sh.CountGreater("z"))
+
+	assert.Equal(t, 2, sh.CountBetween("b", "e", true, false))
+	assert.Equal(t, 2, sh.CountBetween("b", "e", false, true))
+	assert.Equal(t, 1, sh.CountBetween("b", "e", false, false))
+	assert.Equal(t, 3, sh.CountBetween("b", "e", true, true))
+	assert.Equal(t, 0, sh.CountBetween("b", "b", false, true))
+	assert.Equal(t, 1, sh.CountBetween("b", "b", true, true))
+	assert.Equal(t, 0, sh.CountBetween("e", "b", true, true))
+	assert.Equal(t, 1, sh.CountBetween("bb", "dd", true, true))
+}
+
+func TestSkipHashQuantiles(t *testing.T) {
+	sh := New[int, struct{}]()
+	_, ok := sh.Quantile(0.5)
+	assert.False(t, ok)
+
+	for i := 1; i <= 100; i++ {
+		sh.Store(i*10, struct{}{})
+	}
+	key, ok := sh.Quantile(0.5)
+	assert.True(t, ok)
+	assert.Equal(t, 500, key)
+	assert.Equal(t, []int{10, 10, 500, 990, 1000}, sh.Quantiles([]float64{0, 0.01, 0.5, 0.99, 1}))
+
+	_, ok = sh.Quantile(1.5)
+	assert.False(t, ok)
+	assert.Nil(t, sh.Quantiles([]float64{0.5, -0.1}))
+	assert.Nil(t, sh.Quantiles([]float64{math.NaN()}))
+}
+
+func TestSkipHashHistogram(t *testing.T) {
+	sh := New[int, int]()
+	assert.Equal(t, []int{0}, sh.Histogram(nil))
+	for i := range 100 {
+		sh.Store(i, i)
+	}
+	sh.Remove(15)
+
+	assert.Equal(t, []int{99}, sh.Histogram(nil))
+	assert.Equal(t, []int{10, 9, 0, 80}, sh.Histogram([]int{10, 20, 20}))
+	assert.Equal(t, []int{0, 99, 0}, sh.Histogram([]int{-5, 1000}))
+
+	counts := sh.Histogram([]int{0, 25, 50, 75})
+	total := 0
+	for i, n := range counts[1:4] {
+		assert.Equal(t, sh.CountBetween(i*25, (i+1)*25, true, false), n)
+		total += n
+	}
+	assert.Equal(t, sh.Len(), total+counts[0]+counts[4])
+
+	assert.Panics(t, func() { sh.Histogram([]int{5, 1}) })
+}
@@ -1,18 +1,29 @@
 package skiphash
 
-import "cmp"
+import "time"
 
-type rangeCoordinator[K cmp.Ordered, V any] struct {
+type rangeCoordinator[K any, V any] struct {
 	counter uint64
 
 	head *rangeOp[K, V]
 	tail *rangeOp[K, V]
 
 	byVersion map[uint64]*rangeOp[K, V]
+
+	// backlog counts the deferred nodes of all operations. With
+	// WithTombstoneGC, exceeding gcMaxDeferred or keeping the oldest
+	// operation open past gcMaxAge unlinks the whole backlog; forced counts
+	// the nodes unlinked that way.
+	backlog       int
+	gcMaxDeferred int
+	gcMaxAge      time.Duration
+	forced        uint64
 }
 
-type rangeOp[K cmp.Ordered, V any] struct {
+type rangeOp[K any, V any] struct {
 	ver uint64
+	// started is set only when the age limit needs it.
+	started time.Time
 
 	deferred []*slNode[K, V]
 
@@ -20,7 +31,7 @@ type rangeOp[K cmp.Ordered, V any] struct {
 	next *rangeOp[K, V]
 }
 
-func newRangeCoordinator[K cmp.Ordered, V any]() *rangeCoordinator[K, V] {
+func newRangeCoordinator[K any, V any]() *rangeCoordinator[K, V] {
 	return &rangeCoordinator[K, V]{
 		counter:   1,
 		byVersion: make(map[uint64]*rangeOp[K, V]),
@@ -30,6 +41,9 @@ func newRangeCoordinator[K cmp.Ordered, V any]() *rangeCoordinator[K, V] {
 func (r *rangeCoordinator[K, V]) onRangeLocked() uint64 {
 	r.counter++
 	op := &rangeOp[K, V]{ver: r.counter}
+	if r.gcMaxAge > 0 {
+		op.started = time.Now()
+	}
 	if r.tail == nil {
 		r.head = op
 		r.tail = op
@@ -46,12 +60,38 @@ func (r *rangeCoordinator[K, V]) onUpdateLocked() uint64 {
 	return r.counter
 }
 
+// pinnedLocked reports whether node is visible to an active range
+// operation, in which case its fields must not change in place.
+func (r *rangeCoordinator[K, V]) pinnedLocked(node *slNode[K, V]) bool {
+	return r.tail != nil && node.iTime < r.tail.ver
+}
+
 func (r *rangeCoordinator[K, V]) afterRemoveLocked(sh *SkipHash[K, V], node *slNode[K, V]) {
 	if r.tail == nil || node.iTime >= r.tail.ver {
 		sh.unstitchNodeLocked(node)
 		return
 	}
 	r.tail.deferred = append(r.tail.deferred, node)
+	r.backlog++
+	if r.gcMaxDeferred > 0 && r.backlog > r.gcMaxDeferred ||
+		r.gcMaxAge > 0 && time.Since(r.head.started) > r.gcMaxAge {
+		r.forceUnlinkLocked(sh)
+	}
+}
+
+// forceUnlinkLocked unlinks every deferred node ahead of the operations that
+// own them. The nodes are not reclaimed, so a walk positioned on one can
+// still move on, but walks that have not reached them yet will miss them.
+func (r *rangeCoordinator[K, V]) forceUnlinkLocked(sh *SkipHash[K, V]) {
+	for op := r.head; op != nil; op = op.next {
+		for _, node := range op.deferred {
+			if sh.unlinkNodeLocked(node) {
+				r.forced++
+			}
+		}
+		op.deferred = nil
+	}
+	r.backlog = 0
 }
 
 func (r *rangeCoordinator[K, V]) afterRangeLocked(sh *SkipHash[K, V], ver uint64) {
@@ -78,6 +118,7 @@ func (r *rangeCoordinator[K, V]) afterRangeLocked(sh *SkipHash[K, V], ver uint64
 		for _, node := range op.deferred {
 			sh.unstitchNodeLocked(node)
 		}
+		r.backlog -= len(op.deferred)
 		return
 	}
 	pred.deferred = append(pred.deferred, op.deferred...)
@@ -0,0 +1,44 @@
+package skiphash
+
+import (
+	"math/rand"
+	"slices"
+)
+
+// Sample returns n distinct live entries chosen uniformly at random, in key
+// order, or every entry if there are at most n. Each pick is a rank lookup
+// over the span counts, so Sample costs O(n log n) without a scan.
+func (sh *SkipHash[K, V]) Sample(n int) []Entry[K, V] {
+	sh.unsupportedInFineGrained()
+	sh.faultInAll()
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+
+	if n >= sh.len {
+		return sh.allEntriesLocked()
+	}
+	if n <= 0 {
+		return nil
+	}
+	// Floyd's algorithm draws n distinct ranks with n random numbers.
+	picked := make(map[int]struct{}, n)
+	for j := sh.len - n; j < sh.len; j++ {
+		r := rand.Intn(j + 1)
+		if _, dup := picked[r]; dup {
+			r = j
+		}
+		picked[r] = struct{}{}
+	}
+	ranks := make([]int, 0, n)
+	for r := range picked {
+		ranks = append(ranks, r)
+	}
+	slices.Sort(ranks)
+
+	out := make([]Entry[K, V], 0, n)
+	for _, r := range ranks {
+		node := sh.selectLocked(r)
+		out = append(out, Entry[K, V]{Key: node.key, Value: *node.value.Load()})
+	}
+	return out
+}
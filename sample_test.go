@@ -0,0 +1,42 @@
+package skiphash
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSample(t *testing.T) {
+	sh := New[int, int]()
+	assert.Empty(t, sh.Sample(3))
+	for i := range 100 {
+		sh.Store(i, i*i)
+	}
+	for i := 0; i < 100; i += 3 {
+		sh.Remove(i)
+	}
+
+	assert.Len(t, sh.Sample(1000), sh.Len())
+	assert.Empty(t, sh.Sample(0))
+
+	counts := make(map[int]int)
+	for range 2000 {
+		sample := sh.Sample(10)
+		assert.Len(t, sample, 10)
+		assert.True(t, slices.IsSortedFunc(sample, func(a, b Entry[int, int]) int { return a.Key - b.Key }))
+		for i, e := range sample {
+			assert.NotZero(t, e.Key%3)
+			assert.Equal(t, e.Key*e.Key, e.Value)
+			if i > 0 {
+				assert.NotEqual(t, sample[i-1].Key, e.Key)
+			}
+			counts[e.Key]++
+		}
+	}
+	// Each of the 66 live keys is expected 2000*10/66 ≈ 303 times.
+	assert.Len(t, counts, 66)
+	for key, n := range counts {
+		assert.InDelta(t, 303, n, 100, "key %d", key)
+	}
+}
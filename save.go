@@ -0,0 +1,453 @@
+package skiphash
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Snapshot stream layout, all integers as uvarints:
+//
+//	magic "SKHS" | format | value schema | key codec | value codec |
+//	compression | sealed | count |
+//	count × (len | key bytes | len | value bytes | expiry)
+//
+// Codec and compression names are length-prefixed strings. Everything after
+// the sealed flag is compressed with the named compressor, unless the name
+// is empty, and then sealed in chunks if the flag is 1. Records are in key
+// order; expiry is the TTL deadline in Unix nanoseconds, or 0 for none.
+// Format 1 streams stop before the compression name, format 2 streams
+// before the sealed flag, and format 3 records lack the expiry.
+const (
+	snapshotMagic   = "SKHS"
+	snapshotFormat  = 4
+	maxSnapshotItem = 1 << 30
+)
+
+// SaveTo streams the entries live at the moment of the call to w in key
+// order, with their TTL deadlines. Like a range, it registers a version with the range coordinator
+// and reads one node at a time, so writers are not blocked while it runs
+// and no copy of the map is built in memory.
+func (sh *SkipHash[K, V]) SaveTo(w io.Writer) error {
+	sh.unsupportedInFineGrained()
+	sh.faultInAll()
+	keys, values := sh.keyCodec, sh.valueCodec
+
+	sh.mu.Lock()
+	ver := sh.rqc.onRangeLocked()
+	count := sh.len
+	sh.mu.Unlock()
+	defer func() {
+		sh.mu.Lock()
+		sh.rqc.afterRangeLocked(sh, ver)
+		sh.mu.Unlock()
+	}()
+
+	bw := bufio.NewWriter(w)
+	out, err := sh.newSnapshotWriter(bw, keys, values, count)
+	if err != nil {
+		return err
+	}
+
+	var scratch []byte
+	written := 0
+	sh.walkEntriesAtVersion(sh.head, nil, ver, func(key K, value V, expiry int64) bool {
+		scratch, err = appendRecord(scratch[:0], keys, values, key, value)
+		if err != nil {
+			return false
+		}
+		_, err = out.Write(binary.AppendUvarint(scratch, uint64(expiry)))
+		written++
+		return err == nil
+	})
+	if err != nil {
+		return err
+	}
+	if written != count {
+		return fmt.Errorf("skiphash: snapshot wrote %d of %d entries", written, count)
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// LoadFrom replaces the contents of sh with a stream written by SaveTo. The
+// whole stream is read and validated before sh changes, so a failed load
+// leaves sh untouched. Saved TTL deadlines are restored, and entries whose
+// deadline has passed are not loaded. Values saved under an older schema
+// version are upgraded with the migrations registered by WithValueMigration
+// when they are first accessed; see there. With a quota, a weight limit or
+// an entry cap, which must see each value, they are upgraded while loading.
+func (sh *SkipHash[K, V]) LoadFrom(r io.Reader) error {
+	if sh.compare == nil {
+		return ErrUninitialized
+	}
+	sh.unsupportedInFineGrained()
+	keys, values := sh.keyCodec, sh.valueCodec
+	br := bufio.NewReader(r)
+
+	magic := make([]byte, len(snapshotMagic))
+	if _, err := io.ReadFull(br, magic); err != nil || string(magic) != snapshotMagic {
+		return fmt.Errorf("%w: missing header", ErrBadSnapshot)
+	}
+	format, err := binary.ReadUvarint(br)
+	if err != nil {
+		return snapshotReadError(err)
+	}
+	if format < 1 || format > snapshotFormat {
+		return fmt.Errorf("%w: unknown format %d", ErrBadSnapshot, format)
+	}
+	schema, err := binary.ReadUvarint(br)
+	if err != nil {
+		return snapshotReadError(err)
+	}
+	if int(schema) > sh.valueSchema {
+		return fmt.Errorf("%w: value schema %d is newer than %d", ErrBadSnapshot, schema, sh.valueSchema)
+	}
+	for _, want := range []string{keys.name, values.name} {
+		name, err := readChunk(br)
+		if err != nil {
+			return snapshotReadError(err)
+		}
+		if string(name) != want {
+			return fmt.Errorf("%w: codec %q, want %q", ErrBadSnapshot, name, want)
+		}
+	}
+	var compressor Compressor
+	if format > 1 {
+		name, err := readChunk(br)
+		if err != nil {
+			return snapshotReadError(err)
+		}
+		if compressor, err = sh.decompressor(string(name)); err != nil {
+			return err
+		}
+	}
+	var sealed uint64
+	if format > 2 {
+		if sealed, err = binary.ReadUvarint(br); err != nil {
+			return snapshotReadError(err)
+		}
+	}
+	switch {
+	case sealed != 0 && sh.cipher == nil:
+		return fmt.Errorf("%w: encrypted snapshot needs WithSnapshotCipher", ErrBadSnapshot)
+	case sealed == 0 && sh.cipher != nil:
+		return fmt.Errorf("%w: snapshot is not encrypted", ErrBadSnapshot)
+	case sealed != 0:
+		br = bufio.NewReader(newSealReader(sh.cipher, br))
+	}
+	if compressor != nil {
+		zr, err := compressor.NewReader(br)
+		if err != nil {
+			return fmt.Errorf("%w: %s: %w", ErrBadSnapshot, compressor.Name(), err)
+		}
+		defer zr.Close()
+		br = bufio.NewReader(zr)
+	}
+	count, err := binary.ReadUvarint(br)
+	if err != nil {
+		return snapshotReadError(err)
+	}
+
+	migrate := int(schema) < sh.valueSchema
+	lazy := migrate && sh.quota == nil && sh.maxWeight <= 0 && sh.maxEntries <= 0
+	entries := make([]Entry[K, V], 0, min(count, 1<<16))
+	var expiring []Entry[K, int64]
+	var raws [][]byte
+	var expiries []int64
+	now := time.Now().UnixNano()
+	for i := uint64(0); i < count; i++ {
+		kb, err := readChunk(br)
+		if err != nil {
+			return snapshotReadError(err)
+		}
+		vb, err := readChunk(br)
+		if err != nil {
+			return snapshotReadError(err)
+		}
+		key, err := keys.decode(kb)
+		if err != nil {
+			return fmt.Errorf("%w: entry %d key: %w", ErrBadSnapshot, i, err)
+		}
+		var value V
+		switch {
+		case lazy:
+		case migrate:
+			value, err = sh.migrateValue(int(schema), vb)
+		default:
+			value, err = values.decode(vb)
+		}
+		if err != nil {
+			return fmt.Errorf("%w: entry %d value: %w", ErrBadSnapshot, i, err)
+		}
+		key = sh.normalizeKey(key)
+		if n := len(entries); n > 0 && sh.compare(entries[n-1].Key, key) >= 0 {
+			return fmt.Errorf("%w: entry %d (key %v)", ErrUnsorted, i, key)
+		}
+		var expiry uint64
+		if format > 3 {
+			if expiry, err = binary.ReadUvarint(br); err != nil {
+				return snapshotReadError(err)
+			}
+		}
+		if expiry != 0 {
+			if int64(expiry) <= now {
+				continue
+			}
+			expiring = append(expiring, Entry[K, int64]{Key: key, Value: int64(expiry)})
+		}
+		entries = append(entries, Entry[K, V]{Key: key, Value: value})
+		if lazy {
+			raws = append(raws, vb)
+			expiries = append(expiries, int64(expiry))
+		}
+	}
+	// Decompressors verify their checksum at the end of the stream, and a
+	// sealed stream that was cut short lacks its final chunk.
+	if compressor != nil || sealed != 0 {
+		if _, err := br.ReadByte(); err == nil {
+			return fmt.Errorf("%w: data after the last entry", ErrBadSnapshot)
+		} else if err != io.EOF {
+			return snapshotReadError(err)
+		}
+	}
+
+	sh.mu.Lock()
+	defer sh.unlock()
+	sh.beginBatchLocked()
+	defer sh.endBatchLocked()
+	if lazy {
+		if err := sh.replaceAllLocked(nil); err != nil {
+			return err
+		}
+		sh.keepUnmigratedLocked(int(schema), keysOf(entries), raws, expiries)
+		return nil
+	}
+	if err := sh.replaceAllLocked(entries); err != nil {
+		return err
+	}
+	for _, e := range expiring {
+		sh.scheduleLocked(e.Key, time.Unix(0, e.Value))
+	}
+	return nil
+}
+
+// newSnapshotWriter writes the header of a snapshot of count entries to w
+// and returns the writer for the records, which compresses and seals them as
+// configured. Closing it ends the stream but does not close w.
+func (sh *SkipHash[K, V]) newSnapshotWriter(w io.Writer, keys codec[K], values codec[V], count int) (io.WriteCloser, error) {
+	var name string
+	if sh.compressor != nil {
+		name = sh.compressor.Name()
+	}
+	var buf []byte
+	buf = append(buf, snapshotMagic...)
+	buf = binary.AppendUvarint(buf, snapshotFormat)
+	buf = binary.AppendUvarint(buf, uint64(sh.valueSchema))
+	buf = appendChunk(buf, []byte(keys.name))
+	buf = appendChunk(buf, []byte(values.name))
+	buf = appendChunk(buf, []byte(name))
+	var out io.WriteCloser = nopWriteCloser{w}
+	if sh.cipher != nil {
+		buf = binary.AppendUvarint(buf, 1)
+		out = newSealWriter(sh.cipher, w)
+	} else {
+		buf = binary.AppendUvarint(buf, 0)
+	}
+	if _, err := w.Write(buf); err != nil {
+		return nil, err
+	}
+
+	if sh.compressor != nil {
+		zw, err := sh.compressor.NewWriter(out)
+		if err != nil {
+			return nil, err
+		}
+		out = writeCloserChain{WriteCloser: zw, next: out}
+	}
+	_, err := out.Write(binary.AppendUvarint(nil, uint64(count)))
+	return out, err
+}
+
+// appendRecord appends the encoded key and value as two chunks.
+func appendRecord[K any, V any](buf []byte, keys codec[K], values codec[V], key K, value V) ([]byte, error) {
+	kb, err := keys.encode(key)
+	if err != nil {
+		return buf, fmt.Errorf("skiphash: encode key %v: %w", key, err)
+	}
+	vb, err := values.encode(value)
+	if err != nil {
+		return buf, fmt.Errorf("skiphash: encode value of key %v: %w", key, err)
+	}
+	return appendChunk(appendChunk(buf, kb), vb), nil
+}
+
+func appendChunk(buf, chunk []byte) []byte {
+	buf = binary.AppendUvarint(buf, uint64(len(chunk)))
+	return append(buf, chunk...)
+}
+
+func readChunk(r *bufio.Reader) ([]byte, error) {
+	n, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	if n > maxSnapshotItem {
+		return nil, fmt.Errorf("%w: item of %d bytes", ErrBadSnapshot, n)
+	}
+	chunk := make([]byte, n)
+	_, err = io.ReadFull(r, chunk)
+	return chunk, err
+}
+
+func snapshotReadError(err error) error {
+	if errors.Is(err, ErrBadSnapshot) {
+		return err
+	}
+	if errors.Is(err, io.EOF) {
+		err = io.ErrUnexpectedEOF
+	}
+	return fmt.Errorf("%w: %w", ErrBadSnapshot, err)
+}
+
+// Snapshot file layout: magic "SKHF" | format byte | SaveTo stream | stream
+// length as uint64 | CRC-32C of the stream as uint32, both little-endian.
+const (
+	snapshotFileMagic  = "SKHF"
+	snapshotFileFormat = 1
+	snapshotFileHeader = len(snapshotFileMagic) + 1
+	snapshotFileFooter = 8 + 4
+)
+
+var snapshotCRC = crc32.MakeTable(crc32.Castagnoli)
+
+// SaveToFile writes a snapshot to path so that a crash at any point leaves
+// either the previous file or the complete new one: it writes a temporary
+// file in the same directory, syncs it, renames it over path and syncs the
+// directory. The file carries a checksum that LoadFromFile verifies.
+func (sh *SkipHash[K, V]) SaveToFile(path string) error {
+	return writeFileAtomic(path, func(w io.Writer) error {
+		return writeSnapshotFile(w, sh.SaveTo)
+	})
+}
+
+// writeSnapshotFile frames the snapshot stream produced by save with the
+// file header and footer.
+func writeSnapshotFile(w io.Writer, save func(w io.Writer) error) error {
+	if _, err := w.Write(append([]byte(snapshotFileMagic), snapshotFileFormat)); err != nil {
+		return err
+	}
+	crc := crc32.New(snapshotCRC)
+	counter := &countingWriter{w: io.MultiWriter(w, crc)}
+	if err := save(counter); err != nil {
+		return err
+	}
+	footer := binary.LittleEndian.AppendUint64(nil, uint64(counter.n))
+	footer = binary.LittleEndian.AppendUint32(footer, crc.Sum32())
+	_, err := w.Write(footer)
+	return err
+}
+
+// LoadFromFile replaces the contents of sh with a file written by
+// SaveToFile. The checksum is verified in a first pass over the file, so a
+// truncated or corrupted file fails with ErrBadSnapshot and leaves sh
+// untouched.
+func (sh *SkipHash[K, V]) LoadFromFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	size := info.Size() - int64(snapshotFileHeader+snapshotFileFooter)
+	if size < 0 {
+		return fmt.Errorf("%w: file too short", ErrBadSnapshot)
+	}
+
+	header := make([]byte, snapshotFileHeader)
+	footer := make([]byte, snapshotFileFooter)
+	if _, err := f.ReadAt(header, 0); err != nil {
+		return err
+	}
+	if _, err := f.ReadAt(footer, int64(snapshotFileHeader)+size); err != nil {
+		return err
+	}
+	if string(header[:len(snapshotFileMagic)]) != snapshotFileMagic {
+		return fmt.Errorf("%w: missing file header", ErrBadSnapshot)
+	}
+	if format := header[len(snapshotFileMagic)]; format != snapshotFileFormat {
+		return fmt.Errorf("%w: unknown file format %d", ErrBadSnapshot, format)
+	}
+	if n := binary.LittleEndian.Uint64(footer); n != uint64(size) {
+		return fmt.Errorf("%w: stream of %d bytes, file holds %d", ErrBadSnapshot, n, size)
+	}
+
+	crc := crc32.New(snapshotCRC)
+	if _, err := io.Copy(crc, io.NewSectionReader(f, int64(snapshotFileHeader), size)); err != nil {
+		return err
+	}
+	if crc.Sum32() != binary.LittleEndian.Uint32(footer[8:]) {
+		return fmt.Errorf("%w: checksum mismatch", ErrBadSnapshot)
+	}
+	return sh.LoadFrom(io.NewSectionReader(f, int64(snapshotFileHeader), size))
+}
+
+// writeFileAtomic replaces path with what write produces, through a synced
+// temporary file that is renamed into place.
+func writeFileAtomic(path string, write func(w io.Writer) error) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	bw := bufio.NewWriter(tmp)
+	err = write(bw)
+	if err == nil {
+		err = bw.Flush()
+	}
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	err = d.Sync()
+	if closeErr := d.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
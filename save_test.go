@@ -0,0 +1,152 @@
+package skiphash
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSaveToLoadFromRoundTrip(t *testing.T) {
+	sh := New[int, string]()
+	for i := range 1000 {
+		sh.Store(i, strconv.Itoa(i))
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, sh.SaveTo(&buf))
+	require.Nil(t, sh.rqc.tail)
+
+	out := New[int, string]()
+	out.Store(-1, "stale")
+	require.NoError(t, out.LoadFrom(&buf))
+	require.Equal(t, sh.RangeAll(), out.RangeAll())
+	require.Equal(t, 1000, out.Len())
+}
+
+func TestSaveToLoadFromKeepsTTLs(t *testing.T) {
+	sh := New[int, int]()
+	sh.Store(1, 1)
+	sh.StoreTTL(2, 2, time.Hour)
+	sh.StoreTTL(3, 3, 20*time.Millisecond)
+
+	var buf bytes.Buffer
+	require.NoError(t, sh.SaveTo(&buf))
+	time.Sleep(30 * time.Millisecond)
+
+	out := New[int, int]()
+	require.NoError(t, out.LoadFrom(&buf))
+	require.Equal(t, []int{1, 2}, keysOf(out.RangeAll()), "entries past their deadline are not loaded")
+	_, ok := out.TTL(1)
+	require.False(t, ok)
+	ttl, ok := out.TTL(2)
+	require.True(t, ok)
+	require.Greater(t, ttl, 59*time.Minute)
+}
+
+func TestSaveToIsPointInTime(t *testing.T) {
+	sh := New[int, int]()
+	for i := range 100 {
+		sh.Store(i, i)
+	}
+
+	// Writes made while the stream is being produced are not part of it.
+	w := &hookWriter{onWrite: func() {
+		sh.Remove(50)
+		sh.Store(500, 500)
+	}}
+	require.NoError(t, sh.SaveTo(w))
+
+	out := New[int, int]()
+	require.NoError(t, out.LoadFrom(&w.buf))
+	require.Equal(t, 100, out.Len())
+	require.True(t, out.Contains(50))
+	require.False(t, out.Contains(500))
+}
+
+type hookWriter struct {
+	buf     bytes.Buffer
+	onWrite func()
+}
+
+func (w *hookWriter) Write(p []byte) (int, error) {
+	if w.onWrite != nil {
+		w.onWrite()
+		w.onWrite = nil
+	}
+	return w.buf.Write(p)
+}
+
+func TestLoadFromRejectsBadInput(t *testing.T) {
+	sh := New[int, int]()
+	sh.Store(1, 1)
+	var buf bytes.Buffer
+	require.NoError(t, sh.SaveTo(&buf))
+	data := buf.Bytes()
+
+	out := New[int, int]()
+	out.Store(7, 7)
+	require.ErrorIs(t, out.LoadFrom(bytes.NewReader(data[:len(data)-1])), ErrBadSnapshot)
+	require.ErrorIs(t, out.LoadFrom(bytes.NewReader([]byte("nope"))), ErrBadSnapshot)
+	require.ErrorIs(t, New[string, int]().LoadFrom(bytes.NewReader(data)), ErrBadSnapshot)
+	require.Equal(t, []Entry[int, int]{{Key: 7, Value: 7}}, out.RangeAll(), "failed loads leave the map untouched")
+}
+
+func TestLoadFromMigratesOldValues(t *testing.T) {
+	legacy := New[string, string]()
+	legacy.Store("a", "low")
+	var buf bytes.Buffer
+	require.NoError(t, legacy.SaveTo(&buf))
+
+	sh := New[string, string](WithValueMigration(0, 1, func(raw []byte) (string, error) {
+		return strings.ToUpper(string(raw)), nil
+	}))
+	require.NoError(t, sh.LoadFrom(bytes.NewReader(buf.Bytes())))
+	v, ok := sh.Get("a")
+	require.True(t, ok)
+	require.Equal(t, "LOW", v)
+
+	// A stream from a newer schema cannot be read back by the older map.
+	buf.Reset()
+	require.NoError(t, sh.SaveTo(&buf))
+	require.ErrorIs(t, legacy.LoadFrom(&buf), ErrBadSnapshot)
+}
+
+func TestSaveToFileLoadFromFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "map.snap")
+	sh := New[int, string]()
+	for i := range 500 {
+		sh.Store(i, strconv.Itoa(i))
+	}
+	require.NoError(t, sh.SaveToFile(path))
+	sh.Store(1000, "later")
+	require.NoError(t, sh.SaveToFile(path))
+	files, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 1, "no temporary files are left behind")
+
+	out := New[int, string]()
+	require.NoError(t, out.LoadFromFile(path))
+	require.Equal(t, sh.RangeAll(), out.RangeAll())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	for _, corrupt := range [][]byte{
+		data[:len(data)-3],
+		append(append([]byte(nil), data[:100]...), append([]byte{data[100] ^ 1}, data[101:]...)...),
+		append([]byte("XXXX"), data[4:]...),
+		data[:5],
+	} {
+		bad := filepath.Join(dir, "bad.snap")
+		require.NoError(t, os.WriteFile(bad, corrupt, 0o644))
+		require.ErrorIs(t, out.LoadFromFile(bad), ErrBadSnapshot)
+		require.Equal(t, 501, out.Len())
+	}
+	require.ErrorIs(t, out.LoadFromFile(filepath.Join(dir, "missing")), os.ErrNotExist)
+}
@@ -0,0 +1,153 @@
+package skiphash
+
+import (
+	"cmp"
+	"slices"
+)
+
+// secondaryIndex is kept in step with the primary by changedLocked, so it
+// reflects exactly the committed entries whenever the write lock is free.
+type secondaryIndex[K any, V any] interface {
+	applyLocked(c change[K, V])
+}
+
+// attrIndex maps each attribute to the primary keys of the entries it was
+// extracted from, in primary key order.
+type attrIndex[K any, V any, I cmp.Ordered] struct {
+	extract func(K, V) I
+	compare func(a, b K) int
+	byAttr  *SkipHash[I, []K]
+}
+
+func (ix *attrIndex[K, V, I]) applyLocked(c change[K, V]) {
+	switch c.kind {
+	case ChangeInsert:
+		ix.add(ix.extract(c.key, c.value), c.key)
+	case ChangeUpdate:
+		before, after := ix.extract(c.key, c.old), ix.extract(c.key, c.value)
+		if before != after {
+			ix.remove(before, c.key)
+			ix.add(after, c.key)
+		}
+	case ChangeRemove:
+		ix.remove(ix.extract(c.key, c.value), c.key)
+	}
+}
+
+func (ix *attrIndex[K, V, I]) add(attr I, key K) {
+	keys, _ := ix.byAttr.Get(attr)
+	i, found := slices.BinarySearchFunc(keys, key, ix.compare)
+	if !found {
+		ix.byAttr.Store(attr, slices.Insert(keys, i, key))
+	}
+}
+
+func (ix *attrIndex[K, V, I]) remove(attr I, key K) {
+	keys, _ := ix.byAttr.Get(attr)
+	i, found := slices.BinarySearchFunc(keys, key, ix.compare)
+	switch {
+	case !found:
+	case len(keys) == 1:
+		ix.byAttr.Remove(attr)
+	default:
+		ix.byAttr.Store(attr, slices.Delete(keys, i, i+1))
+	}
+}
+
+// AddIndex maintains a secondary index named name from the attribute that
+// extract derives from each entry to the keys of the entries that have it.
+// Existing entries are indexed immediately; afterwards every mutation
+// updates the index under the same lock, so GetByIndex and RangeByIndex
+// never observe it out of step with the SkipHash. extract must be pure.
+// AddIndex fails with ErrIndexExists if the name is taken.
+func AddIndex[I cmp.Ordered, K any, V any](sh *SkipHash[K, V], name string, extract func(K, V) I) error {
+	sh.unsupportedInFineGrained()
+	sh.faultInAll()
+	sh.mu.Lock()
+	defer sh.unlock()
+	if _, ok := sh.secondaries[name]; ok {
+		return ErrIndexExists
+	}
+	ix := &attrIndex[K, V, I]{extract: extract, compare: sh.compare, byAttr: New[I, []K]()}
+	for node := sh.head.next[0]; node != sh.tail; node = node.next[0] {
+		if node.rTime == 0 {
+			ix.add(extract(node.key, *node.value.Load()), node.key)
+		}
+	}
+	if sh.secondaries == nil {
+		sh.secondaries = make(map[string]secondaryIndex[K, V])
+	}
+	sh.secondaries[name] = ix
+	return nil
+}
+
+// GetByIndex returns the entries whose attribute in the index named name
+// equals attr, in key order. It panics if there is no such index with
+// attributes of type I.
+func GetByIndex[I cmp.Ordered, K any, V any](sh *SkipHash[K, V], name string, attr I) []Entry[K, V] {
+	return RangeByIndex(sh, name, attr, attr)
+}
+
+// RangeByIndex returns the entries whose attribute in the index named name
+// lies in [low, high], ordered by attribute and then by key. It panics if
+// there is no such index with attributes of type I.
+func RangeByIndex[I cmp.Ordered, K any, V any](sh *SkipHash[K, V], name string, low, high I) []Entry[K, V] {
+	sh.faultInAll()
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+	ix, ok := sh.secondaries[name].(*attrIndex[K, V, I])
+	if !ok {
+		panic("skiphash: no index " + name + " with this attribute type")
+	}
+	return ix.rangeLocked(sh, low, high)
+}
+
+// rangeLocked returns the entries with attributes in [low, high], ordered by
+// attribute and then by key.
+func (ix *attrIndex[K, V, I]) rangeLocked(sh *SkipHash[K, V], low, high I) []Entry[K, V] {
+	var out []Entry[K, V]
+	for _, e := range ix.byAttr.Range(low, high) {
+		for _, key := range e.Value {
+			if node, ok := sh.index.get(key); ok {
+				out = append(out, Entry[K, V]{Key: key, Value: *node.value.Load()})
+			}
+		}
+	}
+	return out
+}
+
+// valueOrder is the attrIndex kept by WithValueOrder, which indexes entries
+// by their value.
+type valueOrder[K any, V any] interface {
+	secondaryIndex[K, V]
+	rangeLocked(sh *SkipHash[K, V], low, high V) []Entry[K, V]
+}
+
+// WithValueOrder also keeps the entries ordered by value, so RangeByValue
+// can serve queries such as a leaderboard keyed by user and read by score.
+// Its type arguments must match the SkipHash. Every write pays an extra
+// O(log n) update.
+func WithValueOrder[K any, V cmp.Ordered]() Option {
+	return func(cfg *config) {
+		cfg.valueOrder = func(compare func(a, b K) int) valueOrder[K, V] {
+			return &attrIndex[K, V, V]{
+				extract: func(_ K, v V) V { return v },
+				compare: compare,
+				byAttr:  New[V, []K](),
+			}
+		}
+	}
+}
+
+// RangeByValue returns the entries whose value lies in [low, high], ordered
+// by value and then by key. It panics unless the SkipHash was created with
+// WithValueOrder.
+func (sh *SkipHash[K, V]) RangeByValue(low, high V) []Entry[K, V] {
+	if sh.byValue == nil {
+		panic("skiphash: RangeByValue requires WithValueOrder")
+	}
+	sh.faultInAll()
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+	return sh.byValue.rangeLocked(sh, low, high)
+}
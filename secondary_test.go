@@ -0,0 +1,75 @@
+package skiphash
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecondaryIndex(t *testing.T) {
+	sh := New[int, string]()
+	sh.Store(1, "bb")
+	sh.Store(2, "a")
+	sh.Store(3, "cc")
+
+	require.NoError(t, AddIndex(sh, "len", func(_ int, v string) int { return len(v) }))
+	assert.ErrorIs(t, AddIndex(sh, "len", func(_ int, v string) int { return len(v) }), ErrIndexExists)
+
+	assert.Equal(t, []Entry[int, string]{{1, "bb"}, {3, "cc"}}, GetByIndex(sh, "len", 2))
+
+	sh.Store(2, "dd")
+	sh.Remove(1)
+	sh.Insert(4, "eee")
+	assert.Equal(t, []Entry[int, string]{{2, "dd"}, {3, "cc"}}, GetByIndex(sh, "len", 2))
+	assert.Empty(t, GetByIndex(sh, "len", 1))
+	assert.Equal(t, []Entry[int, string]{{2, "dd"}, {3, "cc"}, {4, "eee"}}, RangeByIndex(sh, "len", 1, 3))
+
+	assert.Panics(t, func() { GetByIndex(sh, "missing", 1) })
+	assert.Panics(t, func() { GetByIndex(sh, "len", "2") })
+}
+
+func TestSecondaryIndexMatchesScan(t *testing.T) {
+	sh := New[int, int](WithRandSource(rand.NewSource(1)))
+	require.NoError(t, AddIndex(sh, "mod", func(_ int, v int) int { return v % 7 }))
+	r := rand.New(rand.NewSource(2))
+
+	for range 3000 {
+		key := r.Intn(300)
+		switch r.Intn(3) {
+		case 0:
+			sh.Remove(key)
+		default:
+			sh.Store(key, r.Intn(1000))
+		}
+	}
+
+	low, high := 2, 4
+	var want []Entry[int, int]
+	for _, e := range sh.RangeAll() {
+		if m := e.Value % 7; m >= low && m <= high {
+			want = append(want, e)
+		}
+	}
+	slices.SortStableFunc(want, func(a, b Entry[int, int]) int { return a.Value%7 - b.Value%7 })
+	assert.Equal(t, want, RangeByIndex(sh, "mod", low, high))
+}
+
+func TestRangeByValue(t *testing.T) {
+	sh := New[string, int](WithValueOrder[string, int]())
+	sh.Store("ann", 30)
+	sh.Store("bob", 10)
+	sh.Store("cat", 20)
+	sh.Store("dan", 20)
+	sh.Store("bob", 40)
+	sh.Remove("cat")
+
+	assert.Equal(t, []Entry[string, int]{{"dan", 20}, {"ann", 30}, {"bob", 40}}, sh.RangeByValue(0, 100))
+	assert.Equal(t, []Entry[string, int]{{"dan", 20}}, sh.RangeByValue(10, 25))
+	assert.Empty(t, sh.RangeByValue(50, 100))
+
+	assert.Panics(t, func() { New[string, int]().RangeByValue(0, 1) })
+	assert.Panics(t, func() { New[string, int](WithValueOrder[int, int]()) })
+}
@@ -0,0 +1,159 @@
+package skiphash
+
+import (
+	"cmp"
+	"unsafe"
+)
+
+// Set is an ordered set of keys. It reuses the SkipHash list and index with
+// a zero-size value, so it costs no more per key than the links themselves.
+type Set[K any] struct {
+	sh *SkipHash[K, struct{}]
+}
+
+// NewSet creates an empty Set ordered by cmp.Compare.
+func NewSet[K cmp.Ordered](opts ...Option) *Set[K] {
+	return &Set[K]{sh: New[K, struct{}](opts...)}
+}
+
+// NewSetFunc creates an empty Set for keys ordered by less; see NewFunc.
+func NewSetFunc[K any](less func(a, b K) bool, hash func(K) uint64, opts ...Option) *Set[K] {
+	return &Set[K]{sh: NewFunc[K, struct{}](less, hash, opts...)}
+}
+
+// Add inserts key and reports whether it was not already present.
+func (s *Set[K]) Add(key K) bool {
+	return s.sh.Insert(key, struct{}{})
+}
+
+// Remove deletes key and reports whether it was present.
+func (s *Set[K]) Remove(key K) bool {
+	return s.sh.Remove(key)
+}
+
+func (s *Set[K]) Contains(key K) bool {
+	return s.sh.Contains(key)
+}
+
+func (s *Set[K]) Len() int {
+	return s.sh.Len()
+}
+
+// Range returns the keys in [low, high] in order.
+func (s *Set[K]) Range(low, high K) []K {
+	return keysOf(s.sh.Range(low, high))
+}
+
+// Keys returns every key in order.
+func (s *Set[K]) Keys() []K {
+	return keysOf(s.sh.RangeAll())
+}
+
+// Union returns a new Set holding the keys present in s or other. Both sets
+// must use the same ordering; the result is configured like s.
+func (s *Set[K]) Union(other *Set[K]) *Set[K] {
+	return s.combine(other, true, true, true)
+}
+
+// Intersect returns a new Set holding the keys present in both s and other.
+func (s *Set[K]) Intersect(other *Set[K]) *Set[K] {
+	return s.combine(other, false, true, false)
+}
+
+// Difference returns a new Set holding the keys of s that are not in other.
+func (s *Set[K]) Difference(other *Set[K]) *Set[K] {
+	return s.combine(other, true, false, false)
+}
+
+func (s *Set[K]) combine(other *Set[K], onlyLeft, both, onlyRight bool) *Set[K] {
+	return &Set[K]{sh: s.sh.combine(other.sh, onlyLeft, both, onlyRight)}
+}
+
+func keysOf[K any, V any](entries []Entry[K, V]) []K {
+	keys := make([]K, len(entries))
+	for i, e := range entries {
+		keys[i] = e.Key
+	}
+	return keys
+}
+
+// rlockPair read-locks a and b in address order, so that two goroutines
+// combining the same pair in opposite roles cannot deadlock behind waiting
+// writers. It returns the matching unlock function.
+func rlockPair[K any, V any](a, b *SkipHash[K, V]) func() {
+	if a == b {
+		a.mu.RLock()
+		return a.mu.RUnlock
+	}
+	first, second := a, b
+	if uintptr(unsafe.Pointer(second)) < uintptr(unsafe.Pointer(first)) {
+		first, second = second, first
+	}
+	first.mu.RLock()
+	second.mu.RLock()
+	return func() {
+		second.mu.RUnlock()
+		first.mu.RUnlock()
+	}
+}
+
+// lockPair write-locks a and b, which must differ, in a fixed order and
+// returns the matching unlock, which also runs their pending hooks.
+func lockPair[K any, V any](a, b *SkipHash[K, V]) func() {
+	first, second := a, b
+	if uintptr(unsafe.Pointer(second)) < uintptr(unsafe.Pointer(first)) {
+		first, second = second, first
+	}
+	first.mu.Lock()
+	second.mu.Lock()
+	return func() {
+		second.unlock()
+		first.unlock()
+	}
+}
+
+// mergeWalkLocked walks the live nodes of a and b in a's order, calling fn
+// once per distinct key with the node from each side (nil when absent).
+func mergeWalkLocked[K any, V any](a, b *SkipHash[K, V], fn func(left, right *slNode[K, V])) {
+	left := a.firstLiveLocked()
+	right := b.firstLiveLocked()
+	for left != nil || right != nil {
+		switch {
+		case right == nil:
+			fn(left, nil)
+			left = a.nextLiveLocked(left)
+		case left == nil:
+			fn(nil, right)
+			right = b.nextLiveLocked(right)
+		default:
+			c := a.compare(left.key, right.key)
+			switch {
+			case c < 0:
+				fn(left, nil)
+				left = a.nextLiveLocked(left)
+			case c > 0:
+				fn(nil, right)
+				right = b.nextLiveLocked(right)
+			default:
+				fn(left, right)
+				left = a.nextLiveLocked(left)
+				right = b.nextLiveLocked(right)
+			}
+		}
+	}
+}
+
+// firstLiveLocked returns the first live node, or nil if there is none.
+func (sh *SkipHash[K, V]) firstLiveLocked() *slNode[K, V] {
+	return sh.nextLiveLocked(sh.head)
+}
+
+// nextLiveLocked returns the first live node after node, or nil.
+func (sh *SkipHash[K, V]) nextLiveLocked(node *slNode[K, V]) *slNode[K, V] {
+	for node = node.next[0]; node != sh.tail; node = node.next[0] {
+		if node.rTime == 0 {
+			return node
+		}
+	}
+	return nil
+}
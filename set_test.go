@@ -0,0 +1,77 @@
+package skiphash
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newIntSet(keys ...int) *Set[int] {
+	s := NewSet[int]()
+	for _, k := range keys {
+		s.Add(k)
+	}
+	return s
+}
+
+func TestSet(t *testing.T) {
+	s := newIntSet(5, 1, 3)
+	assert.False(t, s.Add(3))
+	assert.True(t, s.Contains(5))
+	assert.Equal(t, 3, s.Len())
+	assert.Equal(t, []int{1, 3, 5}, s.Keys())
+	assert.Equal(t, []int{3, 5}, s.Range(2, 9))
+	assert.True(t, s.Remove(3))
+	assert.False(t, s.Remove(3))
+	assert.Equal(t, []int{1, 5}, s.Keys())
+}
+
+func TestSetAlgebra(t *testing.T) {
+	a := newIntSet(1, 2, 3, 4, 5)
+	b := newIntSet(4, 5, 6, 7)
+	a.Remove(2)
+
+	assert.Equal(t, []int{1, 3, 4, 5, 6, 7}, a.Union(b).Keys())
+	assert.Equal(t, []int{4, 5}, a.Intersect(b).Keys())
+	assert.Equal(t, []int{1, 3}, a.Difference(b).Keys())
+	assert.Equal(t, []int{6, 7}, b.Difference(a).Keys())
+	assert.Equal(t, []int{1, 3, 4, 5}, a.Union(a).Keys())
+	assert.Empty(t, a.Intersect(NewSet[int]()).Keys())
+
+	u := a.Union(b)
+	u.Add(100)
+	assert.False(t, a.Contains(100), "results are independent sets")
+	checkSpans(t, u.sh)
+}
+
+func TestSetAlgebraKeepsOrdering(t *testing.T) {
+	a := NewSet[int](WithDescending())
+	b := NewSet[int](WithDescending())
+	for _, k := range []int{1, 3, 5} {
+		a.Add(k)
+		b.Add(k + 1)
+	}
+	assert.Equal(t, []int{6, 5, 4, 3, 2, 1}, a.Union(b).Keys())
+}
+
+func TestSetAlgebraConcurrent(t *testing.T) {
+	a := newIntSet(1, 2, 3)
+	b := newIntSet(2, 3, 4)
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Go(func() {
+			for j := range 200 {
+				if i%2 == 0 {
+					a.Union(b)
+					a.Add(j)
+				} else {
+					b.Intersect(a)
+					b.Add(j)
+				}
+			}
+		})
+	}
+	wg.Wait()
+	assert.Equal(t, 200, a.Len())
+}
@@ -0,0 +1,378 @@
+package skiphash
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Union returns a new SkipHash holding the entries of sh and other; for a
+// key in both, sh's value wins. Both must use the same ordering; the result
+// is configured like sh, without its hooks or WAL. Like the Set operations,
+// it merges the two base levels in one pass and links the result bottom-up.
+func (sh *SkipHash[K, V]) Union(other *SkipHash[K, V]) *SkipHash[K, V] {
+	return sh.combine(other, true, true, true)
+}
+
+// Intersect returns a new SkipHash holding the entries of sh whose keys are
+// also in other.
+func (sh *SkipHash[K, V]) Intersect(other *SkipHash[K, V]) *SkipHash[K, V] {
+	return sh.combine(other, false, true, false)
+}
+
+// Subtract returns a new SkipHash holding the entries of sh whose keys are
+// not in other.
+func (sh *SkipHash[K, V]) Subtract(other *SkipHash[K, V]) *SkipHash[K, V] {
+	return sh.combine(other, true, false, false)
+}
+
+// combine merges the base levels of sh and other, keeping entries found only
+// in sh, in both, or only in other as requested. Values come from sh when a
+// key is in both. Options of the result that reject inserts, such as quotas,
+// may drop entries.
+func (sh *SkipHash[K, V]) combine(other *SkipHash[K, V], onlyLeft, both, onlyRight bool) *SkipHash[K, V] {
+	sh.unsupportedInFineGrained()
+	other.unsupportedInFineGrained()
+	sh.faultInAll()
+	other.faultInAll()
+	unlock := rlockPair(sh, other)
+	entries := make([]Entry[K, V], 0, sh.len)
+	mergeWalkLocked(sh, other, func(left, right *slNode[K, V]) {
+		switch {
+		case left != nil && right != nil:
+			if both {
+				entries = append(entries, Entry[K, V]{Key: left.key, Value: *left.value.Load()})
+			}
+		case left != nil:
+			if onlyLeft {
+				entries = append(entries, Entry[K, V]{Key: left.key, Value: *left.value.Load()})
+			}
+		default:
+			if onlyRight {
+				entries = append(entries, Entry[K, V]{Key: right.key, Value: *right.value.Load()})
+			}
+		}
+	})
+	unlock()
+	return sh.newLoaded(entries)
+}
+
+// Split moves the entries at or after key into a new SkipHash, configured
+// like sh without its hooks or WAL, and returns sh, which keeps the entries
+// below key, and the new one. Each level is cut at key, so the moved entries
+// keep their nodes with their TTLs, versions and history, and Split costs
+// O(levels) plus moving each of their index entries and reporting their
+// removal to the indexes, watchers and WAL of sh. While a range scan or
+// snapshot of sh is open, which the cut would disturb, the entries are
+// copied instead and start with new versions and no history.
+func (sh *SkipHash[K, V]) Split(key K) (*SkipHash[K, V], *SkipHash[K, V]) {
+	sh.unsupportedInFineGrained()
+	key = sh.normalizeKey(key)
+	right := sh.newEmptyLike()
+	unlock := lockPair(sh, right)
+	defer unlock()
+	sh.faultInLocked(key, key)
+	sh.beginBatchLocked()
+	defer sh.endBatchLocked()
+
+	if sh.rqc.tail != nil {
+		sh.splitCopyLocked(key, right)
+	} else {
+		sh.splitLocked(key, right)
+	}
+	sh.moveSegmentsLocked(key, right)
+	return sh, right
+}
+
+// splitLocked cuts every level before the first node at or after key and
+// hangs the rest on right. No range operation is open, so the only
+// tombstones still linked are those kept by WithHistory, and they move with
+// their keys.
+func (sh *SkipHash[K, V]) splitLocked(key K, right *SkipHash[K, V]) {
+	preds := make([]*slNode[K, V], sh.maxLevel)
+	ranks := make([]int, sh.maxLevel)
+	cur, rank := sh.head, 0
+	for level := sh.maxLevel - 1; level >= 0; level-- {
+		for next := cur.next[level]; next != sh.tail && sh.compare(next.key, key) < 0; next = cur.next[level] {
+			rank += cur.span[level]
+			cur = next
+		}
+		preds[level], ranks[level] = cur, rank
+	}
+	left := ranks[0]
+	for level, pred := range preds {
+		if succ := pred.next[level]; succ != sh.tail {
+			last := sh.tail.prev[level]
+			right.head.next[level], succ.prev[level] = succ, right.head
+			last.next[level], right.tail.prev[level] = right.tail, last
+			pred.next[level], sh.tail.prev[level] = sh.tail, pred
+		}
+		below := left - ranks[level]
+		right.head.span[level] = pred.span[level] - below
+		pred.span[level] = below
+	}
+	for level, pred := range preds {
+		for i := range sh.augs {
+			sh.augs[i].refresh(sh, pred, level)
+			right.augs[i].refresh(right, right.head, level)
+		}
+	}
+
+	right.rqc.counter, right.writeSeq = sh.rqc.counter, sh.writeSeq
+	for node := right.head.next[0]; node != right.tail; node = node.next[0] {
+		if node.rTime != 0 {
+			continue
+		}
+		k, value, at := node.key, *node.value.Load(), node.expiry.at
+		sh.index.delete(k)
+		right.index.set(k, node)
+		sh.weight -= sh.weigh(k, value)
+		right.weight += right.weigh(k, value)
+		if sh.quota != nil {
+			sh.quota.removed(k)
+			right.quota.added(k)
+		}
+		if at != 0 {
+			sh.unscheduleLocked(node)
+		}
+		sh.changedLocked(change[K, V]{kind: ChangeRemove, key: k, value: value, expiry: at})
+		right.changedLocked(change[K, V]{kind: ChangeInsert, key: k, value: value})
+		if at != 0 {
+			right.scheduleLocked(k, time.Unix(0, at))
+		}
+		if right.trackVersions {
+			right.versionLog = append(right.versionLog, versionRecord[K, V]{ver: node.version, node: node})
+		}
+	}
+	slices.SortFunc(right.versionLog, func(a, b versionRecord[K, V]) int { return cmp.Compare(a.ver, b.ver) })
+	right.len = sh.len - left
+	sh.len = left
+
+	kept := sh.retained[:0]
+	for _, node := range sh.retained {
+		if sh.compare(node.key, key) < 0 {
+			kept = append(kept, node)
+		} else {
+			right.retained = append(right.retained, node)
+		}
+	}
+	clear(sh.retained[len(kept):])
+	sh.retained = kept
+	if sh.fingers {
+		sh.finger.Store(nil)
+	}
+}
+
+// splitCopyLocked moves the live entries at or after key to right by
+// inserting copies there and removing them from sh.
+func (sh *SkipHash[K, V]) splitCopyLocked(key K, right *SkipHash[K, V]) {
+	var moved []*slNode[K, V]
+	var entries []Entry[K, V]
+	for node := sh.lowerBoundLocked(key); node != sh.tail; node = node.next[0] {
+		if node.rTime == 0 {
+			moved = append(moved, node)
+			entries = append(entries, Entry[K, V]{Key: node.key, Value: *node.value.Load()})
+		}
+	}
+	_ = right.loadSortedLocked(entries)
+	for _, node := range moved {
+		k, at := node.key, node.expiry.at
+		sh.removeLocked(node)
+		if at != 0 {
+			right.scheduleLocked(k, time.Unix(0, at))
+		}
+	}
+}
+
+// moveSegmentsLocked hands the spilled segments at or after key to right.
+// None straddles key once it has been faulted in.
+func (sh *SkipHash[K, V]) moveSegmentsLocked(key K, right *SkipHash[K, V]) {
+	if sh.tier == nil {
+		return
+	}
+	kept := sh.tier.segments[:0]
+	for _, seg := range sh.tier.segments {
+		if sh.compare(seg.first, key) < 0 {
+			kept = append(kept, seg)
+			continue
+		}
+		right.tier.segments = append(right.tier.segments, seg)
+		sh.tier.spilled -= seg.count
+		right.tier.spilled += seg.count
+	}
+	clear(sh.tier.segments[len(kept):])
+	sh.tier.segments = kept
+	sh.tier.pending.Store(int32(len(kept)))
+	right.tier.pending.Store(int32(len(right.tier.segments)))
+}
+
+// newLoaded returns a SkipHash like sh, without hooks, holding sorted.
+func (sh *SkipHash[K, V]) newLoaded(sorted []Entry[K, V]) *SkipHash[K, V] {
+	out := sh.newEmptyLike()
+	out.mu.Lock()
+	_ = out.loadSortedLocked(sorted)
+	out.unlock()
+	return out
+}
+
+// Append moves the entries of other, all of whose keys must sort after the
+// last key of sh, to the end of sh and leaves other empty; it fails with
+// ErrOverlap otherwise. Both must use the same ordering. The levels of other
+// are spliced onto the tail of sh, so Append costs O(levels) plus moving
+// each index entry and reporting each entry to the indexes, watchers and WAL
+// of both. The moved entries keep their TTLs, timestamps and access
+// statistics, but get new write versions in sh and lose their history,
+// which is tied to other's versions.
+//
+// When sh vets inserts, with a quota, a weight limit or an entry cap, or a
+// range scan or snapshot of other is open, the entries are inserted into sh
+// one by one in a Txn instead: if one is rejected, both are left unchanged
+// and the error is returned.
+func (sh *SkipHash[K, V]) Append(other *SkipHash[K, V]) error {
+	sh.unsupportedInFineGrained()
+	other.unsupportedInFineGrained()
+	if sh == other {
+		if sh.Len() == 0 {
+			return nil
+		}
+		return ErrOverlap
+	}
+	unlock := lockPair(sh, other)
+	defer unlock()
+	other.expireDueLocked(0)
+	if other.tier != nil {
+		other.loadSegmentsLocked(func(*segment[K]) bool { return true })
+	}
+
+	first := other.firstLiveLocked()
+	if first == nil {
+		return nil
+	}
+	if last, ok := sh.lastKeyLocked(); ok && sh.compare(last, first.key) >= 0 {
+		return ErrOverlap
+	}
+	sh.beginBatchLocked()
+	defer sh.endBatchLocked()
+	other.beginBatchLocked()
+	defer other.endBatchLocked()
+
+	if sh.quota != nil || sh.maxWeight > 0 || sh.maxEntries > 0 ||
+		other.rqc.tail != nil || other.maxLevel > sh.maxLevel || !sh.appendsLocked(first.key) {
+		return sh.appendCopyLocked(other)
+	}
+	sh.spliceLocked(other)
+	return nil
+}
+
+// lastKeyLocked returns the largest key of sh, live or spilled.
+func (sh *SkipHash[K, V]) lastKeyLocked() (K, bool) {
+	node := sh.tail.prev[0]
+	for node != sh.head && node.rTime != 0 {
+		node = node.prev[0]
+	}
+	key, ok := node.key, node != sh.head
+	if sh.tier != nil && len(sh.tier.segments) > 0 {
+		if last := sh.tier.segments[len(sh.tier.segments)-1].last; !ok || sh.compare(last, key) > 0 {
+			key, ok = last, true
+		}
+	}
+	return key, ok
+}
+
+// spliceLocked links the levels of other after the last node of sh at each
+// level and moves its entries over. No range operation of other is open, so
+// its only linked tombstones are those kept by WithHistory, which are
+// unlinked first.
+func (sh *SkipHash[K, V]) spliceLocked(other *SkipHash[K, V]) {
+	for _, node := range other.retained {
+		other.unstitchNodeLocked(node)
+	}
+	other.retained = nil
+
+	first, n := other.head.next[0], other.len
+	lasts := make([]*slNode[K, V], sh.maxLevel)
+	for level := range sh.maxLevel {
+		last := sh.tail.prev[level]
+		lasts[level] = last
+		if level >= other.maxLevel {
+			last.span[level] += n
+			continue
+		}
+		last.span[level] += other.head.span[level]
+		if head := other.head.next[level]; head != other.tail {
+			otherLast := other.tail.prev[level]
+			last.next[level], head.prev[level] = head, last
+			otherLast.next[level], sh.tail.prev[level] = sh.tail, otherLast
+			other.head.next[level], other.tail.prev[level] = other.tail, other.head
+		}
+		other.head.span[level] = 0
+	}
+
+	for node := first; node != sh.tail; node = node.next[0] {
+		key, value, at := node.key, *node.value.Load(), node.expiry.at
+		other.index.delete(key)
+		sh.index.set(key, node)
+		other.weight -= other.weigh(key, value)
+		sh.weight += sh.weigh(key, value)
+		if other.quota != nil {
+			other.quota.removed(key)
+		}
+		if at != 0 {
+			other.unscheduleLocked(node)
+		}
+		node.iTime = sh.rqc.onUpdateLocked()
+		node.writtenAt = node.iTime
+		node.history, node.aug, node.created = nil, nil, 0
+		updatedAt := node.updatedAt
+		sh.noteWriteLocked(node)
+		if updatedAt != 0 {
+			node.updatedAt = updatedAt
+		}
+		other.changedLocked(change[K, V]{kind: ChangeRemove, key: key, value: value, expiry: at})
+		sh.changedLocked(change[K, V]{kind: ChangeInsert, key: key, value: value})
+		if at != 0 {
+			sh.scheduleLocked(key, time.Unix(0, at))
+		}
+	}
+	sh.len += n
+	other.len = 0
+	other.versionLog = nil
+	if other.fingers {
+		other.finger.Store(nil)
+	}
+	for level, last := range lasts {
+		for _, aug := range sh.augs {
+			for node := last; node != sh.tail; node = node.next[level] {
+				aug.refresh(sh, node, level)
+			}
+		}
+	}
+}
+
+// appendCopyLocked inserts copies of the entries of other into sh in a Txn
+// and then removes them from other.
+func (sh *SkipHash[K, V]) appendCopyLocked(other *SkipHash[K, V]) error {
+	var moved []*slNode[K, V]
+	for node := other.firstLiveLocked(); node != nil; node = other.nextLiveLocked(node) {
+		moved = append(moved, node)
+	}
+	err := sh.txnLocked(func(*Txn[K, V]) error {
+		for _, node := range moved {
+			if err := sh.insertLocked(node.key, *node.value.Load()); err != nil {
+				return fmt.Errorf("key %v: %w", node.key, err)
+			}
+			if at := node.expiry.at; at != 0 {
+				sh.scheduleLocked(node.key, time.Unix(0, at))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, node := range moved {
+		other.removeLocked(node)
+	}
+	return nil
+}
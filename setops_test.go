@@ -0,0 +1,154 @@
+package skiphash
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnionIntersectSubtract(t *testing.T) {
+	a := New[int, string]()
+	a.Store(1, "a1")
+	a.Store(2, "a2")
+	a.Store(3, "a3")
+	b := New[int, string]()
+	b.Store(2, "b2")
+	b.Store(4, "b4")
+
+	union := a.Union(b)
+	require.Equal(t, []Entry[int, string]{{Key: 1, Value: "a1"}, {Key: 2, Value: "a2"}, {Key: 3, Value: "a3"}, {Key: 4, Value: "b4"}}, union.RangeAll())
+	checkSpans(t, union)
+
+	require.Equal(t, []Entry[int, string]{{Key: 2, Value: "a2"}}, a.Intersect(b).RangeAll())
+	require.Equal(t, []Entry[int, string]{{Key: 1, Value: "a1"}, {Key: 3, Value: "a3"}}, a.Subtract(b).RangeAll())
+	require.Equal(t, []Entry[int, string]{{Key: 4, Value: "b4"}}, b.Subtract(a).RangeAll())
+
+	// The results are independent of their inputs.
+	union.Store(9, "u")
+	require.False(t, a.Contains(9))
+	require.Equal(t, 3, a.Len())
+}
+
+func TestSetOpsKeepDescendingOrder(t *testing.T) {
+	a := New[int, int](WithDescending())
+	b := New[int, int](WithDescending())
+	for i := range 5 {
+		a.Store(i, i)
+		b.Store(i+3, i)
+	}
+	union := a.Union(b)
+	require.Equal(t, []int{7, 6, 5, 4, 3, 2, 1, 0}, keysOf(union.RangeAll()))
+	union.Store(10, 10)
+	require.Equal(t, 10, union.RangeAll()[0].Key)
+}
+
+func TestSkipHashSplit(t *testing.T) {
+	sh := New[int, string](WithDescending())
+	for i := range 10 {
+		sh.Store(i, strconv.Itoa(i))
+	}
+	sh.Remove(6)
+	sh.StoreTTL(8, "8", time.Hour)
+	sh.StoreTTL(2, "2", time.Hour)
+
+	low, high := sh.Split(5)
+	require.Same(t, sh, low)
+	require.Equal(t, []Entry[int, string]{{9, "9"}, {8, "8"}, {7, "7"}}, low.RangeAll())
+	require.Equal(t, []Entry[int, string]{{5, "5"}, {4, "4"}, {3, "3"}, {2, "2"}, {1, "1"}, {0, "0"}}, high.RangeAll())
+	checkSpans(t, low)
+	checkSpans(t, high)
+	require.False(t, sh.Contains(3))
+	require.Equal(t, 2, high.Rank(3))
+	require.Equal(t, 1, low.Rank(8))
+
+	ttl, ok := low.TTL(8)
+	require.True(t, ok)
+	require.Greater(t, ttl, 59*time.Minute)
+	ttl, ok = high.TTL(2)
+	require.True(t, ok)
+	require.Greater(t, ttl, 59*time.Minute)
+	_, ok = high.TTL(3)
+	require.False(t, ok)
+
+	// Both halves go on as ordinary maps.
+	low.Store(100, "x")
+	high.Store(-1, "y")
+	require.False(t, high.Contains(100))
+	require.Equal(t, 6, high.Rank(-1))
+	checkSpans(t, low)
+	checkSpans(t, high)
+
+	low, rest := low.Split(-5)
+	require.Equal(t, 4, low.Len())
+	require.Zero(t, rest.Len())
+}
+
+func TestSkipHashSplitDuringSnapshot(t *testing.T) {
+	sh := New[int, int]()
+	for i := range 10 {
+		sh.Store(i, i)
+	}
+	view := sh.Snapshot()
+	low, high := sh.Split(5)
+	require.Equal(t, 5, low.Len())
+	require.Equal(t, []int{5, 6, 7, 8, 9}, keysOf(high.RangeAll()))
+	checkSpans(t, low)
+	checkSpans(t, high)
+
+	// The view still sees the entries that have moved out.
+	require.Len(t, view.Range(0, 9), 10)
+	view.Close()
+	checkSpans(t, low)
+}
+
+func TestSkipHashAppend(t *testing.T) {
+	sh := New[int, int]()
+	for i := range 5 {
+		sh.Store(i, i)
+	}
+	sh.Store(10, 10)
+	sh.Remove(10)
+	other := New[int, int]()
+	for i := 5; i < 10; i++ {
+		other.Store(i, i)
+	}
+
+	other.StoreTTL(7, 7, time.Hour)
+
+	require.NoError(t, sh.Append(other))
+	require.Equal(t, 10, sh.Len())
+	require.Zero(t, other.Len())
+	require.Empty(t, other.RangeAll())
+	ttl, ok := sh.TTL(7)
+	require.True(t, ok)
+	require.Greater(t, ttl, 59*time.Minute)
+	for i, e := range sh.RangeAll() {
+		require.Equal(t, Entry[int, int]{i, i}, e)
+		require.Equal(t, i, sh.Rank(i))
+	}
+	checkSpans(t, sh)
+
+	// other can be refilled and appended again.
+	other.Store(20, 20)
+	require.NoError(t, sh.Append(other))
+	require.Equal(t, 10, sh.Rank(20))
+	checkSpans(t, sh)
+	checkSpans(t, other)
+
+	other.Store(3, 3)
+	require.ErrorIs(t, sh.Append(other), ErrOverlap)
+	require.NoError(t, sh.Append(New[int, int]()))
+	require.Equal(t, 11, sh.Len())
+
+	limited := New[int, int](WithQuota(func(k int) int { return k / 100 }, map[int]int{0: 3}))
+	limited.Store(0, 0)
+	other = New[int, int]()
+	for i := 5; i < 10; i++ {
+		other.Store(i, i)
+	}
+	require.ErrorIs(t, limited.Append(other), ErrQuotaExceeded)
+	require.Equal(t, []Entry[int, int]{{0, 0}}, limited.RangeAll())
+	require.Equal(t, 5, other.Len())
+}
@@ -0,0 +1,108 @@
+package skiphash
+
+import "reflect"
+
+// OrderedMap is the subset of the SkipHash API that Shadow mirrors. Adapt
+// other ordered maps (a B-tree, a sorted slice, ...) to it to compare them
+// with a SkipHash.
+type OrderedMap[K any, V any] interface {
+	Get(key K) (V, bool)
+	Store(key K, value V) bool
+	Remove(key K) bool
+	Range(low, high K) []Entry[K, V]
+	Len() int
+}
+
+var _ OrderedMap[int, int] = (*SkipHash[int, int])(nil)
+
+// Divergence describes an operation whose result differed between the two
+// maps of a Shadow. Args holds the keys the operation was called with.
+type Divergence[K any, V any] struct {
+	Op        string
+	Args      []K
+	Primary   any
+	Candidate any
+}
+
+// Shadow applies every operation to a primary and a candidate OrderedMap,
+// returns the primary's results and reports any result the candidate
+// disagrees with. It is meant for validating a migration in production
+// before cutting over. The two maps are not updated atomically, so
+// concurrent writes to the same keys can be reported as divergences.
+type Shadow[K any, V any] struct {
+	primary   OrderedMap[K, V]
+	candidate OrderedMap[K, V]
+	equal     func(a, b V) bool
+	report    func(Divergence[K, V])
+}
+
+// NewShadow pairs primary with candidate. equal compares values and defaults
+// to reflect.DeepEqual, which is also used for keys; report is called
+// synchronously for every divergence.
+func NewShadow[K any, V any](primary, candidate OrderedMap[K, V], equal func(a, b V) bool, report func(Divergence[K, V])) *Shadow[K, V] {
+	if primary == nil || candidate == nil || report == nil {
+		panic("skiphash: NewShadow requires non-nil maps and report function")
+	}
+	if equal == nil {
+		equal = func(a, b V) bool { return reflect.DeepEqual(a, b) }
+	}
+	return &Shadow[K, V]{primary: primary, candidate: candidate, equal: equal, report: report}
+}
+
+func (s *Shadow[K, V]) Get(key K) (V, bool) {
+	v, ok := s.primary.Get(key)
+	cv, cok := s.candidate.Get(key)
+	if ok != cok || ok && !s.equal(v, cv) {
+		s.diverged("Get", []K{key}, Entry[K, V]{key, v}, Entry[K, V]{key, cv})
+	}
+	return v, ok
+}
+
+func (s *Shadow[K, V]) Store(key K, value V) bool {
+	inserted := s.primary.Store(key, value)
+	if cinserted := s.candidate.Store(key, value); inserted != cinserted {
+		s.diverged("Store", []K{key}, inserted, cinserted)
+	}
+	return inserted
+}
+
+func (s *Shadow[K, V]) Remove(key K) bool {
+	removed := s.primary.Remove(key)
+	if cremoved := s.candidate.Remove(key); removed != cremoved {
+		s.diverged("Remove", []K{key}, removed, cremoved)
+	}
+	return removed
+}
+
+func (s *Shadow[K, V]) Range(low, high K) []Entry[K, V] {
+	entries := s.primary.Range(low, high)
+	centries := s.candidate.Range(low, high)
+	if !s.entriesEqual(entries, centries) {
+		s.diverged("Range", []K{low, high}, entries, centries)
+	}
+	return entries
+}
+
+func (s *Shadow[K, V]) Len() int {
+	n := s.primary.Len()
+	if cn := s.candidate.Len(); n != cn {
+		s.diverged("Len", nil, n, cn)
+	}
+	return n
+}
+
+func (s *Shadow[K, V]) entriesEqual(a, b []Entry[K, V]) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !reflect.DeepEqual(a[i].Key, b[i].Key) || !s.equal(a[i].Value, b[i].Value) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Shadow[K, V]) diverged(op string, args []K, primary, candidate any) {
+	s.report(Divergence[K, V]{Op: op, Args: args, Primary: primary, Candidate: candidate})
+}
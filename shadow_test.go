@@ -0,0 +1,79 @@
+package skiphash
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// sliceMap is a sorted-slice OrderedMap whose Range wrongly excludes high.
+type sliceMap struct {
+	entries []Entry[int, string]
+}
+
+func (m *sliceMap) find(key int) (int, bool) {
+	return slices.BinarySearchFunc(m.entries, key, func(e Entry[int, string], k int) int {
+		return e.Key - k
+	})
+}
+
+func (m *sliceMap) Get(key int) (string, bool) {
+	if i, ok := m.find(key); ok {
+		return m.entries[i].Value, true
+	}
+	return "", false
+}
+
+func (m *sliceMap) Store(key int, value string) bool {
+	i, ok := m.find(key)
+	if ok {
+		m.entries[i].Value = value
+		return false
+	}
+	m.entries = slices.Insert(m.entries, i, Entry[int, string]{key, value})
+	return true
+}
+
+func (m *sliceMap) Remove(key int) bool {
+	i, ok := m.find(key)
+	if ok {
+		m.entries = slices.Delete(m.entries, i, i+1)
+	}
+	return ok
+}
+
+func (m *sliceMap) Range(low, high int) []Entry[int, string] {
+	i, _ := m.find(low)
+	j, _ := m.find(high)
+	return slices.Clone(m.entries[i:j])
+}
+
+func (m *sliceMap) Len() int {
+	return len(m.entries)
+}
+
+func TestShadowReportsDivergences(t *testing.T) {
+	var got []Divergence[int, string]
+	s := NewShadow[int, string](&sliceMap{}, New[int, string](), nil, func(d Divergence[int, string]) {
+		got = append(got, d)
+	})
+
+	assert.True(t, s.Store(1, "a"))
+	assert.True(t, s.Store(2, "b"))
+	assert.False(t, s.Store(2, "c"))
+	v, ok := s.Get(2)
+	assert.True(t, ok)
+	assert.Equal(t, "c", v)
+	assert.True(t, s.Remove(1))
+	assert.Equal(t, 1, s.Len())
+	assert.Empty(t, got)
+
+	entries := s.Range(0, 2)
+	assert.Empty(t, entries, "results come from the primary")
+	if assert.Len(t, got, 1) {
+		assert.Equal(t, "Range", got[0].Op)
+		assert.Equal(t, []int{0, 2}, got[0].Args)
+		assert.Equal(t, []Entry[int, string]{{2, "c"}}, got[0].Candidate)
+	}
+}
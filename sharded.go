@@ -0,0 +1,166 @@
+package skiphash
+
+import (
+	"cmp"
+	"hash/maphash"
+	"slices"
+)
+
+// Sharded partitions keys across several SkipHash instances so writers to
+// different shards do not contend on one mutex. Ordered reads merge the
+// shards; they are consistent per shard but not across shards.
+type Sharded[K any, V any] struct {
+	shards  []*SkipHash[K, V]
+	shardOf func(K) int
+	// bounds is set for range partitioning: shard i holds the keys below
+	// bounds[i] and at or above bounds[i-1].
+	bounds []K
+}
+
+// NewSharded spreads keys over n shards by hash. Every ordered read
+// consults all shards.
+func NewSharded[K cmp.Ordered, V any](n int, opts ...Option) *Sharded[K, V] {
+	if n <= 0 {
+		panic("skiphash: NewSharded requires at least one shard")
+	}
+	seed := maphash.MakeSeed()
+	s := &Sharded[K, V]{shards: newShards(New[K, V](opts...), n)}
+	s.shardOf = func(key K) int {
+		return int(maphash.Comparable(seed, key) % uint64(n))
+	}
+	return s
+}
+
+// NewShardedRange partitions the key space at bounds, which must be sorted
+// in the SkipHash order: len(bounds)+1 shards are created and ordered reads
+// only visit the shards their interval overlaps.
+func NewShardedRange[K cmp.Ordered, V any](bounds []K, opts ...Option) *Sharded[K, V] {
+	first := New[K, V](opts...)
+	if !slices.IsSortedFunc(bounds, first.compare) {
+		panic("skiphash: NewShardedRange requires sorted bounds")
+	}
+	s := &Sharded[K, V]{
+		shards: newShards(first, len(bounds)+1),
+		bounds: slices.Clone(bounds),
+	}
+	s.shardOf = func(key K) int {
+		i, found := slices.BinarySearchFunc(s.bounds, key, first.compare)
+		if found {
+			i++
+		}
+		return i
+	}
+	return s
+}
+
+// newShards returns first followed by n-1 empty instances like it, each with
+// its own random source.
+func newShards[K any, V any](first *SkipHash[K, V], n int) []*SkipHash[K, V] {
+	shards := make([]*SkipHash[K, V], n)
+	shards[0] = first
+	for i := 1; i < n; i++ {
+		shards[i] = first.newEmptyLike()
+	}
+	return shards
+}
+
+func (s *Sharded[K, V]) shard(key K) *SkipHash[K, V] {
+	return s.shards[s.shardOf(s.shards[0].normalizeKey(key))]
+}
+
+// Shards returns the number of shards.
+func (s *Sharded[K, V]) Shards() int {
+	return len(s.shards)
+}
+
+func (s *Sharded[K, V]) Get(key K) (V, bool) {
+	return s.shard(key).Get(key)
+}
+
+func (s *Sharded[K, V]) Contains(key K) bool {
+	return s.shard(key).Contains(key)
+}
+
+func (s *Sharded[K, V]) Insert(key K, value V) bool {
+	return s.shard(key).Insert(key, value)
+}
+
+func (s *Sharded[K, V]) Store(key K, value V) bool {
+	return s.shard(key).Store(key, value)
+}
+
+func (s *Sharded[K, V]) Remove(key K) bool {
+	return s.shard(key).Remove(key)
+}
+
+func (s *Sharded[K, V]) Len() int {
+	n := 0
+	for _, sh := range s.shards {
+		n += sh.Len()
+	}
+	return n
+}
+
+// Range returns the entries in [low, high] across all shards, in order.
+func (s *Sharded[K, V]) Range(low, high K) []Entry[K, V] {
+	from, to := s.shardSpan(low, high)
+	if from == to {
+		return nil
+	}
+	if s.bounds != nil {
+		var entries []Entry[K, V]
+		for _, sh := range s.shards[from:to] {
+			entries = append(entries, sh.Range(low, high)...)
+		}
+		return entries
+	}
+	parts := make([][]Entry[K, V], 0, len(s.shards))
+	for _, sh := range s.shards[from:to] {
+		parts = append(parts, sh.Range(low, high))
+	}
+	return s.merge(parts)
+}
+
+// RangeCount returns the number of live keys in [low, high] across shards.
+func (s *Sharded[K, V]) RangeCount(low, high K) int {
+	from, to := s.shardSpan(low, high)
+	n := 0
+	for _, sh := range s.shards[from:to] {
+		n += sh.RangeCount(low, high)
+	}
+	return n
+}
+
+// shardSpan returns the shards that can hold keys in [low, high].
+func (s *Sharded[K, V]) shardSpan(low, high K) (int, int) {
+	sh := s.shards[0]
+	low, high = sh.normalizeKey(low), sh.normalizeKey(high)
+	if sh.compare(low, high) > 0 {
+		return 0, 0
+	}
+	if s.bounds == nil {
+		return 0, len(s.shards)
+	}
+	return s.shardOf(low), s.shardOf(high) + 1
+}
+
+// merge combines sorted per-shard results; keys never repeat across shards.
+func (s *Sharded[K, V]) merge(parts [][]Entry[K, V]) []Entry[K, V] {
+	total := 0
+	for _, p := range parts {
+		total += len(p)
+	}
+	compare := s.shards[0].compare
+	out := make([]Entry[K, V], 0, total)
+	for len(out) < total {
+		best := -1
+		for i, p := range parts {
+			if len(p) > 0 && (best < 0 || compare(p[0].Key, parts[best][0].Key) < 0) {
+				best = i
+			}
+		}
+		out = append(out, parts[best][0])
+		parts[best] = parts[best][1:]
+	}
+	return out
+}
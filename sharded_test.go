@@ -0,0 +1,55 @@
+package skiphash
+
+import (
+	"math/rand"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShardedMatchesSingle(t *testing.T) {
+	maps := map[string]*Sharded[int, int]{
+		"hash":  NewSharded[int, int](4, WithRandSource(rand.NewSource(1))),
+		"range": NewShardedRange[int, int]([]int{100, 200, 300}),
+	}
+	for name, s := range maps {
+		t.Run(name, func(t *testing.T) {
+			ref := New[int, int]()
+			r := rand.New(rand.NewSource(7))
+			for range 2000 {
+				k := r.Intn(400)
+				if r.Intn(3) == 0 {
+					assert.Equal(t, ref.Remove(k), s.Remove(k))
+				} else {
+					assert.Equal(t, ref.Store(k, k*2), s.Store(k, k*2))
+				}
+			}
+			assert.Equal(t, ref.Len(), s.Len())
+			for _, bounds := range [][2]int{{0, 399}, {50, 250}, {100, 100}, {199, 201}, {300, 10}} {
+				assert.Equal(t, ref.Range(bounds[0], bounds[1]), s.Range(bounds[0], bounds[1]), "range %v", bounds)
+				assert.Equal(t, ref.RangeCount(bounds[0], bounds[1]), s.RangeCount(bounds[0], bounds[1]))
+			}
+			v, ok := s.Get(ref.RangeAll()[0].Key)
+			assert.True(t, ok)
+			assert.Equal(t, ref.RangeAll()[0].Value, v)
+		})
+	}
+}
+
+func TestShardedConcurrentWriters(t *testing.T) {
+	s := NewSharded[int, int](8)
+	var wg sync.WaitGroup
+	for w := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for k := w * 1000; k < (w+1)*1000; k++ {
+				s.Insert(k, k)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 8000, s.Len())
+	assert.Len(t, s.Range(0, 7999), 8000)
+}
@@ -2,8 +2,11 @@ package skiphash
 
 import (
 	"cmp"
+	"crypto/cipher"
+	"math/bits"
 	"math/rand"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -18,6 +21,45 @@ type config struct {
 	maxLevel      int
 	fastPathTries int
 	randSource    rand.Source
+	descending    bool
+	versionIndex  bool
+	tierDir       string
+	maxStaleness  time.Duration
+	historyDepth  int
+	historyRetain int
+	callerLabels  bool
+	maxEntries    int
+	eviction      EvictionPolicy
+	maxWeight     int64
+	concurrency   ConcurrencyMode
+	walDir        string
+	gcMaxDeferred int
+	gcMaxAge      time.Duration
+	nodePool      bool
+	arena         bool
+	autoLevel     bool
+	appendMode    bool
+	finger        bool
+	timestamps    bool
+	changelog     int
+	compressor    Compressor
+	cipher        cipher.AEAD
+
+	// Options generic over K or V are stored untyped and asserted by New
+	// once the type parameters are known.
+	quota         any // func() quotaTracker[K]
+	keyNormalizer any // func(K) K
+	buckets       any // func() bucketTracker[K]
+	weigher       any // func(K, V) int64
+	hooks         any // Hooks[K, V]
+	keyCodec      any // codec[K]
+	valueCodec    any // codec[V]
+	rangeStats    any // rangeStatsOps[V]
+	valueOrder    any // func(compare func(a, b K) int) valueOrder[K, V]
+	// valueMigrations holds valueMigration[V] values.
+	valueMigrations []any
+	// aggregates holds func(slot int) augmentation[K, V] values.
+	aggregates []any
 }
 
 func WithMaxLevel(level int) Option {
@@ -28,6 +70,24 @@ func WithMaxLevel(level int) Option {
 	}
 }
 
+// WithAutoLevel makes searches start at a level derived from the current
+// size instead of the WithMaxLevel cap, which then only bounds growth.
+func WithAutoLevel() Option {
+	return func(cfg *config) {
+		cfg.autoLevel = true
+	}
+}
+
+// WithAppendMode optimizes for keys that mostly arrive in increasing order,
+// such as timestamps: an insert past the current last key links in front of
+// the tail sentinel in O(levels) without searching. Other inserts search as
+// usual.
+func WithAppendMode() Option {
+	return func(cfg *config) {
+		cfg.appendMode = true
+	}
+}
+
 func WithFastPathTries(tries int) Option {
 	return func(cfg *config) {
 		if tries >= 0 {
@@ -36,6 +96,16 @@ func WithFastPathTries(tries int) Option {
 	}
 }
 
+// WithDescending reverses the key order. Every ordered operation then works
+// on the reversed list: Range(low, high) expects low to come first in the
+// descending order (low >= high by natural order), Ceil returns the closest
+// key at or below the argument, Succ moves towards smaller keys, and so on.
+func WithDescending() Option {
+	return func(cfg *config) {
+		cfg.descending = true
+	}
+}
+
 func WithRandSource(source rand.Source) Option {
 	return func(cfg *config) {
 		if source != nil {
@@ -44,48 +114,191 @@ func WithRandSource(source rand.Source) Option {
 	}
 }
 
-type Entry[K cmp.Ordered, V any] struct {
+type Entry[K any, V any] struct {
 	Key   K
 	Value V
 }
 
-type SkipHash[K cmp.Ordered, V any] struct {
+type SkipHash[K any, V any] struct {
 	mu sync.RWMutex
 
 	maxLevel      int
+	autoLevel     bool
+	appendMode    bool
+	fingers       bool
 	fastPathTries int
 	rng           *rand.Rand
 
-	index map[K]*slNode[K, V]
-	head  *slNode[K, V]
-	tail  *slNode[K, V]
-	len   int
+	compare func(a, b K) int
+	index   keyIndex[K, V]
+	head    *slNode[K, V]
+	tail    *slNode[K, V]
+	len     int
 
 	rqc *rangeCoordinator[K, V]
-}
 
-type slNode[K cmp.Ordered, V any] struct {
-	key    K
-	value  V
-	height uint8
+	// preds, succs and ranks are reused by every insert under the write lock.
+	preds, succs []*slNode[K, V]
+	ranks        []int
 
-	prev []*slNode[K, V]
-	next []*slNode[K, V]
+	quota     quotaTracker[K]
+	normalize func(K) K
+	buckets   bucketTracker[K]
+
+	migrations  map[int]valueMigration[V]
+	valueSchema int
+
+	writeSeq      uint64
+	trackVersions bool
+	timestamps    bool
+	versionLog    []versionRecord[K, V]
+
+	tier *tier[K, V]
+
+	maxStaleness time.Duration
+	eventual     eventualState[K, V]
+
+	historyDepth  int
+	historyRetain int
+	retained      []*slNode[K, V]
+
+	watchers *watchRegistry[K, V]
+
+	deadlines    *SkipHash[deadline, any] // values are *slNode[K, V]
+	deadlineSeq  uint64
+	nextDeadline atomic.Int64
+	janitor      janitorStats
+	snapshots    autoSnapshotState
+
+	callers *callerMetrics
+
+	// fine is set in FineGrained mode and then holds every entry.
+	fine *fineList[K, V]
+
+	epochs epochs[K, V]
+	arena  *nodeArena[K, V]
+
+	// augs maintain per-level summaries; stats is the one from
+	// WithRangeStats, if any.
+	augs  []augmentation[K, V]
+	stats *monoid[K, V, rangeStats[V]]
 
+	// finger is the node the last ordered search ended on, kept with
+	// WithFinger. Readers move it under the read lock; unlinking a node
+	// clears it under the write lock, so it is always stitched.
+	finger atomic.Pointer[slNode[K, V]]
+
+	maxEntries int
+	eviction   EvictionPolicy
+	maxWeight  int64
+	weigher    func(K, V) int64
+	weight     int64
+
+	hooks        *Hooks[K, V]
+	pendingHooks []change[K, V]
+	// txn is the running Txn, which holds back changedLocked until it
+	// commits. batchSeq is odd while a Txn or another write of several keys
+	// runs, batches counting the nested ones; see lookup.
+	txn      *Txn[K, V]
+	batchSeq atomic.Uint64
+	batches  int
+
+	// secondaries are the indexes added by AddIndex, by name; byValue is
+	// the one kept by WithValueOrder.
+	secondaries map[string]secondaryIndex[K, V]
+	byValue     valueOrder[K, V]
+
+	keyCodec   codec[K]
+	valueCodec codec[V]
+	compressor Compressor
+	cipher     cipher.AEAD
+	wal        *wal[K, V]
+	changelog  *changelog[K, V]
+	// appliedSeq is the last changelog Seq applied by ApplyChanges.
+	appliedSeq uint64
+
+	spawn     func(seed int64) *SkipHash[K, V]
+	cloneSeed int64
+	clones    atomic.Int64
+}
+
+// slNode keeps the fields touched by every base-level scan step (links, key
+// and removal time) at the front so they share the first cache line; the
+// coordinator and rank bookkeeping follow.
+type slNode[K any, V any] struct {
+	next []*slNode[K, V]
+	key  K
 	// iTime / rTime match the paper:
 	// - iTime: range version visible at insertion
 	// - rTime: 0 means logically present, otherwise logical removal version
-	iTime uint64
 	rTime uint64
+	iTime uint64
+	// value is read by lock-free readers through the index, so a write
+	// publishes a new copy instead of changing the old one in place.
+	value atomic.Pointer[V]
 
+	// version is the write sequence number of the last insert or update
+	// and created that of the insert. insertedAt and updatedAt are UnixNano
+	// times kept with WithEntryTimestamps.
+	version    uint64
+	created    uint64
+	insertedAt int64
+	updatedAt  int64
+	// lastAccess is the UnixNano time of the last access and hits the
+	// number of accesses, kept only when tiering or an eviction policy
+	// needs them.
+	lastAccess atomic.Int64
+	hits       atomic.Uint64
+
+	height     uint8
 	unstitched bool
+
+	prev []*slNode[K, V]
+	// span[i] counts the live nodes in (node, next[i]], which lets Rank and
+	// Select skip whole runs of the base level.
+	span []int
+
+	// writtenAt is the coordinator version of the last write to value;
+	// history holds superseded values when WithHistory is enabled.
+	writtenAt uint64
+	history   []pastValue[V]
+
+	// expiry is the TTL deadline; a zero at means the entry never expires.
+	expiry deadline
+
+	// aug holds one set of per-level summaries for each augmentation.
+	aug []any
 }
 
 func New[K cmp.Ordered, V any](opts ...Option) *SkipHash[K, V] {
+	return newSkipHash(cmp.Compare[K], newMapIndex[K, V](), opts)
+}
+
+// NewFunc creates a SkipHash for keys that are not cmp.Ordered, such as
+// time.Time, *big.Int or structs. less defines the key order and hash feeds
+// the point-lookup index; keys that compare equal must hash equally.
+func NewFunc[K any, V any](less func(a, b K) bool, hash func(K) uint64, opts ...Option) *SkipHash[K, V] {
+	if less == nil || hash == nil {
+		panic("skiphash: NewFunc requires non-nil less and hash functions")
+	}
+	compare := func(a, b K) int {
+		switch {
+		case less(a, b):
+			return -1
+		case less(b, a):
+			return 1
+		}
+		return 0
+	}
+	return newSkipHash(compare, newHashIndex[K, V](hash, compare), opts)
+}
+
+func newSkipHash[K any, V any](compare func(a, b K) int, index keyIndex[K, V], opts []Option) *SkipHash[K, V] {
 	cfg := config{
 		maxLevel:      DefaultMaxLevel,
 		fastPathTries: DefaultFastPathTries,
 		randSource:    rand.NewSource(time.Now().UnixNano()),
+		maxStaleness:  DefaultMaxStaleness,
 	}
 	for _, opt := range opts {
 		if opt != nil {
@@ -101,6 +314,10 @@ func New[K cmp.Ordered, V any](opts ...Option) *SkipHash[K, V] {
 	if cfg.randSource == nil {
 		cfg.randSource = rand.NewSource(time.Now().UnixNano())
 	}
+	baseCompare := compare
+	if cfg.descending {
+		compare = func(a, b K) int { return baseCompare(b, a) }
+	}
 
 	head := newSentinel[K, V](uint8(cfg.maxLevel))
 	tail := newSentinel[K, V](uint8(cfg.maxLevel))
@@ -109,92 +326,362 @@ func New[K cmp.Ordered, V any](opts ...Option) *SkipHash[K, V] {
 		tail.prev[level] = head
 	}
 
-	return &SkipHash[K, V]{
+	sh := &SkipHash[K, V]{
 		maxLevel:      cfg.maxLevel,
+		autoLevel:     cfg.autoLevel,
+		appendMode:    cfg.appendMode,
+		fingers:       cfg.finger,
 		fastPathTries: cfg.fastPathTries,
 		rng:           rand.New(cfg.randSource),
-		index:         make(map[K]*slNode[K, V]),
+		compare:       compare,
+		index:         index,
+		trackVersions: cfg.versionIndex,
+		timestamps:    cfg.timestamps,
+		maxStaleness:  cfg.maxStaleness,
+		historyDepth:  cfg.historyDepth,
+		historyRetain: cfg.historyRetain,
+		maxEntries:    cfg.maxEntries,
+		eviction:      cfg.eviction,
+		maxWeight:     cfg.maxWeight,
 		head:          head,
 		tail:          tail,
 		rqc:           newRangeCoordinator[K, V](),
+		preds:         make([]*slNode[K, V], cfg.maxLevel),
+		succs:         make([]*slNode[K, V], cfg.maxLevel),
+		ranks:         make([]int, cfg.maxLevel),
+	}
+	sh.rqc.gcMaxDeferred, sh.rqc.gcMaxAge = cfg.gcMaxDeferred, cfg.gcMaxAge
+	if cfg.nodePool {
+		sh.epochs.pool = newNodePool[K, V](cfg.maxLevel)
 	}
+	if cfg.arena {
+		sh.arena = &nodeArena[K, V]{}
+	}
+	if cfg.quota != nil {
+		sh.quota = typedOption[func() quotaTracker[K]](cfg.quota, "WithQuota")()
+	}
+	if cfg.buckets != nil {
+		sh.buckets = typedOption[func() bucketTracker[K]](cfg.buckets, "WithBucketVersions")()
+	}
+	if cfg.keyNormalizer != nil {
+		sh.normalize = typedOption[func(K) K](cfg.keyNormalizer, "WithKeyNormalizer")
+	}
+	if cfg.concurrency == FineGrained {
+		checkFineGrained(&cfg)
+		sh.fine = newFineList[K, V](compare, cfg.maxLevel)
+	}
+	if cfg.weigher != nil {
+		sh.weigher = typedOption[func(K, V) int64](cfg.weigher, "WithMaxWeight")
+	}
+	if cfg.callerLabels {
+		sh.callers = &callerMetrics{byLabel: make(map[string]*callerCounters)}
+	}
+	if cfg.hooks != nil {
+		hooks := typedOption[Hooks[K, V]](cfg.hooks, "WithHooks")
+		sh.hooks = &hooks
+	}
+	if cfg.rangeStats != nil {
+		ops := typedOption[rangeStatsOps[V]](cfg.rangeStats, "WithRangeStats")
+		sh.stats = &monoid[K, V, rangeStats[V]]{
+			slot:    len(sh.augs),
+			combine: ops.combine,
+			extract: func(_ K, v V) rangeStats[V] { return ops.leaf(v) },
+		}
+		sh.augs = append(sh.augs, sh.stats)
+	}
+	for _, agg := range cfg.aggregates {
+		newAug := typedOption[func(int) augmentation[K, V]](agg, "WithAggregate")
+		sh.augs = append(sh.augs, newAug(len(sh.augs)))
+	}
+	if cfg.changelog > 0 {
+		sh.changelog = &changelog[K, V]{retain: cfg.changelog}
+	}
+	if cfg.valueOrder != nil {
+		newOrder := typedOption[func(func(a, b K) int) valueOrder[K, V]](cfg.valueOrder, "WithValueOrder")
+		sh.byValue = newOrder(sh.compare)
+	}
+	sh.rebuildAugmentsLocked()
+	sh.applyValueMigrations(cfg.valueMigrations)
+	sh.keyCodec, sh.valueCodec = defaultCodec[K](), defaultCodec[V]()
+	if cfg.keyCodec != nil {
+		sh.keyCodec = typedOption[codec[K]](cfg.keyCodec, "WithKeyCodec")
+	}
+	if cfg.valueCodec != nil {
+		sh.valueCodec = typedOption[codec[V]](cfg.valueCodec, "WithValueCodec")
+	}
+	sh.compressor, sh.cipher = cfg.compressor, cfg.cipher
+	// Values LoadFrom leaves to migrate wait in segments, like spilled ones.
+	if cfg.tierDir != "" || sh.migrations != nil {
+		sh.tier = newTier[K, V](cfg.tierDir)
+	}
+	if cfg.walDir != "" {
+		sh.wal = openWAL(cfg.walDir, sh.keyCodec, sh.valueCodec, sh.cipher)
+	}
+
+	sh.cloneSeed = sh.rng.Int63()
+	sh.spawn = func(seed int64) *SkipHash[K, V] {
+		// Only the original logs to the WAL directory.
+		spawnOpts := append(opts[:len(opts):len(opts)], WithRandSource(rand.NewSource(seed)), func(cfg *config) {
+			cfg.walDir = ""
+		})
+		return newSkipHash(baseCompare, index.empty(), spawnOpts)
+	}
+	return sh
 }
 
-func newSentinel[K cmp.Ordered, V any](height uint8) *slNode[K, V] {
-	return &slNode[K, V]{
-		height: height,
-		prev:   make([]*slNode[K, V], height),
-		next:   make([]*slNode[K, V], height),
+// newEmptyLike returns an empty SkipHash built with the same ordering and
+// options as sh, except for hooks and the WAL. Each one gets its own random source,
+// seeded deterministically from sh's.
+func (sh *SkipHash[K, V]) newEmptyLike() *SkipHash[K, V] {
+	out := sh.spawn(sh.cloneSeed + sh.clones.Add(1))
+	out.hooks = nil
+	return out
+}
+
+// typedOption recovers the typed payload of a generic option, panicking when
+// the option was instantiated for different key or value types.
+func typedOption[T any](v any, name string) T {
+	t, ok := v.(T)
+	if !ok {
+		panic("skiphash: " + name + " type parameters do not match the SkipHash")
 	}
+	return t
+}
+
+func newSentinel[K any, V any](height uint8) *slNode[K, V] {
+	node := &slNode[K, V]{height: height}
+	node.initLinks()
+	return node
+}
+
+// initLinks allocates the next and prev pointers from one backing array so a
+// node costs a single link allocation and both directions stay adjacent.
+func (n *slNode[K, V]) initLinks() {
+	links := make([]*slNode[K, V], 2*int(n.height))
+	n.next = links[:n.height:n.height]
+	n.prev = links[n.height:]
+	n.span = make([]int, n.height)
 }
 
 func (sh *SkipHash[K, V]) Len() int {
+	if sh.fine != nil {
+		return int(sh.fine.len.Load())
+	}
+	sh.expireDue()
 	sh.mu.RLock()
 	defer sh.mu.RUnlock()
+	if sh.tier != nil {
+		return sh.len + sh.tier.spilled
+	}
 	return sh.len
 }
 
 func (sh *SkipHash[K, V]) Get(key K) (V, bool) {
+	key = sh.normalizeKey(key)
+	if sh.fine != nil {
+		return sh.fine.get(key)
+	}
+	sh.faultIn(key, key)
+	// The index is safe to read without the lock; see syncIndex. The pinned
+	// epoch keeps node from being recycled while touch uses it.
+	epoch := sh.epochs.pin()
+	defer sh.epochs.unpin(epoch)
+	node, value, ok := sh.lookup(key)
+	if ok {
+		sh.touch(node)
+	}
+	return value, ok
+}
+
+// lookup reads the node and value of key without the lock unless a batch
+// ran meanwhile: its writes are not complete until it ends, so the read is
+// then retried under the read lock, which waits for the batch to finish.
+func (sh *SkipHash[K, V]) lookup(key K) (*slNode[K, V], V, bool) {
+	if seq := sh.batchSeq.Load(); seq%2 == 0 {
+		node, value, ok := sh.loadNode(key)
+		if sh.batchSeq.Load() == seq {
+			return node, value, ok
+		}
+	}
 	sh.mu.RLock()
 	defer sh.mu.RUnlock()
-	node, ok := sh.index[key]
-	if !ok {
+	return sh.loadNode(key)
+}
+
+// beginBatchLocked starts a write of several keys that lock-free readers
+// must see all or none of; see lookup. Batches nest, and each one must be
+// ended by endBatchLocked before the lock is released.
+func (sh *SkipHash[K, V]) beginBatchLocked() {
+	if sh.batches == 0 {
+		sh.batchSeq.Add(1)
+	}
+	sh.batches++
+}
+
+func (sh *SkipHash[K, V]) endBatchLocked() {
+	sh.batches--
+	if sh.batches == 0 {
+		sh.batchSeq.Add(1)
+	}
+}
+
+// loadNode reads the node and value of key. Without the lock, the caller
+// must pin an epoch and validate the result; see lookup.
+func (sh *SkipHash[K, V]) loadNode(key K) (*slNode[K, V], V, bool) {
+	node, ok := sh.index.get(key)
+	var value *V
+	if ok {
+		// A node Split or Append moved out may since have been recycled by
+		// its new SkipHash; the move is a batch, so lookup retries.
+		value = node.value.Load()
+	}
+	if value == nil {
 		var zero V
-		return zero, false
+		return nil, zero, false
 	}
-	return node.value, true
+	return node, *value, true
 }
 
 func (sh *SkipHash[K, V]) Contains(key K) bool {
-	sh.mu.RLock()
-	defer sh.mu.RUnlock()
-	_, ok := sh.index[key]
+	key = sh.normalizeKey(key)
+	if sh.fine != nil {
+		_, ok := sh.fine.get(key)
+		return ok
+	}
+	sh.faultIn(key, key)
+	epoch := sh.epochs.pin()
+	defer sh.epochs.unpin(epoch)
+	node, _, ok := sh.lookup(key)
+	if ok {
+		sh.touch(node)
+	}
 	return ok
 }
 
 // Insert adds a new key/value pair and fails if a key already exists.
 func (sh *SkipHash[K, V]) Insert(key K, value V) bool {
-	sh.mu.Lock()
-	defer sh.mu.Unlock()
+	return sh.TryInsert(key, value) == nil
+}
 
-	if _, exists := sh.index[key]; exists {
-		return false
+// TryInsert is like Insert but reports why the write was rejected:
+// ErrKeyExists for a live key, or a *QuotaError when the tenant is full.
+func (sh *SkipHash[K, V]) TryInsert(key K, value V) error {
+	key = sh.normalizeKey(key)
+	if sh.fine != nil {
+		if !sh.fine.put(key, value, false) {
+			return ErrKeyExists
+		}
+		return nil
 	}
+	sh.mu.Lock()
+	defer sh.unlock()
+	sh.faultInLocked(key, key)
 
-	node := sh.insertNodeLocked(key, value)
-	sh.index[key] = node
-	sh.len++
-	return true
+	if _, exists := sh.index.get(key); exists {
+		return ErrKeyExists
+	}
+	return sh.insertLocked(key, value)
 }
 
-// Store inserts or replaces the value for key.
+// Store inserts or replaces the value for key, clearing any TTL.
 // It returns true if a new key was inserted.
 func (sh *SkipHash[K, V]) Store(key K, value V) bool {
+	inserted, _ := sh.TryStore(key, value)
+	return inserted
+}
+
+// TryStore is like Store but returns an error when the write is rejected by
+// a quota or, for a new or larger value, by WithMaxWeight.
+func (sh *SkipHash[K, V]) TryStore(key K, value V) (bool, error) {
+	key = sh.normalizeKey(key)
+	if sh.fine != nil {
+		return sh.fine.put(key, value, true), nil
+	}
 	sh.mu.Lock()
-	defer sh.mu.Unlock()
+	defer sh.unlock()
+	sh.faultInLocked(key, key)
 
-	if node, exists := sh.index[key]; exists {
-		node.value = value
-		return false
+	if node, exists := sh.index.get(key); exists {
+		sh.touch(node)
+		return false, sh.updateLocked(node, value)
 	}
+	if err := sh.insertLocked(key, value); err != nil {
+		return false, err
+	}
+	return true, nil
+}
 
+// insertLocked links a new live node for key, which must not be in the index.
+func (sh *SkipHash[K, V]) insertLocked(key K, value V) error {
+	if sh.quota != nil {
+		if err := sh.quota.admit(key); err != nil {
+			return err
+		}
+	}
+	if w := sh.weigh(key, value); w > 0 {
+		if err := sh.admitWeightLocked(w, w); err != nil {
+			return err
+		}
+	}
+
+	node := sh.attachLocked(key, value)
+	sh.noteWriteLocked(node)
+	if sh.quota != nil {
+		sh.quota.added(key)
+	}
+	sh.changedLocked(change[K, V]{kind: ChangeInsert, key: key, value: value})
+	sh.enforceCapsLocked(node)
+	return nil
+}
+
+// attachLocked links and indexes a new live node without any accounting
+// beyond the length and weight.
+func (sh *SkipHash[K, V]) attachLocked(key K, value V) *slNode[K, V] {
 	node := sh.insertNodeLocked(key, value)
-	sh.index[key] = node
+	sh.index.set(key, node)
 	sh.len++
-	return true
+	sh.weight += sh.weigh(key, value)
+	sh.touch(node)
+	return node
+}
+
+// updateLocked replaces the value of a live node. While an active range or
+// snapshot can see the node, the old node is retired and a new one linked
+// instead, so versioned readers keep observing the value they started with.
+// Any TTL on the entry is cleared. The only possible error is ErrOverWeight.
+func (sh *SkipHash[K, V]) updateLocked(node *slNode[K, V], value V) error {
+	old, expiry, meta := *node.value.Load(), node.expiry.at, sh.undoMetaLocked(node)
+	oldWeight := sh.weigh(node.key, old)
+	newWeight := sh.weigh(node.key, value)
+	if newWeight > oldWeight {
+		if err := sh.admitWeightLocked(newWeight, newWeight-oldWeight); err != nil {
+			return err
+		}
+	}
+
+	if sh.rqc.pinnedLocked(node) {
+		created, insertedAt := node.created, node.insertedAt
+		sh.retireNodeLocked(node)
+		node = sh.attachLocked(node.key, value)
+		node.created, node.insertedAt = created, insertedAt
+	} else {
+		sh.unscheduleLocked(node)
+		sh.recordHistoryLocked(node)
+		node.value.Store(&value)
+		sh.augmentLocked(node, true)
+		node.writtenAt = sh.rqc.onUpdateLocked()
+		sh.weight += newWeight - oldWeight
+	}
+	sh.noteWriteLocked(node)
+	sh.changedLocked(change[K, V]{kind: ChangeUpdate, key: node.key, old: old, value: value, expiry: expiry, meta: meta})
+	sh.enforceCapsLocked(node)
+	return nil
 }
 
 func (sh *SkipHash[K, V]) insertNodeLocked(key K, value V) *slNode[K, V] {
 	level := sh.randomLevelLocked()
-	preds, succs := sh.findInsertNeighborsLocked(key)
-	node := &slNode[K, V]{
-		key:    key,
-		value:  value,
-		height: level,
-		prev:   make([]*slNode[K, V], level),
-		next:   make([]*slNode[K, V], level),
-		iTime:  sh.rqc.onUpdateLocked(),
-	}
+	preds, succs, ranks := sh.findInsertNeighborsLocked(key)
+	node := sh.newNodeLocked(key, value, level)
 
 	for i := uint8(0); i < level; i++ {
 		pred := preds[i]
@@ -203,35 +690,145 @@ func (sh *SkipHash[K, V]) insertNodeLocked(key K, value V) *slNode[K, V] {
 		node.next[i] = succ
 		pred.next[i] = node
 		succ.prev[i] = node
+
+		node.span[i] = pred.span[i] - (ranks[0] - ranks[i])
+		pred.span[i] = ranks[0] - ranks[i] + 1
+	}
+	for i := int(level); i < sh.maxLevel; i++ {
+		preds[i].span[i]++
 	}
+	sh.augmentLocked(node, true)
 
 	return node
 }
 
 func (sh *SkipHash[K, V]) Remove(key K) bool {
+	key = sh.normalizeKey(key)
+	if sh.fine != nil {
+		return sh.fine.remove(key)
+	}
 	sh.mu.Lock()
-	defer sh.mu.Unlock()
+	defer sh.unlock()
+	sh.faultInLocked(key, key)
 
-	node, exists := sh.index[key]
+	node, exists := sh.index.get(key)
 	if !exists {
 		return false
 	}
 
-	delete(sh.index, key)
+	sh.removeLocked(node)
+	return true
+}
+
+// removeLocked logically deletes a live node and drops it from the index.
+func (sh *SkipHash[K, V]) removeLocked(node *slNode[K, V]) {
+	sh.dropLocked(node, false)
+}
+
+// dropLocked removes a live node on behalf of a caller or, when evicted is
+// set, of the SkipHash itself.
+func (sh *SkipHash[K, V]) dropLocked(node *slNode[K, V], evicted bool) {
+	// detachLocked may recycle the node, so read it first.
+	key, value, expiry, meta := node.key, *node.value.Load(), node.expiry.at, sh.undoMetaLocked(node)
+	sh.detachLocked(node)
+	if sh.quota != nil {
+		sh.quota.removed(key)
+	}
+	sh.changedLocked(change[K, V]{kind: ChangeRemove, evicted: evicted, key: key, value: value, expiry: expiry, meta: meta})
+}
+
+// change is a committed mutation. old is the replaced value for updates;
+// for removals value is the removed value. expiry is the deadline the entry
+// had before an update or removal, so a rolled back Txn can restore it, and
+// for changeExpiry the new deadline; meta is, inside a Txn, the rest of
+// what such a rollback restores.
+type change[K any, V any] struct {
+	kind    ChangeKind
+	evicted bool
+	key     K
+	old     V
+	value   V
+	expiry  int64
+	meta    entryMeta[V]
+}
+
+// changedLocked fans a committed mutation out to the optional observers.
+func (sh *SkipHash[K, V]) changedLocked(c change[K, V]) {
+	if sh.txn != nil {
+		sh.txn.changes = append(sh.txn.changes, c)
+		return
+	}
+	if c.kind == changeExpiry {
+		if sh.wal != nil {
+			sh.wal.appendLocked(c, sh.rqc.onUpdateLocked())
+		}
+		return
+	}
+	if sh.buckets != nil {
+		sh.buckets.bump(c.key)
+	}
+	sh.eventual.noteLocked(c)
+	for _, ix := range sh.secondaries {
+		ix.applyLocked(c)
+	}
+	if sh.byValue != nil {
+		sh.byValue.applyLocked(c)
+	}
+	if sh.watchers != nil {
+		sh.watchers.publishLocked(sh, ChangeEvent[K, V]{
+			Kind:    c.kind,
+			Key:     c.key,
+			Value:   c.value,
+			Version: sh.rqc.onUpdateLocked(),
+		})
+	}
+	if sh.hooks != nil {
+		sh.pendingHooks = append(sh.pendingHooks, c)
+	}
+	if sh.wal != nil {
+		sh.wal.appendLocked(c, sh.rqc.onUpdateLocked())
+	}
+	if sh.changelog != nil {
+		sh.changelog.appendLocked(c)
+	}
+}
+
+// detachLocked is the structural half of removeLocked: the node becomes a
+// tombstone and is unstitched once no range operation can still see it.
+func (sh *SkipHash[K, V]) detachLocked(node *slNode[K, V]) {
+	sh.index.delete(node.key)
+	sh.retireNodeLocked(node)
+}
+
+// retireNodeLocked is detachLocked for a node whose index entry is about to
+// point at its replacement, so lock-free readers never find the key missing.
+func (sh *SkipHash[K, V]) retireNodeLocked(node *slNode[K, V]) {
+	sh.unscheduleLocked(node)
+	sh.adjustSpansLocked(node, -1)
 	node.rTime = sh.rqc.onUpdateLocked()
-	sh.rqc.afterRemoveLocked(sh, node)
+	sh.augmentLocked(node, true)
+	sh.weight -= sh.weigh(node.key, *node.value.Load())
+	if sh.historyDepth > 0 {
+		sh.retainLocked(node)
+	} else {
+		sh.rqc.afterRemoveLocked(sh, node)
+	}
 	sh.len--
-	return true
 }
 
 func (sh *SkipHash[K, V]) Ceil(key K) (Entry[K, V], bool) {
+	key = sh.normalizeKey(key)
+	if sh.fine != nil {
+		return sh.fine.ceil(key, false)
+	}
+	sh.faultInNear(key, false, Ascending)
 	sh.mu.RLock()
 	defer sh.mu.RUnlock()
 
-	if node, exists := sh.index[key]; exists {
+	if node, exists := sh.index.get(key); exists {
 		return Entry[K, V]{
 			Key:   node.key,
-			Value: node.value,
+			Value: *node.value.Load(),
 		}, true
 	}
 
@@ -242,22 +839,27 @@ func (sh *SkipHash[K, V]) Ceil(key K) (Entry[K, V], bool) {
 	}
 	return Entry[K, V]{
 		Key:   node.key,
-		Value: node.value,
+		Value: *node.value.Load(),
 	}, true
 }
 
 func (sh *SkipHash[K, V]) Succ(key K) (Entry[K, V], bool) {
+	key = sh.normalizeKey(key)
+	if sh.fine != nil {
+		return sh.fine.ceil(key, true)
+	}
+	sh.faultInNear(key, true, Ascending)
 	sh.mu.RLock()
 	defer sh.mu.RUnlock()
 
 	var node *slNode[K, V]
-	if cur, exists := sh.index[key]; exists {
+	if cur, exists := sh.index.get(key); exists {
 		node = cur.next[0]
 	} else {
 		node = sh.lowerBoundLocked(key)
 	}
 	for node != sh.tail &&
-		(node.key == key || node.rTime != 0) {
+		(sh.compare(node.key, key) == 0 || node.rTime != 0) {
 		node = node.next[0]
 	}
 
@@ -267,18 +869,23 @@ func (sh *SkipHash[K, V]) Succ(key K) (Entry[K, V], bool) {
 	}
 	return Entry[K, V]{
 		Key:   node.key,
-		Value: node.value,
+		Value: *node.value.Load(),
 	}, true
 }
 
 func (sh *SkipHash[K, V]) Floor(key K) (Entry[K, V], bool) {
+	key = sh.normalizeKey(key)
+	if sh.fine != nil {
+		return sh.fine.floor(&key, false)
+	}
+	sh.faultInNear(key, false, Descending)
 	sh.mu.RLock()
 	defer sh.mu.RUnlock()
 
-	if node, exists := sh.index[key]; exists {
+	if node, exists := sh.index.get(key); exists {
 		return Entry[K, V]{
 			Key:   node.key,
-			Value: node.value,
+			Value: *node.value.Load(),
 		}, true
 	}
 
@@ -289,11 +896,16 @@ func (sh *SkipHash[K, V]) Floor(key K) (Entry[K, V], bool) {
 	}
 	return Entry[K, V]{
 		Key:   node.key,
-		Value: node.value,
+		Value: *node.value.Load(),
 	}, true
 }
 
 func (sh *SkipHash[K, V]) Pred(key K) (Entry[K, V], bool) {
+	key = sh.normalizeKey(key)
+	if sh.fine != nil {
+		return sh.fine.floor(&key, true)
+	}
+	sh.faultInNear(key, true, Descending)
 	sh.mu.RLock()
 	defer sh.mu.RUnlock()
 
@@ -304,26 +916,27 @@ func (sh *SkipHash[K, V]) Pred(key K) (Entry[K, V], bool) {
 	}
 	return Entry[K, V]{
 		Key:   node.key,
-		Value: node.value,
+		Value: *node.value.Load(),
 	}, true
 }
 
 func (sh *SkipHash[K, V]) predecessorLocked(key K, strict bool) *slNode[K, V] {
-	cur := sh.head
-	for level := sh.maxLevel - 1; level >= 0; level-- {
+	cur, top := sh.searchStartLocked(key, strict)
+	for level := top; level >= 0; level-- {
 		next := cur.next[level]
 		for next != sh.tail {
 			if strict {
-				if next.key >= key {
+				if sh.compare(next.key, key) >= 0 {
 					break
 				}
-			} else if next.key > key {
+			} else if sh.compare(next.key, key) > 0 {
 				break
 			}
 			cur = next
 			next = cur.next[level]
 		}
 	}
+	sh.moveFingerLocked(cur)
 	for cur != sh.head && cur.rTime != 0 {
 		cur = cur.prev[0]
 	}
@@ -332,45 +945,55 @@ func (sh *SkipHash[K, V]) predecessorLocked(key K, strict bool) *slNode[K, V] {
 
 // RangeAll returns all logically present entries.
 func (sh *SkipHash[K, V]) RangeAll() []Entry[K, V] {
-	out := make([]Entry[K, V], 0, sh.Len())
+	if sh.fine != nil {
+		return sh.fine.entries(nil, nil)
+	}
+	sh.faultInAll()
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+	return sh.allEntriesLocked()
+}
+
+func (sh *SkipHash[K, V]) allEntriesLocked() []Entry[K, V] {
+	out := make([]Entry[K, V], 0, sh.len)
 	for node := sh.head.next[0]; node != sh.tail; node = node.next[0] {
 		if node.rTime == 0 {
 			out = append(out, Entry[K, V]{
 				Key:   node.key,
-				Value: node.value,
+				Value: *node.value.Load(),
 			})
 		}
 	}
 	return out
-
 }
 
 // RangeCount returns how many logically present keys are in [low, high].
+// It runs in O(log n) using the span counts instead of walking the interval.
 func (sh *SkipHash[K, V]) RangeCount(low, high K) int {
-	if low > high {
+	low, high = sh.normalizeKey(low), sh.normalizeKey(high)
+	if sh.compare(low, high) > 0 {
 		return 0
 	}
+	if sh.fine != nil {
+		return len(sh.fine.entries(&low, &high))
+	}
+	sh.faultIn(low, high)
 	sh.mu.RLock()
 	defer sh.mu.RUnlock()
 
-	count := 0
-	for node := sh.lowerBoundLocked(low); node != sh.tail && node.key <= high; node = node.next[0] {
-		if node.rTime == 0 {
-			count++
-		}
-	}
-	return count
+	return sh.rankLocked(high, true) - sh.rankLocked(low, false)
 }
 
 func (sh *SkipHash[K, V]) lowerBoundLocked(key K) *slNode[K, V] {
-	cur := sh.head
-	for level := sh.maxLevel - 1; level >= 0; level-- {
+	cur, top := sh.searchStartLocked(key, true)
+	for level := top; level >= 0; level-- {
 		next := cur.next[level]
-		for next != sh.tail && next.key < key {
+		for next != sh.tail && sh.compare(next.key, key) < 0 {
 			cur = next
 			next = cur.next[level]
 		}
 	}
+	sh.moveFingerLocked(cur)
 	return cur.next[0]
 }
 
@@ -383,59 +1006,126 @@ func (sh *SkipHash[K, V]) firstLiveGELocked(key K) *slNode[K, V] {
 	return sh.tail
 }
 
-func (sh *SkipHash[K, V]) findInsertNeighborsLocked(key K) ([]*slNode[K, V], []*slNode[K, V]) {
-	preds := make([]*slNode[K, V], sh.maxLevel)
-	succs := make([]*slNode[K, V], sh.maxLevel)
+// findInsertNeighborsLocked returns the per-level neighbours of key together
+// with the number of live nodes up to and including each predecessor. The
+// slices are the SkipHash's scratch buffers and are only valid until the next
+// call.
+func (sh *SkipHash[K, V]) findInsertNeighborsLocked(key K) ([]*slNode[K, V], []*slNode[K, V], []int) {
+	preds, succs, ranks := sh.preds, sh.succs, sh.ranks
+	if sh.appendMode && sh.appendsLocked(key) {
+		// Every predecessor is the tail's, and the live nodes after it are
+		// exactly those its span counts.
+		for level := range sh.maxLevel {
+			preds[level] = sh.tail.prev[level]
+			succs[level] = sh.tail
+			ranks[level] = sh.len - preds[level].span[level]
+		}
+		return preds, succs, ranks
+	}
 
 	cur := sh.head
+	rank := 0
 	for level := sh.maxLevel - 1; level >= 0; level-- {
 		next := cur.next[level]
 		for next != sh.tail {
-			if next.key < key {
-				cur = next
-				next = cur.next[level]
-				continue
-			}
+			c := sh.compare(next.key, key)
 			// Reinsertions may race with deferred physical removal. We keep new
 			// key instances after the logically deleted chain for the same key.
-			if next.key == key && next.rTime != 0 {
-				cur = next
-				next = cur.next[level]
-				continue
+			if c > 0 || c == 0 && next.rTime == 0 {
+				break
 			}
-			break
+			rank += cur.span[level]
+			cur = next
+			next = cur.next[level]
 		}
 		preds[level] = cur
 		succs[level] = next
+		ranks[level] = rank
 	}
 
-	return preds, succs
+	return preds, succs, ranks
 }
 
+// appendsLocked reports whether key belongs after the last node, which
+// holds when it sorts after that node's key or equals the key of a
+// tombstone there.
+func (sh *SkipHash[K, V]) appendsLocked(key K) bool {
+	last := sh.tail.prev[0]
+	if last == sh.head {
+		return true
+	}
+	c := sh.compare(last.key, key)
+	return c < 0 || c == 0 && last.rTime != 0
+}
+
+// randomLevelLocked draws a geometric level with p = 1/2 from a single
+// random word: each trailing zero bit is one more promotion. A given
+// WithRandSource therefore always yields the same sequence of levels.
 func (sh *SkipHash[K, V]) randomLevelLocked() uint8 {
-	level := 1
-	for level < sh.maxLevel && sh.rng.Float64() < 0.5 {
-		level++
+	return uint8(min(bits.TrailingZeros64(sh.rng.Uint64())+1, sh.topLevelLocked()))
+}
+
+// topLevelLocked is the number of levels searches descend through. With
+// WithAutoLevel it follows log2 of the size, so small maps skip the empty
+// upper levels; nodes left taller than that by removals are still found
+// through the lower levels.
+func (sh *SkipHash[K, V]) topLevelLocked() int {
+	if !sh.autoLevel {
+		return sh.maxLevel
 	}
-	return uint8(level)
+	return min(bits.Len(uint(sh.len))+1, sh.maxLevel)
 }
 
 func (sh *SkipHash[K, V]) unstitchNodeLocked(node *slNode[K, V]) {
+	if sh.unlinkNodeLocked(node) {
+		sh.epochs.retireLocked(node)
+	}
+}
+
+// unlinkNodeLocked is unstitchNodeLocked without handing the node to
+// reclamation, for nodes a range walk may still be positioned on. It reports
+// whether node was linked.
+func (sh *SkipHash[K, V]) unlinkNodeLocked(node *slNode[K, V]) bool {
 	if node == nil ||
 		node == sh.head ||
 		node == sh.tail ||
 		node.unstitched {
-		return
+		return false
+	}
+	weight := 0
+	if node.rTime == 0 {
+		weight = 1
 	}
 	for level := uint8(0); level < node.height; level++ {
 		pred := node.prev[level]
 		succ := node.next[level]
 		if pred != nil {
 			pred.next[level] = succ
+			pred.span[level] += node.span[level] - weight
 		}
 		if succ != nil {
 			succ.prev[level] = pred
 		}
 	}
 	node.unstitched = true
+	sh.augmentLocked(node, false)
+	if sh.fingers {
+		sh.finger.CompareAndSwap(node, nil)
+	}
+	return true
+}
+
+// adjustSpansLocked adds delta to every span that covers node, which is how a
+// stitched node changes its weight when it becomes logically deleted.
+func (sh *SkipHash[K, V]) adjustSpansLocked(node *slNode[K, V], delta int) {
+	for level := uint8(0); level < node.height; level++ {
+		node.prev[level].span[level] += delta
+	}
+	cur := node.prev[node.height-1]
+	for level := int(node.height); level < sh.maxLevel; level++ {
+		for int(cur.height) <= level {
+			cur = cur.prev[cur.height-1]
+		}
+		cur.span[level] += delta
+	}
 }
@@ -35,6 +35,12 @@ func newAdapter() benchMap {
 	}
 }
 
+func newFineGrainedAdapter() benchMap {
+	return &adapter{
+		sh: New[int, int](WithConcurrencyMode(FineGrained)),
+	}
+}
+
 func (a *adapter) Load(k int) (int, bool) {
 	return a.sh.Get(k)
 }
@@ -51,6 +57,80 @@ func (a *adapter) RangeCount(low, high int) int {
 	return a.sh.RangeCount(low, high)
 }
 
+type shardedAdapter struct {
+	s *Sharded[int, int]
+}
+
+func newShardedAdapter() benchMap {
+	return &shardedAdapter{
+		s: NewSharded[int, int](16, WithRandSource(rand.NewSource(1))),
+	}
+}
+
+func (a *shardedAdapter) Load(k int) (int, bool) {
+	return a.s.Get(k)
+}
+
+func (a *shardedAdapter) Store(k, v int) {
+	a.s.Store(k, v)
+}
+
+func (a *shardedAdapter) Delete(k int) {
+	a.s.Remove(k)
+}
+
+func (a *shardedAdapter) RangeCount(low, high int) int {
+	return a.s.RangeCount(low, high)
+}
+
+type lockFreeAdapter struct {
+	m *LockFree[int, int]
+}
+
+func newLockFreeAdapter() benchMap {
+	return &lockFreeAdapter{m: NewLockFree[int, int]()}
+}
+
+func (a *lockFreeAdapter) Load(k int) (int, bool) {
+	return a.m.Get(k)
+}
+
+func (a *lockFreeAdapter) Store(k, v int) {
+	a.m.Store(k, v)
+}
+
+func (a *lockFreeAdapter) Delete(k int) {
+	a.m.Remove(k)
+}
+
+func (a *lockFreeAdapter) RangeCount(low, high int) int {
+	return len(a.m.Range(low, high))
+}
+
+type unrolledAdapter struct {
+	m *Unrolled[int, int]
+}
+
+func newUnrolledAdapter() benchMap {
+	return &unrolledAdapter{m: NewUnrolled[int, int](WithRandSource(rand.NewSource(1)))}
+}
+
+func (a *unrolledAdapter) Load(k int) (int, bool) {
+	return a.m.Get(k)
+}
+
+func (a *unrolledAdapter) Store(k, v int) {
+	a.m.Store(k, v)
+}
+
+func (a *unrolledAdapter) Delete(k int) {
+	a.m.Remove(k)
+}
+
+func (a *unrolledAdapter) RangeCount(low, high int) int {
+	return len(a.m.Range(low, high))
+}
+
 type lockedMapAdapter struct {
 	mu sync.RWMutex
 	m  map[int]int
@@ -132,6 +212,10 @@ var benchmarkImplementations = []struct {
 	new  func() benchMap
 }{
 	{name: "skiphash", new: newAdapter},
+	{name: "skiphash-sharded", new: newShardedAdapter},
+	{name: "skiphash-lockfree", new: newLockFreeAdapter},
+	{name: "skiphash-finegrained", new: newFineGrainedAdapter},
+	{name: "skiphash-unrolled", new: newUnrolledAdapter},
 	{name: "map+rwmutex", new: newLockedMapAdapter},
 	{name: "sync.Map", new: newSyncMapAdapter},
 }
@@ -309,3 +393,154 @@ func BenchmarkOrderedMapRangeParallel(b *testing.B) {
 	}
 	runWorkloadOnAllMaps(b, cfg)
 }
+
+func BenchmarkRangeScan(b *testing.B) {
+	sh := New[int, int](WithRandSource(rand.NewSource(1)))
+	for k := range benchUniverse {
+		sh.Store(k, k)
+	}
+
+	for _, width := range []int{benchRangeWidth, 16 * benchRangeWidth} {
+		b.Run(fmt.Sprintf("width_%d", width), func(b *testing.B) {
+			b.ReportAllocs()
+			r := rand.New(rand.NewSource(2))
+			for b.Loop() {
+				low := r.Intn(benchUniverse - width)
+				benchSink.Add(int64(len(sh.Range(low, low+width))))
+			}
+		})
+	}
+}
+
+// BenchmarkAnyKeyOverhead compares a generic int instantiation with the same
+// keys boxed in AnyKey.
+func BenchmarkAnyKeyOverhead(b *testing.B) {
+	const n = 10_000
+	generic := New[int, int](WithRandSource(rand.NewSource(1)))
+	boxed := NewAnyKey[int](WithRandSource(rand.NewSource(1)))
+	keys := make([]AnyKey, n)
+	for i := range n {
+		keys[i] = MustAnyKey(i)
+		generic.Store(i, i)
+		boxed.Store(keys[i], i)
+	}
+
+	b.Run("get/generic", func(b *testing.B) {
+		i := 0
+		for b.Loop() {
+			generic.Get(i % n)
+			i++
+		}
+	})
+	b.Run("get/anykey", func(b *testing.B) {
+		i := 0
+		for b.Loop() {
+			boxed.Get(keys[i%n])
+			i++
+		}
+	})
+	b.Run("store/generic", func(b *testing.B) {
+		i := 0
+		for b.Loop() {
+			generic.Store(n+i%n, i)
+			i++
+		}
+	})
+	b.Run("store/anykey", func(b *testing.B) {
+		i := 0
+		for b.Loop() {
+			boxed.Store(MustAnyKey(n+i%n), i)
+			i++
+		}
+	})
+	b.Run("range/generic", func(b *testing.B) {
+		for b.Loop() {
+			generic.Range(100, 100+benchRangeWidth)
+		}
+	})
+	b.Run("range/anykey", func(b *testing.B) {
+		low, high := keys[100], keys[100+benchRangeWidth]
+		for b.Loop() {
+			boxed.Range(low, high)
+		}
+	})
+}
+
+func BenchmarkBulkBuild(b *testing.B) {
+	entries := make([]Entry[int, int], benchUniverse)
+	for i := range entries {
+		entries[i] = Entry[int, int]{Key: i, Value: i}
+	}
+
+	b.Run("NewFromSorted", func(b *testing.B) {
+		for b.Loop() {
+			if _, err := NewFromSorted(entries); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("InsertSortedStrict", func(b *testing.B) {
+		for b.Loop() {
+			if err := New[int, int]().InsertSortedStrict(entries); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkInsert(b *testing.B) {
+	b.ReportAllocs()
+	sh := New[int, int](WithRandSource(rand.NewSource(1)))
+	r := rand.New(rand.NewSource(2))
+	for b.Loop() {
+		k := r.Int()
+		sh.Store(k, k)
+	}
+}
+
+func BenchmarkInsertArena(b *testing.B) {
+	b.ReportAllocs()
+	sh := New[int, int](WithArena(), WithRandSource(rand.NewSource(1)))
+	r := rand.New(rand.NewSource(2))
+	for b.Loop() {
+		k := r.Int()
+		sh.Store(k, k)
+	}
+}
+
+func BenchmarkInsertIncreasing(b *testing.B) {
+	for _, appendMode := range []bool{false, true} {
+		b.Run(fmt.Sprintf("append_%t", appendMode), func(b *testing.B) {
+			opts := []Option{WithRandSource(rand.NewSource(1))}
+			if appendMode {
+				opts = append(opts, WithAppendMode())
+			}
+			sh := New[int, int](opts...)
+			k := 0
+			for b.Loop() {
+				sh.Store(k, k)
+				k++
+			}
+		})
+	}
+}
+
+func BenchmarkSequentialCeil(b *testing.B) {
+	for _, finger := range []bool{false, true} {
+		b.Run(fmt.Sprintf("finger_%t", finger), func(b *testing.B) {
+			opts := []Option{WithRandSource(rand.NewSource(1))}
+			if finger {
+				opts = append(opts, WithFinger())
+			}
+			sh := New[int, int](opts...)
+			for k := range benchUniverse {
+				sh.Store(2*k, k)
+			}
+			k := 0
+			for b.Loop() {
+				sh.Ceil(k % (2 * benchUniverse))
+				k++
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package skiphash
 
 import (
 	"math/rand"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -199,6 +200,7 @@ func TestSkipHashConcurrentSanity(t *testing.T) {
 	wg.Wait()
 
 	assert.GreaterOrEqual(t, sh.Len(), 0, "len should never be negative")
+	checkSpans(t, sh)
 }
 
 func TestSkipHashRangeCount(t *testing.T) {
@@ -213,3 +215,303 @@ func TestSkipHashRangeCount(t *testing.T) {
 	got := sh.RangeCount(0, 99)
 	assert.Equal(t, 90, got, "unexpected range count")
 }
+
+func TestSkipHashRangeCountMatchesScan(t *testing.T) {
+	sh := New[int, int](WithRandSource(rand.NewSource(9)))
+	r := rand.New(rand.NewSource(10))
+	for range 3000 {
+		k := r.Intn(1000)
+		if r.Intn(4) == 0 {
+			sh.Remove(k)
+		} else {
+			sh.Store(k, k)
+		}
+	}
+
+	for range 200 {
+		low := r.Intn(1100) - 50
+		high := low + r.Intn(300)
+		assert.Equal(t, len(sh.Range(low, high)), sh.RangeCount(low, high), "range [%d, %d]", low, high)
+	}
+	assert.Equal(t, 0, sh.RangeCount(10, 5))
+}
+
+func TestSkipHashWalkFrom(t *testing.T) {
+	sh := New[int, int](WithRandSource(rand.NewSource(11)))
+	for i := range 10 {
+		sh.Insert(i*10, i)
+	}
+	sh.Remove(40)
+
+	keys := func(entries []Entry[int, int]) []int {
+		out := make([]int, 0, len(entries))
+		for _, e := range entries {
+			out = append(out, e.Key)
+		}
+		return out
+	}
+
+	assert.Equal(t, []int{30, 50, 60}, keys(sh.WalkFrom(20, 3, Ascending)))
+	assert.Equal(t, []int{30, 50}, keys(sh.WalkFrom(25, 2, Ascending)))
+	assert.Equal(t, []int{30, 20, 10}, keys(sh.WalkFrom(50, 3, Descending)))
+	assert.Equal(t, []int{30, 20}, keys(sh.WalkFrom(45, 2, Descending)))
+	assert.Equal(t, []int{80, 90}, keys(sh.WalkFrom(70, 5, Ascending)))
+	assert.Empty(t, sh.WalkFrom(0, 5, Descending))
+	assert.Empty(t, sh.WalkFrom(0, 0, Ascending))
+
+	assert.Equal(t, []int{30, 50}, keys(sh.NextN(20, 2)))
+	assert.Equal(t, []int{30, 20}, keys(sh.PrevN(40, 2)))
+	assert.Empty(t, sh.NextN(90, 3))
+}
+
+func TestSkipHashKSmallestKLargest(t *testing.T) {
+	sh := New[int, int](WithRandSource(rand.NewSource(13)))
+	for i := range 10 {
+		sh.Insert(i, i)
+	}
+	sh.Remove(0)
+	sh.Remove(9)
+
+	assert.Equal(t, []Entry[int, int]{{Key: 1, Value: 1}, {Key: 2, Value: 2}, {Key: 3, Value: 3}}, sh.KSmallest(3))
+	assert.Equal(t, []Entry[int, int]{{Key: 8, Value: 8}, {Key: 7, Value: 7}}, sh.KLargest(2))
+	assert.Len(t, sh.KLargest(100), 8)
+	assert.Empty(t, sh.KSmallest(0))
+	assert.Empty(t, New[int, int]().KLargest(3))
+}
+
+func TestSkipHashRangeLimit(t *testing.T) {
+	sh := New[int, int](WithRandSource(rand.NewSource(12)))
+	for i := range 20 {
+		sh.Insert(i, i)
+	}
+	sh.Remove(5)
+
+	var got []int
+	low, pages := 0, 0
+	for {
+		entries, next, more := sh.RangeLimit(low, 14, 4)
+		pages++
+		for _, e := range entries {
+			got = append(got, e.Key)
+		}
+		if !more {
+			break
+		}
+		low = next
+	}
+	assert.Equal(t, []int{0, 1, 2, 3, 4, 6, 7, 8, 9, 10, 11, 12, 13, 14}, got)
+	assert.Equal(t, 4, pages)
+
+	entries, _, more := sh.RangeLimit(16, 19, 4)
+	assert.Len(t, entries, 4)
+	assert.False(t, more, "exact fit must not report more")
+
+	entries, _, more = sh.RangeLimit(0, 10, 0)
+	assert.Empty(t, entries)
+	assert.False(t, more)
+}
+
+func TestSkipHashDescending(t *testing.T) {
+	sh := New[int, int](WithDescending(), WithRandSource(rand.NewSource(15)))
+	for _, k := range []int{3, 9, 1, 7, 5} {
+		sh.Insert(k, k)
+	}
+
+	keys := func(entries []Entry[int, int]) []int {
+		out := make([]int, 0, len(entries))
+		for _, e := range entries {
+			out = append(out, e.Key)
+		}
+		return out
+	}
+
+	assert.Equal(t, []int{9, 7, 5, 3, 1}, keys(sh.RangeAll()))
+	assert.Equal(t, []int{7, 5, 3}, keys(sh.Range(8, 2)))
+	assert.Empty(t, sh.Range(2, 8))
+	assert.Equal(t, 3, sh.RangeCount(8, 2))
+
+	ceil, _ := sh.Ceil(6)
+	assert.Equal(t, 5, ceil.Key)
+	floor, _ := sh.Floor(6)
+	assert.Equal(t, 7, floor.Key)
+	succ, _ := sh.Succ(7)
+	assert.Equal(t, 5, succ.Key)
+	pred, _ := sh.Pred(7)
+	assert.Equal(t, 9, pred.Key)
+
+	assert.Equal(t, 1, sh.Rank(7))
+	first, _ := sh.Select(0)
+	assert.Equal(t, 9, first.Key)
+
+	page, next, more := sh.RangeLimit(9, 1, 2)
+	assert.Equal(t, []int{9, 7}, keys(page))
+	assert.True(t, more)
+	assert.Equal(t, 5, next)
+}
+
+func TestSkipHashKeyNormalizer(t *testing.T) {
+	sh := New[string, int](WithKeyNormalizer(strings.ToLower))
+
+	assert.True(t, sh.Insert("Alice", 1))
+	assert.False(t, sh.Insert("ALICE", 2), "normalized keys must collide")
+	assert.True(t, sh.Store("bob", 3))
+	assert.False(t, sh.Store("BOB", 4))
+
+	got, ok := sh.Get("aLiCe")
+	assert.True(t, ok)
+	assert.Equal(t, 1, got)
+	assert.True(t, sh.Contains("Bob"))
+
+	entries := sh.Range("A", "C")
+	assert.Equal(t, []Entry[string, int]{{"alice", 1}, {"bob", 4}}, entries)
+	assert.Equal(t, 2, sh.RangeCount("A", "Z"))
+	assert.Equal(t, 1, sh.Rank("BOB"))
+	succ, _ := sh.Succ("ALICE")
+	assert.Equal(t, "bob", succ.Key)
+
+	assert.NoError(t, sh.InsertSortedStrict([]Entry[string, int]{{"Carol", 5}, {"dave", 6}}))
+	assert.ErrorIs(t, sh.InsertSortedStrict([]Entry[string, int]{{"Eve", 7}, {"EVE", 8}}), ErrDuplicateKey)
+
+	assert.True(t, sh.Remove("ALICE"))
+	assert.False(t, sh.Contains("alice"))
+}
+
+func TestSkipHashRandomLevels(t *testing.T) {
+	build := func() *SkipHash[int, int] {
+		sh := New[int, int](WithRandSource(rand.NewSource(42)), WithMaxLevel(8))
+		for i := range 4096 {
+			sh.Insert(i, i)
+		}
+		return sh
+	}
+
+	a, b := build(), build()
+	assert.Equal(t, a.LevelStats(), b.LevelStats(), "the same source yields the same levels")
+
+	levels := a.LevelStats().Levels
+	for level := 1; level < 6; level++ {
+		ratio := float64(levels[level]) / float64(levels[level-1])
+		assert.InDelta(t, 0.5, ratio, 0.1, "level %d", level)
+	}
+	assert.Positive(t, levels[7], "levels are capped, not dropped")
+}
+
+func TestSkipHashAutoLevel(t *testing.T) {
+	sh := New[int, int](WithAutoLevel(), WithRandSource(rand.NewSource(5)))
+	for k := range 1000 {
+		sh.Store(2*k, k)
+	}
+	levels := sh.LevelStats().Levels
+	assert.Zero(t, levels[11], "heights follow log2 of the size")
+
+	// Shrinking leaves tall nodes behind; lookups must still find everything.
+	for k := 0; k < 1000; k++ {
+		if k%50 != 0 {
+			sh.Remove(2 * k)
+		}
+	}
+	assert.Equal(t, 20, sh.Len())
+	for i, e := range sh.RangeAll() {
+		assert.Equal(t, 100*i, e.Key)
+		assert.Equal(t, i, sh.Rank(e.Key))
+		got, ok := sh.Select(i)
+		assert.True(t, ok)
+		assert.Equal(t, e, got)
+		ceil, ok := sh.Ceil(e.Key - 1)
+		assert.True(t, ok)
+		assert.Equal(t, e, ceil)
+		if i > 0 {
+			pred, ok := sh.Pred(e.Key)
+			assert.True(t, ok)
+			assert.Equal(t, 100*(i-1), pred.Key)
+		}
+	}
+	assert.Equal(t, 5, sh.RangeCount(0, 450))
+}
+
+func TestSkipHashAppendMode(t *testing.T) {
+	sh := New[int, int](WithAppendMode(), WithRandSource(rand.NewSource(3)))
+	ref := New[int, int](WithRandSource(rand.NewSource(3)))
+	r := rand.New(rand.NewSource(4))
+	next := 0
+	for range 3000 {
+		var key int
+		switch r.Intn(10) {
+		case 0:
+			key = r.Intn(next + 1)
+		case 1:
+			key = max(next-1, 0)
+		default:
+			next += 1 + r.Intn(3)
+			key = next
+		}
+		if r.Intn(4) == 0 {
+			sh.Remove(key)
+			ref.Remove(key)
+		} else {
+			sh.Store(key, key)
+			ref.Store(key, key)
+		}
+	}
+	checkSpans(t, sh)
+	assert.Equal(t, ref.RangeAll(), sh.RangeAll())
+	for _, e := range sh.Sample(50) {
+		assert.Equal(t, ref.Rank(e.Key), sh.Rank(e.Key))
+	}
+}
+
+func TestSkipHashRangeAppend(t *testing.T) {
+	sh := New[int, int]()
+	for k := range 100 {
+		sh.Store(k, k)
+	}
+	buf := sh.RangeAppend([]Entry[int, int]{{Key: -1}}, 10, 12)
+	assert.Equal(t, []Entry[int, int]{{-1, 0}, {10, 10}, {11, 11}, {12, 12}}, buf)
+	assert.Equal(t, buf[:1], sh.RangeAppend(buf[:1], 12, 10))
+
+	buf = make([]Entry[int, int], 0, 64)
+	allocs := testing.AllocsPerRun(100, func() {
+		buf = sh.RangeAppend(buf[:0], 20, 80)
+	})
+	assert.Zero(t, allocs)
+	assert.Equal(t, sh.Range(20, 80), buf)
+}
+
+func TestSkipHashRangeKeysValues(t *testing.T) {
+	sh := New[int, string]()
+	for k := range 10 {
+		sh.Store(k, strings.Repeat("x", k))
+	}
+	sh.Remove(4)
+	assert.Equal(t, []int{2, 3, 5}, sh.RangeKeys(2, 5))
+	assert.Equal(t, []string{"xx", "xxx", "xxxxx"}, sh.RangeValues(2, 5))
+	assert.Nil(t, sh.RangeKeys(5, 2))
+	assert.Nil(t, sh.RangeValues(20, 30))
+}
+
+func TestSkipHashRangeWhile(t *testing.T) {
+	sh := New[int, string]()
+	for k := range 20 {
+		sh.Store(k, strings.Repeat("x", k))
+	}
+	var keys []int
+	size := 0
+	sh.RangeWhile(5, func(k int, v string) bool {
+		if size+len(v) > 20 {
+			return false
+		}
+		size += len(v)
+		keys = append(keys, k)
+		sh.Remove(k + 1) // the scan keeps its starting view
+		return true
+	})
+	assert.Equal(t, []int{5, 6, 7}, keys)
+	assert.False(t, sh.Contains(8))
+
+	keys = keys[:0]
+	sh.RangeWhile(18, func(k int, _ string) bool {
+		keys = append(keys, k)
+		return true
+	})
+	assert.Equal(t, []int{18, 19}, keys)
+}
@@ -0,0 +1,92 @@
+package skiphash
+
+import "sync"
+
+// SnapshotView is a read-only, point-in-time view of a SkipHash. All reads
+// through one view observe the same range-coordinator version, no matter
+// how many writes happen meanwhile. A view pins the nodes it can see, so it
+// must be closed once it is no longer needed.
+type SnapshotView[K any, V any] struct {
+	sh      *SkipHash[K, V]
+	ver     uint64
+	release sync.Once
+}
+
+// Snapshot registers a new version with the range coordinator and returns a
+// view of the entries live at that version.
+func (sh *SkipHash[K, V]) Snapshot() *SnapshotView[K, V] {
+	sh.unsupportedInFineGrained()
+	sh.faultInAll()
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	return &SnapshotView[K, V]{sh: sh, ver: sh.rqc.onRangeLocked()}
+}
+
+// Version returns the range-coordinator version the view reflects.
+func (s *SnapshotView[K, V]) Version() uint64 {
+	return s.ver
+}
+
+// Close releases the view. Removed nodes it was pinning become eligible for
+// physical unlinking. Close is idempotent; the view must not be used after.
+func (s *SnapshotView[K, V]) Close() {
+	s.release.Do(func() {
+		s.sh.mu.Lock()
+		s.sh.rqc.afterRangeLocked(s.sh, s.ver)
+		s.sh.mu.Unlock()
+	})
+}
+
+// Get returns the value key had when the view was taken.
+func (s *SnapshotView[K, V]) Get(key K) (V, bool) {
+	sh := s.sh
+	key = sh.normalizeKey(key)
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+
+	for node := sh.lowerBoundLocked(key); node != sh.tail && sh.compare(node.key, key) == 0; node = node.next[0] {
+		if sh.visibleAtLocked(node, s.ver) {
+			return *node.value.Load(), true
+		}
+	}
+	var zero V
+	return zero, false
+}
+
+// Range returns the entries in [low, high] as of the view's version.
+func (s *SnapshotView[K, V]) Range(low, high K) []Entry[K, V] {
+	sh := s.sh
+	low, high = sh.normalizeKey(low), sh.normalizeKey(high)
+	if sh.compare(low, high) > 0 {
+		return nil
+	}
+
+	sh.mu.RLock()
+	start := sh.lowerBoundLocked(low)
+	for start != sh.tail && !sh.visibleAtLocked(start, s.ver) {
+		start = start.next[0]
+	}
+	sh.mu.RUnlock()
+
+	return sh.collectAtVersion(nil, start, high, s.ver)
+}
+
+// Len returns the number of entries in the view. It walks the base level.
+func (s *SnapshotView[K, V]) Len() int {
+	sh := s.sh
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+
+	n := 0
+	for node := sh.head.next[0]; node != sh.tail; node = node.next[0] {
+		if sh.visibleAtLocked(node, s.ver) {
+			n++
+		}
+	}
+	return n
+}
+
+// visibleAtLocked reports whether node was live at version ver.
+func (sh *SkipHash[K, V]) visibleAtLocked(node *slNode[K, V], ver uint64) bool {
+	return node.iTime < ver && (node.rTime == 0 || node.rTime >= ver)
+}
@@ -0,0 +1,217 @@
+package skiphash
+
+import (
+	"context"
+	"math/rand"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSkipHashSnapshot(t *testing.T) {
+	sh := New[int, string](WithRandSource(rand.NewSource(17)))
+	for i := range 10 {
+		sh.Insert(i, "old")
+	}
+
+	snap := sh.Snapshot()
+	defer snap.Close()
+
+	sh.Store(3, "new")
+	sh.Remove(4)
+	sh.Insert(42, "new")
+	sh.Remove(5)
+	sh.Insert(5, "again")
+
+	got, ok := snap.Get(3)
+	assert.True(t, ok)
+	assert.Equal(t, "old", got, "in-place updates must not leak into the view")
+	got, ok = snap.Get(4)
+	assert.True(t, ok)
+	assert.Equal(t, "old", got)
+	got, _ = snap.Get(5)
+	assert.Equal(t, "old", got)
+	_, ok = snap.Get(42)
+	assert.False(t, ok)
+
+	entries := snap.Range(2, 50)
+	require.Len(t, entries, 8)
+	for _, e := range entries {
+		assert.Equal(t, "old", e.Value, "key %d", e.Key)
+	}
+	assert.Equal(t, 10, snap.Len())
+
+	got, _ = sh.Get(3)
+	assert.Equal(t, "new", got)
+	assert.Equal(t, 10, sh.Len())
+	assert.Len(t, sh.Range(0, 100), 10)
+	checkSpans(t, sh)
+}
+
+func TestSkipHashSnapshotCloseReclaims(t *testing.T) {
+	sh := New[int, int]()
+	for i := range 10 {
+		sh.Insert(i, i)
+	}
+	snap := sh.Snapshot()
+	for i := range 10 {
+		sh.Remove(i)
+	}
+	assert.Equal(t, 10, snap.Len())
+	assert.NotEqual(t, sh.tail, sh.head.next[0], "removed nodes are pinned by the view")
+
+	snap.Close()
+	snap.Close()
+	assert.Equal(t, sh.tail, sh.head.next[0], "closing the view unlinks pinned nodes")
+}
+
+func TestSkipHashSnapshotConcurrentWriters(t *testing.T) {
+	sh := New[int, int](WithRandSource(rand.NewSource(18)))
+	for i := range 500 {
+		sh.Insert(i, 0)
+	}
+	snap := sh.Snapshot()
+	defer snap.Close()
+
+	var wg sync.WaitGroup
+	for w := range 4 {
+		wg.Go(func() {
+			r := rand.New(rand.NewSource(int64(w)))
+			for range 2000 {
+				k := r.Intn(600)
+				if r.Intn(2) == 0 {
+					sh.Store(k, w+1)
+				} else {
+					sh.Remove(k)
+				}
+			}
+		})
+	}
+	for range 20 {
+		entries := snap.Range(0, 1000)
+		assert.Len(t, entries, 500)
+		for _, e := range entries {
+			assert.Equal(t, 0, e.Value)
+		}
+	}
+	wg.Wait()
+	assert.Equal(t, 500, snap.Len())
+	checkSpans(t, sh)
+}
+
+func TestSkipHashTombstonesAll(t *testing.T) {
+	sh := New[int, string]()
+	for i := range 5 {
+		sh.Insert(i, "v")
+	}
+	assert.Empty(t, sh.TombstonesAll())
+
+	sh.Remove(0)
+	snap := sh.Snapshot()
+	sh.Remove(3)
+	sh.Remove(1)
+	sh.Store(2, "w")
+
+	tombs := sh.TombstonesAll()
+	require.Len(t, tombs, 3)
+	assert.Equal(t, []int{1, 2, 3}, []int{tombs[0].Key, tombs[1].Key, tombs[2].Key})
+	for _, tomb := range tombs {
+		assert.Equal(t, snap.Version(), tomb.BlockedBy)
+		assert.Equal(t, snap.Version(), tomb.RemovedAt)
+		assert.Equal(t, "v", tomb.Value)
+	}
+
+	snap.Close()
+	assert.Empty(t, sh.TombstonesAll())
+}
+
+func TestSkipHashCompact(t *testing.T) {
+	sh := New[int, string]()
+	for i := range 5 {
+		sh.Insert(i, "v")
+	}
+	snap := sh.Snapshot()
+	sh.Remove(1)
+	sh.Remove(3)
+	assert.Zero(t, sh.Compact(), "tombstones still belong to the snapshot")
+	assert.Len(t, sh.TombstonesAll(), 2)
+
+	// Drop the snapshot's registration without running its deferred
+	// unstitching, leaving tombstones that nothing owns.
+	sh.mu.Lock()
+	sh.rqc.head, sh.rqc.tail = nil, nil
+	clear(sh.rqc.byVersion)
+	sh.mu.Unlock()
+
+	assert.Equal(t, 2, sh.Compact())
+	assert.Empty(t, sh.TombstonesAll())
+	assert.Equal(t, []int{0, 2, 4}, keysOf(sh.RangeAll()))
+	checkSpans(t, sh)
+	snap.Close()
+}
+
+func TestTombstoneGCBacklogLimit(t *testing.T) {
+	sh := New[int, int](WithTombstoneGC(5, 0))
+	for i := range 20 {
+		sh.Insert(i, i)
+	}
+	snap := sh.Snapshot()
+	defer snap.Close()
+
+	for i := range 5 {
+		sh.Remove(i)
+	}
+	assert.Len(t, sh.TombstonesAll(), 5)
+	sh.Remove(5)
+	assert.Empty(t, sh.TombstonesAll(), "exceeding the backlog unlinks it")
+	assert.Equal(t, uint64(6), sh.rqc.forced)
+	assert.Zero(t, sh.rqc.backlog)
+	checkSpans(t, sh)
+
+	// The snapshot no longer sees the unlinked entries.
+	_, ok := snap.Get(5)
+	assert.False(t, ok)
+	assert.Equal(t, 14, sh.Len())
+}
+
+func TestTombstoneGCMaxAge(t *testing.T) {
+	sh := New[int, int](WithTombstoneGC(0, 20*time.Millisecond))
+	for i := range 5 {
+		sh.Insert(i, i)
+	}
+	snap := sh.Snapshot()
+	defer snap.Close()
+
+	sh.Remove(0)
+	assert.Len(t, sh.TombstonesAll(), 1)
+	time.Sleep(30 * time.Millisecond)
+	sh.Remove(1)
+	assert.Empty(t, sh.TombstonesAll())
+}
+
+func TestTombstoneGCDuringWalk(t *testing.T) {
+	sh := New[int, int](WithTombstoneGC(2, 0))
+	for i := 1; i <= 100; i++ {
+		sh.Insert(i, i)
+	}
+
+	var seen []int
+	for k, v := range sh.RangeIterContext(context.Background(), 1, 100) {
+		require.Equal(t, k, v)
+		seen = append(seen, k)
+		if k == 10 {
+			for i := 50; i <= 90; i++ {
+				sh.Remove(i)
+			}
+		}
+	}
+	require.True(t, slices.IsSorted(seen))
+	require.Equal(t, 100, seen[len(seen)-1])
+	require.Less(t, len(seen), 100, "the walk skips entries unlinked ahead of it")
+	require.Nil(t, sh.rqc.tail)
+	checkSpans(t, sh)
+}
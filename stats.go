@@ -0,0 +1,99 @@
+package skiphash
+
+// Stats describes the live entries, the tombstones still linked into the
+// list and the range coordinator that owns them.
+type Stats struct {
+	Live int
+	// Tombstones counts removed nodes that are still linked: the deferred
+	// backlog plus removed entries kept by WithHistory.
+	Tombstones int
+	// Deferred counts removed nodes waiting for an open range operation or
+	// snapshot to finish before they are unstitched.
+	Deferred     int
+	ActiveRanges int
+	// OldestRange is the version of the oldest open range operation or
+	// snapshot, which every deferred node ultimately waits on, or 0.
+	OldestRange uint64
+	// Version is the current range-coordinator version.
+	Version uint64
+	// ForcedUnlinks counts deferred nodes unlinked early by WithTombstoneGC.
+	ForcedUnlinks uint64
+}
+
+// Stats returns a consistent set of counters in O(1).
+func (sh *SkipHash[K, V]) Stats() Stats {
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+	r := sh.rqc
+	stats := Stats{
+		Live:          sh.len,
+		Tombstones:    r.backlog + len(sh.retained),
+		Deferred:      r.backlog,
+		ActiveRanges:  len(r.byVersion),
+		Version:       r.counter,
+		ForcedUnlinks: r.forced,
+	}
+	if r.head != nil {
+		stats.OldestRange = r.head.ver
+	}
+	if sh.fine != nil {
+		stats.Live = int(sh.fine.len.Load())
+	}
+	if sh.tier != nil {
+		stats.Live += sh.tier.spilled
+	}
+	return stats
+}
+
+// LevelStats describes the shape of the list.
+type LevelStats struct {
+	MaxLevel int
+	// Levels[i] is the number of live nodes linked at level i, so
+	// Levels[0] is the live count.
+	Levels    []int
+	AvgHeight float64
+	// SearchSteps estimates the average number of nodes a search visits:
+	// one descent per level in use plus, on every level, half the average
+	// run of nodes between two nodes of the level above. It is lowest when
+	// each level holds about half the nodes of the one below.
+	SearchSteps float64
+}
+
+// LevelStats walks the base level once to report per-level node counts and
+// the expected search cost they imply.
+func (sh *SkipHash[K, V]) LevelStats() LevelStats {
+	sh.unsupportedInFineGrained()
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+
+	stats := LevelStats{MaxLevel: sh.maxLevel, Levels: make([]int, sh.maxLevel)}
+	total := 0
+	for node := sh.head.next[0]; node != sh.tail; node = node.next[0] {
+		if node.rTime != 0 {
+			continue
+		}
+		for level := range int(node.height) {
+			stats.Levels[level]++
+		}
+		total += int(node.height)
+	}
+	if stats.Levels[0] == 0 {
+		return stats
+	}
+	stats.AvgHeight = float64(total) / float64(stats.Levels[0])
+
+	used := 0
+	for used < sh.maxLevel && stats.Levels[used] > 0 {
+		used++
+	}
+	steps := float64(used)
+	for level := range used {
+		above := 1 // the head stands in for the level above the top one
+		if level+1 < used {
+			above = stats.Levels[level+1]
+		}
+		steps += float64(stats.Levels[level]) / float64(above) / 2
+	}
+	stats.SearchSteps = steps
+	return stats
+}
@@ -0,0 +1,58 @@
+package skiphash
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStats(t *testing.T) {
+	sh := New[int, int]()
+	for i := range 10 {
+		sh.Insert(i, i)
+	}
+	require.Equal(t, Stats{Live: 10, Version: 1}, sh.Stats())
+
+	first := sh.Snapshot()
+	second := sh.Snapshot()
+	sh.Remove(1)
+	sh.Remove(2)
+	sh.Store(3, 30)
+
+	stats := sh.Stats()
+	require.Equal(t, 8, stats.Live)
+	require.Equal(t, 3, stats.Deferred, "the update replaced a pinned node")
+	require.Equal(t, 3, stats.Tombstones)
+	require.Len(t, sh.TombstonesAll(), stats.Tombstones)
+	require.Equal(t, 2, stats.ActiveRanges)
+	require.Equal(t, first.Version(), stats.OldestRange)
+	require.Equal(t, second.Version(), stats.Version)
+
+	second.Close()
+	require.Equal(t, 3, sh.Stats().Deferred, "the older snapshot still owns them")
+	first.Close()
+	stats = sh.Stats()
+	require.Zero(t, stats.Deferred)
+	require.Zero(t, stats.Tombstones)
+	require.Zero(t, stats.OldestRange)
+}
+
+func TestLevelStats(t *testing.T) {
+	require.Equal(t, LevelStats{MaxLevel: DefaultMaxLevel, Levels: make([]int, DefaultMaxLevel)}, New[int, int]().LevelStats())
+
+	entries := make([]Entry[int, int], 15)
+	for i := range entries {
+		entries[i] = Entry[int, int]{Key: i}
+	}
+	// NewFromSorted assigns levels deterministically: 15 entries give
+	// 15, 7, 3 and 1 nodes on the first four levels.
+	sh, err := NewFromSorted(entries, WithMaxLevel(6))
+	require.NoError(t, err)
+	sh.Insert(100, 0)
+	sh.Remove(100)
+
+	stats := sh.LevelStats()
+	require.Equal(t, []int{15, 7, 3, 1, 0, 0}, stats.Levels)
+	require.InDelta(t, 26.0/15, stats.AvgHeight, 1e-9)
+	require.InDelta(t, 4+(15.0/7+7.0/3+3.0+1)/2, stats.SearchSteps, 1e-9)
+}
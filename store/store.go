@@ -0,0 +1,240 @@
+// Package store is a durable, ordered key-value store backed by a SkipHash.
+//
+// Every write is appended to the SkipHash write-ahead log (see
+// skiphash.WithWAL) before it returns; the log is periodically folded into a
+// checkpoint. Open recovers the contents with skiphash.Recover, discarding a
+// torn final record left by a crash.
+package store
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/baxromumarov/skiphash"
+)
+
+// DefaultCheckpointEvery is the number of logged writes after which the log
+// is folded into a new checkpoint.
+const DefaultCheckpointEvery = 10_000
+
+// ErrClosed is returned by writes to a closed Store.
+var ErrClosed = errors.New("store: closed")
+
+type config struct {
+	syncWrites      bool
+	checkpointEvery int
+	janitorInterval time.Duration
+	mapOptions      []skiphash.Option
+}
+
+type Option func(*config)
+
+// WithSyncWrites controls whether every write is fsynced before it returns.
+// It defaults to true; without it a crash can lose the most recent writes but
+// never corrupts the store, and a failed log write is only reported by the
+// next Checkpoint or Close.
+func WithSyncWrites(sync bool) Option {
+	return func(cfg *config) {
+		cfg.syncWrites = sync
+	}
+}
+
+// WithCheckpointEvery sets how many logged writes trigger a checkpoint.
+func WithCheckpointEvery(n int) Option {
+	return func(cfg *config) {
+		if n > 0 {
+			cfg.checkpointEvery = n
+		}
+	}
+}
+
+// WithJanitor removes expired entries in the background every interval.
+func WithJanitor(interval time.Duration) Option {
+	return func(cfg *config) {
+		cfg.janitorInterval = interval
+	}
+}
+
+// WithMapOptions passes options through to the underlying SkipHash. They
+// must be the same every time the store is opened.
+func WithMapOptions(opts ...skiphash.Option) Option {
+	return func(cfg *config) {
+		cfg.mapOptions = append(cfg.mapOptions, opts...)
+	}
+}
+
+// Stats reports the state of a Store.
+type Stats struct {
+	Entries int
+	// LogRecords counts the writes logged since the last checkpoint, or
+	// since Open if there was none.
+	LogRecords     int
+	Checkpoints    int
+	LastCheckpoint time.Time
+	Janitor        skiphash.JanitorStats
+}
+
+// Store is safe for concurrent use. Writes are serialized; reads go straight
+// to the in-memory SkipHash.
+type Store[K cmp.Ordered, V any] struct {
+	cfg config
+	sh  *skiphash.SkipHash[K, V]
+
+	mu             sync.Mutex
+	logRecords     int
+	checkpoints    int
+	lastCheckpoint time.Time
+	closed         bool
+	stopJanitor    context.CancelFunc
+}
+
+// Open opens the store in dir, creating it if needed, and recovers its
+// contents.
+func Open[K cmp.Ordered, V any](dir string, opts ...Option) (*Store[K, V], error) {
+	cfg := config{syncWrites: true, checkpointEvery: DefaultCheckpointEvery}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&cfg)
+		}
+	}
+	sh, err := skiphash.Recover[K, V](dir, cfg.mapOptions...)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Store[K, V]{cfg: cfg, sh: sh}
+	if cfg.janitorInterval > 0 {
+		var ctx context.Context
+		ctx, s.stopJanitor = context.WithCancel(context.Background())
+		s.sh.StartJanitor(ctx, cfg.janitorInterval)
+	}
+	return s, nil
+}
+
+func (s *Store[K, V]) Get(key K) (V, bool) {
+	return s.sh.Get(key)
+}
+
+func (s *Store[K, V]) Range(low, high K) []skiphash.Entry[K, V] {
+	return s.sh.Range(low, high)
+}
+
+func (s *Store[K, V]) Len() int {
+	return s.sh.Len()
+}
+
+// TTL returns the time left before key expires; see SkipHash.TTL.
+func (s *Store[K, V]) TTL(key K) (time.Duration, bool) {
+	return s.sh.TTL(key)
+}
+
+// Put stores value under key. It fails if a map option such as a quota
+// rejects the write.
+func (s *Store[K, V]) Put(key K, value V) error {
+	return s.write(func() (bool, error) {
+		_, err := s.sh.TryStore(key, value)
+		return true, err
+	})
+}
+
+// PutTTL stores value under key until ttl elapses.
+func (s *Store[K, V]) PutTTL(key K, value V, ttl time.Duration) error {
+	return s.write(func() (bool, error) {
+		_, err := s.sh.TryStoreTTL(key, value, ttl)
+		return true, err
+	})
+}
+
+// Delete removes key and reports whether it was present.
+func (s *Store[K, V]) Delete(key K) (bool, error) {
+	var removed bool
+	err := s.write(func() (bool, error) {
+		removed = s.sh.Remove(key)
+		return removed, nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return removed, nil
+}
+
+// write applies a change, which the SkipHash logs, and makes it durable.
+// apply reports whether it logged anything.
+func (s *Store[K, V]) write(apply func() (bool, error)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return ErrClosed
+	}
+	logged, err := apply()
+	if err != nil || !logged {
+		return err
+	}
+	if s.cfg.syncWrites {
+		if err := s.sh.SyncWAL(); err != nil {
+			return err
+		}
+	}
+	s.logRecords++
+	// The write is already in the log, so a failed checkpoint is retried
+	// on the next write rather than reported.
+	if s.logRecords >= s.cfg.checkpointEvery {
+		_ = s.checkpointLocked()
+	}
+	return nil
+}
+
+// Checkpoint writes the current contents to a new checkpoint and starts a
+// new log segment; see SkipHash.CheckpointWAL.
+func (s *Store[K, V]) Checkpoint() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return ErrClosed
+	}
+	return s.checkpointLocked()
+}
+
+func (s *Store[K, V]) checkpointLocked() error {
+	if err := s.sh.CheckpointWAL(); err != nil {
+		return err
+	}
+	s.logRecords = 0
+	s.checkpoints++
+	s.lastCheckpoint = time.Now()
+	return nil
+}
+
+func (s *Store[K, V]) Stats() Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return Stats{
+		Entries:        s.sh.Len(),
+		LogRecords:     s.logRecords,
+		Checkpoints:    s.checkpoints,
+		LastCheckpoint: s.lastCheckpoint,
+		Janitor:        s.sh.JanitorStats(),
+	}
+}
+
+// Close stops the janitor and closes the log, reporting the first error the
+// log hit. The store stays readable.
+func (s *Store[K, V]) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	if s.stopJanitor != nil {
+		s.stopJanitor()
+	}
+	err := s.sh.SyncWAL()
+	if closeErr := s.sh.CloseWAL(); err == nil {
+		err = closeErr
+	}
+	return err
+}
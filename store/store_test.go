@@ -0,0 +1,94 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/baxromumarov/skiphash"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreRecovers(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open[string, int](dir, WithCheckpointEvery(4))
+	require.NoError(t, err)
+
+	for i, k := range []string{"a", "b", "c", "d", "e"} {
+		require.NoError(t, s.Put(k, i))
+	}
+	removed, err := s.Delete("b")
+	require.NoError(t, err)
+	assert.True(t, removed)
+	require.NoError(t, s.PutTTL("gone", 1, -time.Second))
+	require.NoError(t, s.PutTTL("later", 2, time.Hour))
+	stats := s.Stats()
+	assert.Equal(t, 2, stats.Checkpoints)
+	assert.Zero(t, stats.LogRecords)
+	require.NoError(t, s.Put("f", 5))
+	require.NoError(t, s.Close())
+	assert.ErrorIs(t, s.Put("x", 0), ErrClosed)
+
+	s, err = Open[string, int](dir)
+	require.NoError(t, err)
+	defer s.Close()
+	assert.Equal(t, []skiphash.Entry[string, int]{{Key: "a", Value: 0}, {Key: "c", Value: 2}, {Key: "d", Value: 3}, {Key: "e", Value: 4}, {Key: "f", Value: 5}, {Key: "later", Value: 2}}, s.Range("a", "z"))
+	ttl, ok := s.TTL("later")
+	require.True(t, ok)
+	assert.Greater(t, ttl, 59*time.Minute)
+}
+
+func TestStoreDiscardsTornTail(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open[int, string](dir)
+	require.NoError(t, err)
+	require.NoError(t, s.Put(1, "one"))
+	require.NoError(t, s.Put(2, "two"))
+	require.NoError(t, s.Close())
+
+	segments, err := filepath.Glob(filepath.Join(dir, "wal-*.log"))
+	require.NoError(t, err)
+	path := segments[len(segments)-1]
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.NoError(t, os.Truncate(path, info.Size()-3))
+
+	s, err = Open[int, string](dir)
+	require.NoError(t, err)
+	assert.Equal(t, 1, s.Len())
+	require.NoError(t, s.Put(3, "three"))
+	require.NoError(t, s.Close())
+
+	s, err = Open[int, string](dir)
+	require.NoError(t, err)
+	defer s.Close()
+	assert.Equal(t, []skiphash.Entry[int, string]{{Key: 1, Value: "one"}, {Key: 3, Value: "three"}}, s.Range(0, 10))
+}
+
+func TestStoreCheckpointEmpty(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open[int, int](dir, WithJanitor(time.Millisecond))
+	require.NoError(t, err)
+	require.NoError(t, s.Checkpoint())
+	require.NoError(t, s.Close())
+
+	s, err = Open[int, int](dir)
+	require.NoError(t, err)
+	defer s.Close()
+	assert.Zero(t, s.Len())
+}
+
+func TestStorePutReportsRejection(t *testing.T) {
+	dir := t.TempDir()
+	quota := skiphash.WithQuota(func(k string) string { return k[:1] }, map[string]int{"a": 1})
+	s, err := Open[string, int](dir, WithMapOptions(quota))
+	require.NoError(t, err)
+	defer s.Close()
+	require.NoError(t, s.Put("a1", 1))
+	var qerr *skiphash.QuotaError
+	assert.ErrorAs(t, s.Put("a2", 2), &qerr)
+	assert.ErrorAs(t, s.PutTTL("a3", 3, time.Hour), &qerr)
+	assert.Equal(t, 1, s.Stats().LogRecords)
+}
@@ -0,0 +1,97 @@
+package skiphash
+
+import "iter"
+
+// SubMapView is a live view of the entries of a SkipHash whose keys fall
+// within its bounds. It stores only the bounds: every read goes to the
+// parent, so it reflects writes made after the view was created. Bounds are
+// inclusive, and a missing bound is open.
+type SubMapView[K any, V any] struct {
+	sh        *SkipHash[K, V]
+	low, high *K
+}
+
+// SubMap returns a view of the keys in [low, high].
+func (sh *SkipHash[K, V]) SubMap(low, high K) *SubMapView[K, V] {
+	low, high = sh.normalizeKey(low), sh.normalizeKey(high)
+	return &SubMapView[K, V]{sh: sh, low: &low, high: &high}
+}
+
+// HeadMap returns a view of the keys at or before high.
+func (sh *SkipHash[K, V]) HeadMap(high K) *SubMapView[K, V] {
+	high = sh.normalizeKey(high)
+	return &SubMapView[K, V]{sh: sh, high: &high}
+}
+
+// TailMap returns a view of the keys at or after low.
+func (sh *SkipHash[K, V]) TailMap(low K) *SubMapView[K, V] {
+	low = sh.normalizeKey(low)
+	return &SubMapView[K, V]{sh: sh, low: &low}
+}
+
+// inBounds reports whether the normalized key lies within the view.
+func (v *SubMapView[K, V]) inBounds(key K) bool {
+	return (v.low == nil || v.sh.compare(key, *v.low) >= 0) &&
+		(v.high == nil || v.sh.compare(key, *v.high) <= 0)
+}
+
+// empty reports whether the bounds admit no key at all.
+func (v *SubMapView[K, V]) empty() bool {
+	return v.low != nil && v.high != nil && v.sh.compare(*v.low, *v.high) > 0
+}
+
+func (v *SubMapView[K, V]) Get(key K) (V, bool) {
+	if !v.inBounds(v.sh.normalizeKey(key)) {
+		var zero V
+		return zero, false
+	}
+	return v.sh.Get(key)
+}
+
+func (v *SubMapView[K, V]) Contains(key K) bool {
+	return v.inBounds(v.sh.normalizeKey(key)) && v.sh.Contains(key)
+}
+
+// Range returns the entries in [low, high] that also lie within the view.
+func (v *SubMapView[K, V]) Range(low, high K) []Entry[K, V] {
+	low, high = v.sh.normalizeKey(low), v.sh.normalizeKey(high)
+	if v.low != nil && v.sh.compare(low, *v.low) < 0 {
+		low = *v.low
+	}
+	if v.high != nil && v.sh.compare(high, *v.high) > 0 {
+		high = *v.high
+	}
+	return v.sh.Range(low, high)
+}
+
+// All iterates over the entries of the view in key order, as of the start
+// of the iteration; see RangeIterContext.
+func (v *SubMapView[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		if !v.empty() {
+			v.sh.walkBounded(v.low, v.high, yield)
+		}
+	}
+}
+
+// Len returns the number of live keys within the view in O(log n).
+func (v *SubMapView[K, V]) Len() int {
+	sh := v.sh
+	if v.empty() {
+		return 0
+	}
+	if sh.fine != nil {
+		return len(sh.fine.entries(v.low, v.high))
+	}
+	sh.faultInAll()
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+	n := sh.len
+	if v.high != nil {
+		n = sh.rankLocked(*v.high, true)
+	}
+	if v.low != nil {
+		n -= sh.rankLocked(*v.low, false)
+	}
+	return n
+}
@@ -0,0 +1,63 @@
+package skiphash
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubMapView(t *testing.T) {
+	sh := New[int, int]()
+	for i := range 10 {
+		sh.Store(i, i*10)
+	}
+
+	sub := sh.SubMap(3, 6)
+	require.Equal(t, 4, sub.Len())
+	_, ok := sub.Get(7)
+	require.False(t, ok)
+	v, ok := sub.Get(4)
+	require.True(t, ok)
+	require.Equal(t, 40, v)
+	require.False(t, sub.Contains(2))
+	require.Equal(t, []Entry[int, int]{{Key: 5, Value: 50}, {Key: 6, Value: 60}}, sub.Range(5, 100))
+
+	// The view follows the parent.
+	sh.Remove(4)
+	sh.Store(5, -5)
+	var keys, values []int
+	for k, v := range sub.All() {
+		keys = append(keys, k)
+		values = append(values, v)
+	}
+	require.Equal(t, []int{3, 5, 6}, keys)
+	require.Equal(t, []int{30, -5, 60}, values)
+	require.Equal(t, 3, sub.Len())
+	require.Nil(t, sh.rqc.tail)
+}
+
+func TestHeadAndTailMap(t *testing.T) {
+	sh := New[int, int]()
+	for i := range 10 {
+		sh.Store(i, i)
+	}
+
+	head := sh.HeadMap(2)
+	require.Equal(t, 3, head.Len())
+	var keys []int
+	for k := range head.All() {
+		keys = append(keys, k)
+	}
+	require.Equal(t, []int{0, 1, 2}, keys)
+
+	tail := sh.TailMap(8)
+	require.Equal(t, 2, tail.Len())
+	require.Equal(t, []int{8, 9}, keysOf(tail.Range(0, 100)))
+	sh.Store(100, 100)
+	require.Equal(t, 3, tail.Len())
+
+	require.Zero(t, sh.SubMap(5, 1).Len())
+	for range sh.SubMap(5, 1).All() {
+		t.Fatal("inverted view yielded an entry")
+	}
+}
@@ -0,0 +1,489 @@
+package skiphash
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sync/atomic"
+	"time"
+)
+
+// WithTiering enables spilling cold key ranges to sorted segment files in
+// dir. Spilled entries stay logically present: any operation that touches
+// their key range transparently reads the segment back into memory first.
+// Keys and values are written with the codecs set by WithKeyCodec and
+// WithValueCodec, as in snapshots, along with the versions, timestamps and
+// history of each entry, which it gets back when it is read in.
+func WithTiering(dir string) Option {
+	return func(cfg *config) {
+		if dir != "" {
+			cfg.tierDir = dir
+		}
+	}
+}
+
+// TieringStats describes the on-disk tier.
+type TieringStats struct {
+	Segments       int
+	SpilledEntries int
+	// LastError is the most recent failure to read a segment back or to
+	// migrate the values LoadFrom kept. The segment stays and its entries
+	// are invisible until a later access succeeds in loading it.
+	LastError error
+}
+
+type tier[K any, V any] struct {
+	dir      string
+	segments []*segment[K]
+	spilled  int
+	lastErr  error
+
+	// pending mirrors len(segments) so readers can skip the write lock when
+	// nothing is spilled.
+	pending atomic.Int32
+}
+
+// segment is a run of consecutive keys stored on disk, or kept in memory
+// by LoadFrom until their values are migrated. No live in-memory key ever
+// falls inside [first, last].
+type segment[K any] struct {
+	first, last K
+	count       int
+	path        string
+	unmigrated  *unmigrated[K]
+}
+
+func newTier[K any, V any](dir string) *tier[K, V] {
+	return &tier[K, V]{dir: dir}
+}
+
+// SpillCold moves every run of at least minRun consecutive live entries that
+// were not accessed within idle into its own segment file and returns how
+// many entries were spilled. Entries with a TTL always stay in memory. Runs
+// are written out without holding the lock, and a run that a writer touched
+// meanwhile, or that a range scan or snapshot opened meanwhile can see, stays
+// in memory. It returns an error if tiering is not enabled.
+func (sh *SkipHash[K, V]) SpillCold(idle time.Duration, minRun int) (int, error) {
+	if sh.tier == nil || sh.tier.dir == "" {
+		return 0, errors.New("skiphash: tiering is not enabled")
+	}
+	minRun = max(minRun, 1)
+	cutoff := time.Now().Add(-idle).UnixNano()
+
+	if err := os.MkdirAll(sh.tier.dir, 0o755); err != nil {
+		return 0, err
+	}
+	sh.mu.RLock()
+	runs := sh.coldRunsLocked(cutoff, minRun)
+	sh.mu.RUnlock()
+
+	spilled := 0
+	for _, run := range runs {
+		path, err := sh.writeSegment(run)
+		if err != nil {
+			return spilled, err
+		}
+		sh.mu.Lock()
+		ok := sh.spillRunLocked(run, path)
+		sh.mu.Unlock()
+		if !ok {
+			os.Remove(path)
+			continue
+		}
+		spilled += len(run.nodes)
+	}
+	return spilled, nil
+}
+
+// coldRun is a run of cold nodes found by SpillCold, with the values and
+// bookkeeping they had when it was found.
+type coldRun[K any, V any] struct {
+	nodes   []*slNode[K, V]
+	values  []*V
+	entries []Entry[K, V]
+	metas   []entryMeta[V]
+}
+
+func (sh *SkipHash[K, V]) coldRunsLocked(cutoff int64, minRun int) []coldRun[K, V] {
+	var runs []coldRun[K, V]
+	var run coldRun[K, V]
+	flush := func() {
+		if len(run.nodes) >= minRun {
+			runs = append(runs, run)
+		}
+		run = coldRun[K, V]{}
+	}
+	for node := sh.head.next[0]; node != sh.tail; node = node.next[0] {
+		if node.rTime != 0 {
+			continue
+		}
+		if node.lastAccess.Load() > cutoff || node.expiry.at != 0 {
+			flush()
+			continue
+		}
+		value := node.value.Load()
+		run.nodes = append(run.nodes, node)
+		run.values = append(run.values, value)
+		run.entries = append(run.entries, Entry[K, V]{Key: node.key, Value: *value})
+		run.metas = append(run.metas, sh.metaLocked(node))
+	}
+	flush()
+	return runs
+}
+
+// TieringStats reports the current state of the on-disk tier. Entries
+// LoadFrom has yet to migrate count as spilled.
+func (sh *SkipHash[K, V]) TieringStats() TieringStats {
+	if sh.tier == nil {
+		return TieringStats{}
+	}
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+	return TieringStats{
+		Segments:       len(sh.tier.segments),
+		SpilledEntries: sh.tier.spilled,
+		LastError:      sh.tier.lastErr,
+	}
+}
+
+// spillRunLocked replaces run with the segment at path and reports whether
+// it did: it does not if any node of run was removed, updated or given a TTL,
+// or a key was inserted among them, since the segment was written, or if a
+// range operation is open, as it would keep seeing the nodes next to the
+// ones read back in.
+func (sh *SkipHash[K, V]) spillRunLocked(run coldRun[K, V], path string) bool {
+	if sh.rqc.tail != nil {
+		return false
+	}
+	for i, node := range run.nodes {
+		if node.rTime != 0 || node.value.Load() != run.values[i] || node.expiry.at != 0 {
+			return false
+		}
+	}
+	i := 0
+	for node := run.nodes[0]; i < len(run.nodes); node = node.next[0] {
+		if node.rTime != 0 {
+			continue
+		}
+		if node != run.nodes[i] {
+			return false
+		}
+		i++
+	}
+
+	for _, node := range run.nodes {
+		sh.detachLocked(node)
+	}
+	// The entries are not removed, so they must not stay linked for AsOf
+	// reads; their history is in the segment. detachLocked retained them
+	// last, after any it pushed out.
+	if n := min(len(run.nodes), len(sh.retained)); n > 0 && sh.historyDepth > 0 {
+		kept := len(sh.retained) - n
+		for _, node := range sh.retained[kept:] {
+			sh.rqc.afterRemoveLocked(sh, node)
+		}
+		clear(sh.retained[kept:])
+		sh.retained = sh.retained[:kept]
+	}
+	seg := &segment[K]{
+		first: run.entries[0].Key,
+		last:  run.entries[len(run.entries)-1].Key,
+		count: len(run.entries),
+		path:  path,
+	}
+	sh.insertSegmentLocked(seg)
+	sh.tier.spilled += seg.count
+	return true
+}
+
+func (sh *SkipHash[K, V]) insertSegmentLocked(seg *segment[K]) {
+	t := sh.tier
+	i := 0
+	for i < len(t.segments) && sh.compare(t.segments[i].first, seg.first) < 0 {
+		i++
+	}
+	t.segments = append(t.segments, nil)
+	copy(t.segments[i+1:], t.segments[i:])
+	t.segments[i] = seg
+	t.pending.Store(int32(len(t.segments)))
+}
+
+// Segment file layout, all integers as uvarints:
+//
+//	count | count × (len | key bytes | len | value bytes | version |
+//	created | insertedAt | updatedAt | iTime | writtenAt | history count |
+//	history count × (len | value bytes | from | to))
+//
+// Keys and values are in the configured codecs; the whole file is sealed
+// if a snapshot cipher is set.
+
+// writeSegment stores run in a new, uniquely named file in the tier
+// directory so that several instances may share a directory.
+func (sh *SkipHash[K, V]) writeSegment(run coldRun[K, V]) (string, error) {
+	f, err := os.CreateTemp(sh.tier.dir, "segment-*.seg")
+	if err != nil {
+		return "", err
+	}
+	path := f.Name()
+	var w io.WriteCloser = nopWriteCloser{f}
+	if sh.cipher != nil {
+		w = newSealWriter(sh.cipher, f)
+	}
+	bw := bufio.NewWriter(w)
+	err = sh.encodeSegment(bw, run)
+	if err == nil {
+		err = bw.Flush()
+	}
+	if err == nil {
+		err = w.Close()
+	}
+	if err != nil {
+		f.Close()
+		os.Remove(path)
+		return "", fmt.Errorf("skiphash: encode segment: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		os.Remove(path)
+		return "", err
+	}
+	return path, f.Close()
+}
+
+func (sh *SkipHash[K, V]) encodeSegment(w io.Writer, run coldRun[K, V]) error {
+	values := sh.valueCodec
+	buf := binary.AppendUvarint(nil, uint64(len(run.entries)))
+	for i, e := range run.entries {
+		var err error
+		if buf, err = appendRecord(buf, sh.keyCodec, values, e.Key, e.Value); err != nil {
+			return err
+		}
+		m := run.metas[i]
+		for _, n := range []uint64{m.version, m.created, uint64(m.insertedAt), uint64(m.updatedAt), m.iTime, m.writtenAt, uint64(len(m.history))} {
+			buf = binary.AppendUvarint(buf, n)
+		}
+		for _, past := range m.history {
+			vb, err := values.encode(past.value)
+			if err != nil {
+				return fmt.Errorf("skiphash: encode past value of key %v: %w", e.Key, err)
+			}
+			buf = binary.AppendUvarint(binary.AppendUvarint(appendChunk(buf, vb), past.from), past.to)
+		}
+		if len(buf) >= 64<<10 {
+			if _, err := w.Write(buf); err != nil {
+				return err
+			}
+			buf = buf[:0]
+		}
+	}
+	_, err := w.Write(buf)
+	return err
+}
+
+func (sh *SkipHash[K, V]) readSegment(path string) ([]Entry[K, V], []entryMeta[V], error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+	var r io.Reader = f
+	if sh.cipher != nil {
+		r = newSealReader(sh.cipher, f)
+	}
+	entries, metas, err := sh.decodeSegment(bufio.NewReader(r))
+	if err != nil {
+		return nil, nil, fmt.Errorf("skiphash: decode segment %s: %w", path, err)
+	}
+	return entries, metas, nil
+}
+
+func (sh *SkipHash[K, V]) decodeSegment(r *bufio.Reader) ([]Entry[K, V], []entryMeta[V], error) {
+	keys, values := sh.keyCodec, sh.valueCodec
+	count, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	entries := make([]Entry[K, V], 0, min(count, 1<<16))
+	metas := make([]entryMeta[V], 0, min(count, 1<<16))
+	for range count {
+		kb, err := readChunk(r)
+		if err != nil {
+			return nil, nil, err
+		}
+		vb, err := readChunk(r)
+		if err != nil {
+			return nil, nil, err
+		}
+		key, err := keys.decode(kb)
+		if err != nil {
+			return nil, nil, err
+		}
+		value, err := values.decode(vb)
+		if err != nil {
+			return nil, nil, err
+		}
+		var fields [7]uint64
+		for i := range fields {
+			if fields[i], err = binary.ReadUvarint(r); err != nil {
+				return nil, nil, err
+			}
+		}
+		m := entryMeta[V]{
+			version:    fields[0],
+			created:    fields[1],
+			insertedAt: int64(fields[2]),
+			updatedAt:  int64(fields[3]),
+			iTime:      fields[4],
+			writtenAt:  fields[5],
+		}
+		for range fields[6] {
+			vb, err := readChunk(r)
+			if err != nil {
+				return nil, nil, err
+			}
+			var past pastValue[V]
+			if past.value, err = values.decode(vb); err != nil {
+				return nil, nil, err
+			}
+			if past.from, err = binary.ReadUvarint(r); err != nil {
+				return nil, nil, err
+			}
+			if past.to, err = binary.ReadUvarint(r); err != nil {
+				return nil, nil, err
+			}
+			m.history = append(m.history, past)
+		}
+		entries = append(entries, Entry[K, V]{Key: key, Value: value})
+		metas = append(metas, m)
+	}
+	return entries, metas, nil
+}
+
+// faultIn loads every spilled segment overlapping [low, high]. Like
+// faultInAll, it first sweeps expired entries so readers never see them.
+func (sh *SkipHash[K, V]) faultIn(low, high K) {
+	sh.expireDue()
+	if sh.tier == nil || sh.tier.pending.Load() == 0 {
+		return
+	}
+	sh.mu.Lock()
+	defer sh.unlock()
+	sh.faultInLocked(low, high)
+}
+
+// faultInAll loads every spilled segment; ordered operations that are not
+// bounded by a key interval need the whole key space in memory.
+func (sh *SkipHash[K, V]) faultInAll() {
+	sh.expireDue()
+	if sh.tier == nil || sh.tier.pending.Load() == 0 {
+		return
+	}
+	sh.mu.Lock()
+	defer sh.unlock()
+	sh.loadSegmentsLocked(func(*segment[K]) bool { return true })
+}
+
+// faultInNear loads the spilled segment holding the neighbour of key in
+// direction dir, skipping key itself if strict. No in-memory key falls inside
+// a segment, so that is the nearest segment past key unless the in-memory
+// neighbour comes first. An unreadable segment is skipped for the next one,
+// as its entries are invisible.
+func (sh *SkipHash[K, V]) faultInNear(key K, strict bool, dir Direction) {
+	sh.expireDue()
+	if sh.tier == nil || sh.tier.pending.Load() == 0 {
+		return
+	}
+	sh.mu.Lock()
+	defer sh.unlock()
+	t := sh.tier
+	if dir == Descending {
+		near := sh.predecessorLocked(key, strict)
+		for i := len(t.segments) - 1; i >= 0; i-- {
+			seg := t.segments[i]
+			if near != sh.head && sh.compare(seg.last, near.key) < 0 {
+				return
+			}
+			if c := sh.compare(seg.first, key); (c < 0 || c == 0 && !strict) && sh.loadSegmentLocked(i) {
+				return
+			}
+		}
+		return
+	}
+	near := sh.firstLiveGELocked(key)
+	for strict && near != sh.tail && (near.rTime != 0 || sh.compare(near.key, key) == 0) {
+		near = near.next[0]
+	}
+	for i := range t.segments {
+		seg := t.segments[i]
+		if near != sh.tail && sh.compare(seg.first, near.key) > 0 {
+			return
+		}
+		if c := sh.compare(seg.last, key); (c > 0 || c == 0 && !strict) && sh.loadSegmentLocked(i) {
+			return
+		}
+	}
+}
+
+func (sh *SkipHash[K, V]) faultInLocked(low, high K) {
+	sh.expireDueLocked(0)
+	if sh.tier == nil || len(sh.tier.segments) == 0 {
+		return
+	}
+	sh.loadSegmentsLocked(func(seg *segment[K]) bool {
+		return sh.compare(seg.first, high) <= 0 && sh.compare(seg.last, low) >= 0
+	})
+}
+
+func (sh *SkipHash[K, V]) loadSegmentsLocked(match func(*segment[K]) bool) {
+	for i := 0; i < len(sh.tier.segments); i++ {
+		if match(sh.tier.segments[i]) && sh.loadSegmentLocked(i) {
+			i--
+		}
+	}
+}
+
+// loadSegmentLocked reads segment i back into memory and reports whether it
+// could; a segment that cannot be read stays registered.
+func (sh *SkipHash[K, V]) loadSegmentLocked(i int) bool {
+	t := sh.tier
+	seg := t.segments[i]
+	if seg.unmigrated != nil {
+		if err := sh.migrateLocked(seg.unmigrated); err != nil {
+			t.lastErr = err
+			return false
+		}
+	} else {
+		entries, metas, err := sh.readSegment(seg.path)
+		if err != nil {
+			t.lastErr = err
+			return false
+		}
+		for i, e := range entries {
+			node := sh.attachLocked(e.Key, e.Value)
+			sh.restoreMetaLocked(node, metas[i])
+			// Nothing is spilled while a range operation is open, so no
+			// other node for the key is visible at versions before the
+			// spill.
+			node.iTime = metas[i].iTime
+		}
+		os.Remove(seg.path)
+	}
+	t.spilled -= seg.count
+	t.segments = slices.Delete(t.segments, i, i+1)
+	t.pending.Store(int32(len(t.segments)))
+	return true
+}
+
+// touch records an access for the cold-range detector and eviction policies.
+func (sh *SkipHash[K, V]) touch(node *slNode[K, V]) {
+	if sh.tier != nil || sh.eviction == EvictLRU {
+		node.lastAccess.Store(time.Now().UnixNano())
+	}
+	if sh.eviction == EvictLFU {
+		node.hits.Add(1)
+	}
+}
@@ -0,0 +1,203 @@
+package skiphash
+
+import (
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSkipHashTiering(t *testing.T) {
+	dir := t.TempDir()
+	sh := New[int, string](WithTiering(dir), WithRandSource(rand.NewSource(16)))
+	for i := range 100 {
+		sh.Insert(i, "v")
+	}
+
+	// Keep 40..59 hot, everything else goes cold.
+	time.Sleep(5 * time.Millisecond)
+	for i := 40; i < 60; i++ {
+		sh.Get(i)
+	}
+	spilled, err := sh.SpillCold(2*time.Millisecond, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 80, spilled)
+
+	stats := sh.TieringStats()
+	assert.Equal(t, 2, stats.Segments)
+	assert.Equal(t, 80, stats.SpilledEntries)
+	assert.Equal(t, 100, sh.Len(), "spilled entries stay logically present")
+	assert.Equal(t, 20, sh.len)
+	files, _ := filepath.Glob(filepath.Join(dir, "segment-*"))
+	assert.Len(t, files, 2)
+
+	// A point lookup faults in only the segment holding the key.
+	got, ok := sh.Get(90)
+	assert.True(t, ok)
+	assert.Equal(t, "v", got)
+	assert.Equal(t, 1, sh.TieringStats().Segments)
+	assert.Equal(t, 40, sh.TieringStats().SpilledEntries)
+
+	// Writes into a spilled range see the spilled keys.
+	assert.False(t, sh.Insert(5, "dup"))
+	assert.Equal(t, 0, sh.TieringStats().Segments)
+	assert.Len(t, sh.Range(0, 99), 100)
+	checkSpans(t, sh)
+
+	files, _ = filepath.Glob(filepath.Join(dir, "segment-*"))
+	assert.Empty(t, files, "loaded segments are deleted")
+}
+
+func TestSkipHashTieringKeepsEntryMeta(t *testing.T) {
+	dir := t.TempDir()
+	sh := New[int, point](WithTiering(dir), WithValueCodec[point](pointCodec{}),
+		WithEntryTimestamps(), WithHistory(2, 0), WithVersionIndex())
+	for i := range 10 {
+		sh.Store(i, point{int32(i), 0})
+	}
+	asOf := sh.CurrentVersion()
+	sh.Store(3, point{3, 1})
+	meta, _ := sh.GetEntryMeta(3)
+
+	time.Sleep(2 * time.Millisecond)
+	spilled, err := sh.SpillCold(time.Millisecond, 1)
+	require.NoError(t, err)
+	require.Equal(t, 10, spilled)
+	files, _ := filepath.Glob(filepath.Join(dir, "segment-*"))
+	require.Len(t, files, 1)
+	data, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "point", "values are written with the value codec, not gob")
+
+	got, ok := sh.GetEntryMeta(3)
+	require.True(t, ok)
+	assert.Equal(t, meta, got)
+	v, ok := sh.GetAsOf(3, asOf)
+	assert.True(t, ok)
+	assert.Equal(t, point{3, 0}, v)
+	versions := sh.RangeByVersion(meta.Version, meta.Version)
+	require.Len(t, versions, 1)
+	assert.Equal(t, 3, versions[0].Key)
+}
+
+func TestSkipHashTieringOrderedOps(t *testing.T) {
+	sh := New[int, int](WithTiering(t.TempDir()))
+	for i := range 50 {
+		sh.Insert(i*2, i)
+	}
+	time.Sleep(2 * time.Millisecond)
+	spilled, err := sh.SpillCold(time.Millisecond, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 50, spilled)
+
+	ceil, ok := sh.Ceil(31)
+	assert.True(t, ok)
+	assert.Equal(t, 32, ceil.Key)
+	assert.Equal(t, 25, sh.RangeCount(50, 98))
+	assert.Len(t, sh.RangeAll(), 50)
+}
+
+func TestSkipHashTieringReadError(t *testing.T) {
+	dir := t.TempDir()
+	sh := New[int, int](WithTiering(dir))
+	for i := range 10 {
+		sh.Insert(i, i)
+	}
+	time.Sleep(2 * time.Millisecond)
+	_, err := sh.SpillCold(time.Millisecond, 1)
+	require.NoError(t, err)
+
+	files, _ := filepath.Glob(filepath.Join(dir, "segment-*"))
+	require.Len(t, files, 1)
+	require.NoError(t, os.WriteFile(files[0], []byte("garbage"), 0o644))
+
+	_, ok := sh.Get(3)
+	assert.False(t, ok)
+	stats := sh.TieringStats()
+	assert.Error(t, stats.LastError)
+	assert.Equal(t, 1, stats.Segments, "unreadable segments stay registered")
+
+	_, err = New[int, int]().SpillCold(time.Second, 1)
+	assert.Error(t, err)
+}
+
+func TestSkipHashTieringNeighbours(t *testing.T) {
+	sh := New[int, int](WithTiering(t.TempDir()))
+	for i := range 100 {
+		sh.Insert(i, i)
+	}
+	time.Sleep(2 * time.Millisecond)
+	for i := 20; i < 100; i += 20 {
+		sh.Get(i)
+	}
+	spilled, err := sh.SpillCold(time.Millisecond, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 96, spilled)
+	assert.Equal(t, 5, sh.TieringStats().Segments)
+
+	// The neighbour is in memory, so nothing is loaded.
+	e, ok := sh.Floor(20)
+	assert.True(t, ok)
+	assert.Equal(t, 20, e.Key)
+	e, ok = sh.Ceil(20)
+	assert.True(t, ok)
+	assert.Equal(t, 20, e.Key)
+	assert.Equal(t, 5, sh.TieringStats().Segments)
+
+	// Otherwise only the segment holding it is.
+	e, ok = sh.Succ(40)
+	assert.True(t, ok)
+	assert.Equal(t, 41, e.Key)
+	assert.Equal(t, 4, sh.TieringStats().Segments)
+	e, ok = sh.Pred(20)
+	assert.True(t, ok)
+	assert.Equal(t, 19, e.Key)
+	assert.Equal(t, 3, sh.TieringStats().Segments)
+	e, ok = sh.Ceil(70)
+	assert.True(t, ok)
+	assert.Equal(t, 70, e.Key)
+	assert.Equal(t, 2, sh.TieringStats().Segments)
+	_, ok = sh.Succ(99)
+	assert.False(t, ok)
+	assert.Equal(t, 2, sh.TieringStats().Segments)
+	e, ok = sh.Pred(100)
+	assert.True(t, ok)
+	assert.Equal(t, 99, e.Key)
+	assert.Equal(t, 1, sh.TieringStats().Segments)
+	assert.Equal(t, 100, sh.Len())
+	checkSpans(t, sh)
+}
+
+func TestSkipHashSpillSkipsChangedRuns(t *testing.T) {
+	dir := t.TempDir()
+	sh := New[int, int](WithTiering(dir))
+	for i := range 30 {
+		sh.Insert(i*2, i)
+	}
+	time.Sleep(2 * time.Millisecond)
+
+	spill := func(change func()) bool {
+		sh.mu.RLock()
+		runs := sh.coldRunsLocked(time.Now().UnixNano(), 1)
+		sh.mu.RUnlock()
+		require.Len(t, runs, 1)
+		path, err := sh.writeSegment(runs[0])
+		require.NoError(t, err)
+		change()
+		sh.mu.Lock()
+		defer sh.mu.Unlock()
+		return sh.spillRunLocked(runs[0], path)
+	}
+	assert.False(t, spill(func() { sh.Store(10, -1) }))
+	assert.False(t, spill(func() { sh.Remove(10) }))
+	assert.False(t, spill(func() { sh.Insert(11, 11) }))
+	assert.False(t, spill(func() { sh.StoreTTL(12, 6, time.Hour) }))
+	sh.Remove(12)
+	assert.True(t, spill(func() {}))
+	assert.Equal(t, 1, sh.TieringStats().Segments)
+	assert.Equal(t, 29, sh.Len())
+}
@@ -0,0 +1,86 @@
+package skiphash
+
+import "time"
+
+// WithTombstoneGC bounds the tombstones kept for open range operations and
+// snapshots. Once more than maxDeferred removed nodes are waiting, or the
+// oldest open operation has been registered for longer than maxAge, every
+// waiting node is unlinked immediately instead of when its owner finishes.
+// A zero limit is not enforced. The price is consistency: an operation that
+// has not reached an unlinked node yet no longer sees it, so long scans and
+// snapshots may miss entries removed after they started.
+func WithTombstoneGC(maxDeferred int, maxAge time.Duration) Option {
+	return func(cfg *config) {
+		cfg.gcMaxDeferred = max(maxDeferred, 0)
+		cfg.gcMaxAge = max(maxAge, 0)
+	}
+}
+
+// Tombstone describes a logically removed entry that is still linked into
+// the list because a range operation may observe it.
+type Tombstone[K any, V any] struct {
+	Key   K
+	Value V
+	// RemovedAt is the range-coordinator version of the removal (rTime).
+	RemovedAt uint64
+	// BlockedBy is the version of the active range operation or snapshot
+	// that currently owns the deferred unlink.
+	BlockedBy uint64
+}
+
+// TombstonesAll returns every logically removed but physically present
+// entry in key order, with the range version preventing its cleanup.
+func (sh *SkipHash[K, V]) TombstonesAll() []Tombstone[K, V] {
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+
+	blockers := make(map[*slNode[K, V]]uint64)
+	for op := sh.rqc.head; op != nil; op = op.next {
+		for _, node := range op.deferred {
+			blockers[node] = op.ver
+		}
+	}
+
+	var out []Tombstone[K, V]
+	for node := sh.head.next[0]; node != sh.tail; node = node.next[0] {
+		if node.rTime == 0 {
+			continue
+		}
+		out = append(out, Tombstone[K, V]{
+			Key:       node.key,
+			Value:     *node.value.Load(),
+			RemovedAt: node.rTime,
+			BlockedBy: blockers[node],
+		})
+	}
+	return out
+}
+
+// Compact unstitches every tombstone that no range operation or snapshot can
+// observe and reclaims the retired nodes readers have moved past. It does
+// nothing while a range operation is registered, since the tombstones then
+// belong to it, and it keeps the removed entries WithHistory retains. It
+// returns the number of nodes unstitched.
+func (sh *SkipHash[K, V]) Compact() int {
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	if sh.rqc.head != nil {
+		return 0
+	}
+
+	retained := make(map[*slNode[K, V]]bool, len(sh.retained))
+	for _, node := range sh.retained {
+		retained[node] = true
+	}
+	n := 0
+	for node := sh.head.next[0]; node != sh.tail; {
+		next := node.next[0]
+		if node.rTime != 0 && !retained[node] {
+			sh.unstitchNodeLocked(node)
+			n++
+		}
+		node = next
+	}
+	sh.epochs.reclaimLocked()
+	return n
+}
@@ -0,0 +1,165 @@
+package skiphash
+
+import (
+	"cmp"
+	"time"
+)
+
+// changeExpiry is the change published when a deadline is set. Only the
+// WAL records it; removing a deadline is implied by the update or removal
+// that does so.
+const changeExpiry ChangeKind = 0xff
+
+// deadline keys the TTL index, which is itself a SkipHash ordered by expiry
+// time and then by scheduling order, so sweeping only ever looks at its
+// front.
+type deadline struct {
+	at  int64 // UnixNano
+	seq uint64
+}
+
+func compareDeadlines(a, b deadline) int {
+	if c := cmp.Compare(a.at, b.at); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.seq, b.seq)
+}
+
+// InsertTTL is like Insert, but the entry expires after ttl.
+func (sh *SkipHash[K, V]) InsertTTL(key K, value V, ttl time.Duration) bool {
+	sh.unsupportedInFineGrained()
+	key = sh.normalizeKey(key)
+	sh.mu.Lock()
+	defer sh.unlock()
+	sh.faultInLocked(key, key)
+
+	if _, exists := sh.index.get(key); exists {
+		return false
+	}
+	if sh.insertLocked(key, value) != nil {
+		return false
+	}
+	sh.scheduleLocked(key, time.Now().Add(ttl))
+	return true
+}
+
+// StoreTTL is like Store, but the entry expires after ttl, replacing any
+// earlier deadline.
+func (sh *SkipHash[K, V]) StoreTTL(key K, value V, ttl time.Duration) bool {
+	inserted, _ := sh.TryStoreTTL(key, value, ttl)
+	return inserted
+}
+
+// TryStoreTTL is like StoreTTL but returns an error when the write is
+// rejected, like TryStore.
+func (sh *SkipHash[K, V]) TryStoreTTL(key K, value V, ttl time.Duration) (bool, error) {
+	sh.unsupportedInFineGrained()
+	key = sh.normalizeKey(key)
+	sh.mu.Lock()
+	defer sh.unlock()
+	sh.faultInLocked(key, key)
+
+	node, exists := sh.index.get(key)
+	if exists {
+		sh.touch(node)
+		if err := sh.updateLocked(node, value); err != nil {
+			return false, err
+		}
+	} else if err := sh.insertLocked(key, value); err != nil {
+		return false, err
+	}
+	sh.scheduleLocked(key, time.Now().Add(ttl))
+	return !exists, nil
+}
+
+// TTL returns the time left before key expires. It reports false if key is
+// absent or has no TTL.
+func (sh *SkipHash[K, V]) TTL(key K) (time.Duration, bool) {
+	sh.unsupportedInFineGrained()
+	key = sh.normalizeKey(key)
+	sh.faultIn(key, key)
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+	node, ok := sh.index.get(key)
+	if !ok || node.expiry.at == 0 {
+		return 0, false
+	}
+	return time.Until(time.Unix(0, node.expiry.at)), true
+}
+
+// RemoveExpired removes every entry whose TTL has passed and returns how many
+// were removed. Expired entries are otherwise removed lazily by the next
+// operation that touches the SkipHash after their deadline.
+func (sh *SkipHash[K, V]) RemoveExpired() int {
+	sh.mu.Lock()
+	defer sh.unlock()
+	return sh.expireDueLocked(0)
+}
+
+// expireDue removes the entries whose TTL has passed, taking the write lock
+// only when the earliest deadline is due.
+func (sh *SkipHash[K, V]) expireDue() {
+	next := sh.nextDeadline.Load()
+	if next == 0 || time.Now().UnixNano() < next {
+		return
+	}
+	sh.mu.Lock()
+	defer sh.unlock()
+	sh.expireDueLocked(0)
+}
+
+// expireDueLocked removes up to limit expired entries, or all of them if
+// limit is 0, and returns how many it removed.
+func (sh *SkipHash[K, V]) expireDueLocked(limit int) int {
+	if sh.deadlines == nil || sh.deadlines.len == 0 {
+		return 0
+	}
+	now := time.Now().UnixNano()
+	expired := 0
+	for limit == 0 || expired < limit {
+		first := sh.deadlines.firstLiveLocked()
+		if first == nil || first.key.at > now {
+			break
+		}
+		sh.dropLocked((*first.value.Load()).(*slNode[K, V]), true)
+		expired++
+	}
+	return expired
+}
+
+// scheduleLocked sets the deadline of the live entry for key.
+func (sh *SkipHash[K, V]) scheduleLocked(key K, at time.Time) {
+	node, ok := sh.index.get(key)
+	if !ok {
+		return
+	}
+	sh.unscheduleLocked(node)
+	if sh.deadlines == nil {
+		sh.deadlines = newSkipHash(compareDeadlines, newMapIndex[deadline, any](), nil)
+	}
+	sh.deadlineSeq++
+	node.expiry = deadline{at: at.UnixNano(), seq: sh.deadlineSeq}
+	sh.deadlines.insertLocked(node.expiry, node)
+	sh.noteNextDeadlineLocked()
+	sh.changedLocked(change[K, V]{kind: changeExpiry, key: key, expiry: node.expiry.at})
+}
+
+// unscheduleLocked clears the deadline of node, if it has one.
+func (sh *SkipHash[K, V]) unscheduleLocked(node *slNode[K, V]) {
+	if node.expiry.at == 0 {
+		return
+	}
+	if entry, ok := sh.deadlines.index.get(node.expiry); ok {
+		sh.deadlines.removeLocked(entry)
+	}
+	node.expiry = deadline{}
+	sh.noteNextDeadlineLocked()
+}
+
+func (sh *SkipHash[K, V]) noteNextDeadlineLocked() {
+	if first := sh.deadlines.firstLiveLocked(); first != nil {
+		sh.nextDeadline.Store(first.key.at)
+	} else {
+		sh.nextDeadline.Store(0)
+	}
+}
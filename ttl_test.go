@@ -0,0 +1,56 @@
+package skiphash
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSkipHashTTL(t *testing.T) {
+	var evicted []int
+	sh := New[int, string](WithHooks(Hooks[int, string]{
+		OnEvict: func(key int, _ string) { evicted = append(evicted, key) },
+	}))
+
+	assert.True(t, sh.InsertTTL(1, "a", 20*time.Millisecond))
+	assert.False(t, sh.InsertTTL(1, "b", time.Hour))
+	assert.True(t, sh.StoreTTL(2, "b", time.Hour))
+	sh.Store(3, "c")
+
+	left, ok := sh.TTL(2)
+	require.True(t, ok)
+	assert.Greater(t, left, 59*time.Minute)
+	_, ok = sh.TTL(3)
+	assert.False(t, ok)
+
+	time.Sleep(30 * time.Millisecond)
+	_, ok = sh.Get(1)
+	assert.False(t, ok, "expired entries are removed lazily on read")
+	assert.Equal(t, 2, sh.Len())
+	assert.Equal(t, []int{1}, evicted)
+	assert.True(t, sh.InsertTTL(1, "again", time.Hour), "an expired key can be reinserted")
+
+	sh.Store(2, "persistent")
+	_, ok = sh.TTL(2)
+	assert.False(t, ok, "Store clears the TTL")
+	checkSpans(t, sh)
+}
+
+func TestSkipHashRemoveExpired(t *testing.T) {
+	sh := New[int, int]()
+	for k := range 10 {
+		sh.InsertTTL(k, k, time.Duration(k%2)*time.Hour+20*time.Millisecond)
+	}
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, 5, sh.RemoveExpired())
+	assert.Equal(t, 0, sh.RemoveExpired())
+	assert.Equal(t, []int{1, 3, 5, 7, 9}, keysOf(sh.RangeAll()))
+
+	sh.Remove(1)
+	sh.StoreTTL(3, 3, -time.Second)
+	assert.Equal(t, 3, sh.Len(), "already expired entries vanish on the next operation")
+	assert.False(t, sh.Contains(3))
+	checkSpans(t, sh)
+}
@@ -0,0 +1,182 @@
+package skiphash
+
+import (
+	"slices"
+	"time"
+)
+
+// Txn is a batch of reads and writes that commits atomically; see
+// SkipHash.Txn.
+type Txn[K any, V any] struct {
+	sh *SkipHash[K, V]
+	// changes is every mutation made while the transaction runs, in order.
+	// It is both the undo log and what changedLocked publishes on commit.
+	changes []change[K, V]
+}
+
+// Txn runs fn under the write lock and commits everything it wrote if fn
+// returns nil. If fn returns an error or panics, its writes are undone,
+// except that entries the SkipHash expired or evicted in the meantime stay
+// removed. Readers, watchers, hooks, indexes and
+// the WAL only see the changes of a committed transaction: Get and Contains,
+// which normally take no lock, wait for a running Txn like other reads.
+//
+// fn must use tx and not the SkipHash, whose lock it holds, and must not
+// retain tx. Txn returns the error from fn.
+func (sh *SkipHash[K, V]) Txn(fn func(tx *Txn[K, V]) error) error {
+	sh.unsupportedInFineGrained()
+	sh.mu.Lock()
+	defer sh.unlock()
+	return sh.txnLocked(fn)
+}
+
+// txnLocked is Txn for a caller that already holds the write lock.
+func (sh *SkipHash[K, V]) txnLocked(fn func(tx *Txn[K, V]) error) error {
+	tx := &Txn[K, V]{sh: sh}
+	sh.txn = tx
+	sh.beginBatchLocked()
+	committed := false
+	defer func() {
+		if !committed {
+			tx.rollbackLocked()
+		}
+		sh.txn = nil
+		sh.endBatchLocked()
+		for _, c := range tx.changes {
+			sh.changedLocked(c)
+		}
+	}()
+	if err := fn(tx); err != nil {
+		return err
+	}
+	committed = true
+	return nil
+}
+
+// rollbackLocked undoes the changes in reverse, restoring the versions and
+// timestamps the undone writes replaced, and leaves only the evictions,
+// which are kept, to be published.
+func (tx *Txn[K, V]) rollbackLocked() {
+	sh := tx.sh
+	changes := tx.changes
+	tx.changes = nil
+	for i := len(changes) - 1; i >= 0; i-- {
+		c := changes[i]
+		if c.evicted {
+			continue
+		}
+		node, exists := sh.index.get(c.key)
+		switch {
+		case c.kind == ChangeInsert && exists:
+			sh.removeLocked(node)
+		case c.kind == ChangeUpdate && exists:
+			_ = sh.updateLocked(node, c.old)
+		case c.kind == ChangeRemove && exists:
+			_ = sh.updateLocked(node, c.value)
+		case c.kind == ChangeRemove:
+			_ = sh.insertLocked(c.key, c.value)
+		}
+		if node, ok := sh.index.get(c.key); ok && c.kind != ChangeInsert {
+			sh.restoreMetaLocked(node, c.meta)
+		}
+		if (c.kind == ChangeUpdate || c.kind == ChangeRemove) && c.expiry != 0 {
+			sh.scheduleLocked(c.key, time.Unix(0, c.expiry))
+		}
+	}
+	undone := tx.changes
+	tx.changes = nil
+	for _, c := range slices.Concat(changes, undone) {
+		if c.evicted {
+			tx.changes = append(tx.changes, c)
+		}
+	}
+}
+
+func (tx *Txn[K, V]) checkOpen() {
+	if tx.sh.txn != tx {
+		panic("skiphash: Txn used after its function returned")
+	}
+}
+
+// Get returns the value of key, including the writes made so far in the
+// transaction.
+func (tx *Txn[K, V]) Get(key K) (V, bool) {
+	tx.checkOpen()
+	sh := tx.sh
+	key = sh.normalizeKey(key)
+	sh.faultInLocked(key, key)
+	if node, ok := sh.index.get(key); ok {
+		return *node.value.Load(), true
+	}
+	var zero V
+	return zero, false
+}
+
+// Insert adds key and fails like SkipHash.TryInsert.
+func (tx *Txn[K, V]) Insert(key K, value V) error {
+	tx.checkOpen()
+	sh := tx.sh
+	key = sh.normalizeKey(key)
+	sh.faultInLocked(key, key)
+	if _, exists := sh.index.get(key); exists {
+		return ErrKeyExists
+	}
+	return sh.insertLocked(key, value)
+}
+
+// Store inserts or replaces the value for key like SkipHash.TryStore.
+func (tx *Txn[K, V]) Store(key K, value V) (bool, error) {
+	tx.checkOpen()
+	sh := tx.sh
+	key = sh.normalizeKey(key)
+	sh.faultInLocked(key, key)
+	if node, exists := sh.index.get(key); exists {
+		sh.touch(node)
+		return false, sh.updateLocked(node, value)
+	}
+	if err := sh.insertLocked(key, value); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Remove deletes key and reports whether it was present.
+func (tx *Txn[K, V]) Remove(key K) bool {
+	tx.checkOpen()
+	sh := tx.sh
+	key = sh.normalizeKey(key)
+	sh.faultInLocked(key, key)
+	node, exists := sh.index.get(key)
+	if exists {
+		sh.removeLocked(node)
+	}
+	return exists
+}
+
+// Rekey moves the entry at oldKey to newKey with its TTL. It runs as a Txn,
+// so no reader sees the entry missing or under both keys. It fails if oldKey
+// is absent, newKey is present or the insert is rejected.
+func (sh *SkipHash[K, V]) Rekey(oldKey, newKey K) bool {
+	moved := false
+	_ = sh.Txn(func(tx *Txn[K, V]) error {
+		value, ok := tx.Get(oldKey)
+		if !ok {
+			return nil
+		}
+		if _, taken := tx.Get(newKey); taken {
+			return nil
+		}
+		node, _ := sh.index.get(sh.normalizeKey(oldKey))
+		expiry := node.expiry.at
+		tx.Remove(oldKey)
+		if err := tx.Insert(newKey, value); err != nil {
+			return err
+		}
+		if expiry != 0 {
+			sh.scheduleLocked(sh.normalizeKey(newKey), time.Unix(0, expiry))
+		}
+		moved = true
+		return nil
+	})
+	return moved
+}
@@ -0,0 +1,181 @@
+package skiphash
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxnCommit(t *testing.T) {
+	var events []ChangeKind
+	sh := New[string, int](WithHooks(Hooks[string, int]{
+		OnInsert: func(string, int) { events = append(events, ChangeInsert) },
+		OnUpdate: func(string, int, int) { events = append(events, ChangeUpdate) },
+		OnRemove: func(string, int) { events = append(events, ChangeRemove) },
+	}))
+	sh.Store("a", 10)
+	events = nil
+
+	err := sh.Txn(func(tx *Txn[string, int]) error {
+		a, _ := tx.Get("a")
+		tx.Remove("a")
+		require.NoError(t, tx.Insert("b", a))
+		assert.ErrorIs(t, tx.Insert("b", 1), ErrKeyExists)
+		got, ok := tx.Get("b")
+		assert.True(t, ok)
+		assert.Equal(t, 10, got)
+		_, err := tx.Store("b", a+1)
+		return err
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []Entry[string, int]{{"b", 11}}, sh.RangeAll())
+	assert.Equal(t, []ChangeKind{ChangeRemove, ChangeInsert, ChangeUpdate}, events)
+}
+
+func TestTxnRollback(t *testing.T) {
+	var events int
+	sh := New[string, int](WithHooks(Hooks[string, int]{
+		OnInsert: func(string, int) { events++ },
+		OnUpdate: func(string, int, int) { events++ },
+		OnRemove: func(string, int) { events++ },
+	}))
+	sh.Store("a", 1)
+	sh.Store("b", 2)
+	events = 0
+
+	errAbort := errors.New("abort")
+	var leaked *Txn[string, int]
+	err := sh.Txn(func(tx *Txn[string, int]) error {
+		leaked = tx
+		tx.Store("a", 10)
+		tx.Remove("b")
+		tx.Store("c", 3)
+		tx.Store("b", 20)
+		tx.Remove("a")
+		return errAbort
+	})
+	assert.ErrorIs(t, err, errAbort)
+	assert.Equal(t, []Entry[string, int]{{"a", 1}, {"b", 2}}, sh.RangeAll())
+	assert.Zero(t, events)
+	assert.Panics(t, func() { leaked.Get("a") })
+
+	assert.Panics(t, func() {
+		sh.Txn(func(tx *Txn[string, int]) error {
+			tx.Remove("a")
+			panic("boom")
+		})
+	})
+	assert.Equal(t, []Entry[string, int]{{"a", 1}, {"b", 2}}, sh.RangeAll())
+	sh.Store("c", 3)
+	assert.Equal(t, 1, events)
+}
+
+func TestTxnRollbackRestoresVersions(t *testing.T) {
+	sh := New[string, int](WithVersionIndex(), WithEntryTimestamps(), WithHistory(4, 4))
+	sh.Store("a", 1)
+	sh.Store("b", 2)
+	sh.Store("a", 3)
+	metaA, _ := sh.GetEntryMeta("a")
+	metaB, _ := sh.GetEntryMeta("b")
+	asOf := sh.CurrentVersion()
+
+	_ = sh.Txn(func(tx *Txn[string, int]) error {
+		tx.Store("a", 10)
+		tx.Store("a", 11)
+		tx.Remove("b")
+		return errors.New("abort")
+	})
+	got, _ := sh.GetEntryMeta("a")
+	assert.Equal(t, metaA, got)
+	got, _ = sh.GetEntryMeta("b")
+	assert.Equal(t, metaB, got)
+	assert.True(t, sh.StoreIfVersion("a", 4, metaA.Version))
+
+	versions := sh.RangeByVersion(0, sh.WriteVersion())
+	assert.Equal(t, []string{"b", "a"}, []string{versions[0].Key, versions[1].Key})
+	assert.Len(t, versions, 2)
+	v, _ := sh.GetAsOf("a", asOf)
+	assert.Equal(t, 3, v)
+}
+
+func TestTxnRollbackInvisibleToReaders(t *testing.T) {
+	sh := New[int, int]()
+	sh.Store(1, 1)
+
+	var done atomic.Bool
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Go(func() {
+			for !done.Load() {
+				v, ok := sh.Get(1)
+				assert.True(t, ok)
+				assert.Equal(t, 1, v)
+				assert.False(t, sh.Contains(2))
+			}
+		})
+	}
+	for range 100 {
+		sh.Txn(func(tx *Txn[int, int]) error {
+			tx.Store(1, 999)
+			tx.Store(2, 2)
+			runtime.Gosched()
+			return errors.New("abort")
+		})
+	}
+	done.Store(true)
+	wg.Wait()
+}
+
+func TestTxnKeepsEvictions(t *testing.T) {
+	sh := New[int, int](WithMaxEntries(2, EvictOldest))
+	sh.Store(1, 1)
+	sh.Store(2, 2)
+
+	err := sh.Txn(func(tx *Txn[int, int]) error {
+		tx.Store(3, 3)
+		return errors.New("abort")
+	})
+	require.Error(t, err)
+	assert.Equal(t, []Entry[int, int]{{2, 2}}, sh.RangeAll())
+}
+
+func TestRekey(t *testing.T) {
+	sh := New[string, int](WithQuota(func(k string) string { return k[:1] }, map[string]int{"a": 1, "b": 1}))
+	sh.Store("a1", 1)
+	sh.Store("b1", 2)
+
+	assert.True(t, sh.Rekey("a1", "a2"))
+	assert.Equal(t, []Entry[string, int]{{"a2", 1}, {"b1", 2}}, sh.RangeAll())
+	assert.False(t, sh.Rekey("a2", "b1"))
+	assert.False(t, sh.Rekey("a2", "a2"))
+	assert.False(t, sh.Rekey("zz", "z1"))
+	// The quota of tenant b is full, so the insert fails and the move is undone.
+	assert.False(t, sh.Rekey("a2", "b2"))
+	assert.Equal(t, []Entry[string, int]{{"a2", 1}, {"b1", 2}}, sh.RangeAll())
+
+	// The TTL moves with the entry, and survives a rolled back write.
+	sh = New[string, int]()
+	sh.InsertTTL("x", 1, time.Hour)
+	assert.True(t, sh.Rekey("x", "y"))
+	left, ok := sh.TTL("y")
+	assert.True(t, ok)
+	assert.Greater(t, left, 59*time.Minute)
+	sh.Txn(func(tx *Txn[string, int]) error {
+		tx.Remove("y")
+		return errors.New("abort")
+	})
+	_, ok = sh.TTL("y")
+	assert.True(t, ok)
+	sh.Txn(func(tx *Txn[string, int]) error {
+		tx.Store("y", 2)
+		return errors.New("abort")
+	})
+	_, ok = sh.TTL("y")
+	assert.True(t, ok)
+}
@@ -0,0 +1,270 @@
+package skiphash
+
+import (
+	"cmp"
+	"math/bits"
+	"math/rand"
+	"slices"
+	"sync"
+	"time"
+)
+
+// unrolledChunk is the most entries a node of an Unrolled map holds.
+const unrolledChunk = 32
+
+// Unrolled is an ordered map whose skip-list nodes each hold a sorted run of
+// up to 32 entries. Range scans then read keys and values from contiguous
+// arrays instead of chasing one pointer per entry, and the list has about a
+// twentieth of the nodes. The hash index maps each key to its node, and the
+// slot is found by binary search within the node.
+//
+// Unrolled covers the core map operations under a single RWMutex; options
+// other than WithMaxLevel, WithDescending and WithRandSource are ignored.
+type Unrolled[K comparable, V any] struct {
+	mu       sync.RWMutex
+	maxLevel int
+	compare  func(a, b K) int
+	rng      *rand.Rand
+	// head is a sentinel; every other node holds at least one entry, and
+	// all of its keys sort before the first key of the next node.
+	head  *urNode[K, V]
+	index map[K]*urNode[K, V]
+	len   int
+}
+
+type urNode[K comparable, V any] struct {
+	keys   []K
+	values []V
+	next   []*urNode[K, V]
+}
+
+// NewUnrolled creates an empty Unrolled map.
+func NewUnrolled[K cmp.Ordered, V any](opts ...Option) *Unrolled[K, V] {
+	cfg := config{maxLevel: DefaultMaxLevel}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&cfg)
+		}
+	}
+	if cfg.maxLevel <= 0 {
+		cfg.maxLevel = DefaultMaxLevel
+	}
+	if cfg.randSource == nil {
+		cfg.randSource = rand.NewSource(time.Now().UnixNano())
+	}
+	compare := cmp.Compare[K]
+	if cfg.descending {
+		compare = func(a, b K) int { return cmp.Compare(b, a) }
+	}
+	return &Unrolled[K, V]{
+		maxLevel: cfg.maxLevel,
+		compare:  compare,
+		rng:      rand.New(cfg.randSource),
+		head:     &urNode[K, V]{next: make([]*urNode[K, V], cfg.maxLevel)},
+		index:    make(map[K]*urNode[K, V]),
+	}
+}
+
+func (m *Unrolled[K, V]) Len() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.len
+}
+
+func (m *Unrolled[K, V]) Get(key K) (V, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if node, ok := m.index[key]; ok {
+		i, _ := m.slot(node, key)
+		return node.values[i], true
+	}
+	var zero V
+	return zero, false
+}
+
+func (m *Unrolled[K, V]) Contains(key K) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	_, ok := m.index[key]
+	return ok
+}
+
+// Insert adds key and fails if it is already present.
+func (m *Unrolled[K, V]) Insert(key K, value V) bool {
+	return m.put(key, value, false)
+}
+
+// Store inserts or replaces the value for key and reports whether key was
+// inserted.
+func (m *Unrolled[K, V]) Store(key K, value V) bool {
+	return m.put(key, value, true)
+}
+
+func (m *Unrolled[K, V]) put(key K, value V, overwrite bool) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if node, ok := m.index[key]; ok {
+		if overwrite {
+			i, _ := m.slot(node, key)
+			node.values[i] = value
+		}
+		return false
+	}
+
+	node := m.floorNode(key)
+	if node == m.head {
+		node = m.head.next[0]
+	}
+	if node == nil {
+		node = m.newNode()
+		m.link(node, key)
+	}
+	if len(node.keys) == unrolledChunk {
+		tail := m.split(node)
+		if m.compare(key, tail.keys[0]) >= 0 {
+			node = tail
+		}
+	}
+	i, _ := m.slot(node, key)
+	node.keys = slices.Insert(node.keys, i, key)
+	node.values = slices.Insert(node.values, i, value)
+	m.index[key] = node
+	m.len++
+	return true
+}
+
+func (m *Unrolled[K, V]) Remove(key K) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	node, ok := m.index[key]
+	if !ok {
+		return false
+	}
+	delete(m.index, key)
+	m.len--
+	if len(node.keys) == 1 {
+		m.unlink(node)
+		return true
+	}
+	i, _ := m.slot(node, key)
+	node.keys = slices.Delete(node.keys, i, i+1)
+	node.values = slices.Delete(node.values, i, i+1)
+
+	// Absorb a small successor so that nodes stay reasonably full.
+	if next := node.next[0]; next != nil && len(node.keys) < unrolledChunk/4 &&
+		len(node.keys)+len(next.keys) <= unrolledChunk/2 {
+		m.unlink(next)
+		for _, k := range next.keys {
+			m.index[k] = node
+		}
+		node.keys = append(node.keys, next.keys...)
+		node.values = append(node.values, next.values...)
+	}
+	return true
+}
+
+// Range returns the entries in [low, high], in order.
+func (m *Unrolled[K, V]) Range(low, high K) []Entry[K, V] {
+	if m.compare(low, high) > 0 {
+		return nil
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	node := m.floorNode(low)
+	i := 0
+	if node == m.head {
+		node = m.head.next[0]
+	} else {
+		i, _ = m.slot(node, low)
+	}
+	entries := make([]Entry[K, V], 0, defaultEntryCap)
+	for ; node != nil; node, i = node.next[0], 0 {
+		for ; i < len(node.keys); i++ {
+			if m.compare(node.keys[i], high) > 0 {
+				return entries
+			}
+			entries = append(entries, Entry[K, V]{Key: node.keys[i], Value: node.values[i]})
+		}
+	}
+	return entries
+}
+
+// slot returns where key is, or would be inserted, in node.
+func (m *Unrolled[K, V]) slot(node *urNode[K, V], key K) (int, bool) {
+	return slices.BinarySearchFunc(node.keys, key, m.compare)
+}
+
+// floorNode returns the last node whose first key is at most key, or the
+// head if there is none.
+func (m *Unrolled[K, V]) floorNode(key K) *urNode[K, V] {
+	cur := m.head
+	for level := m.maxLevel - 1; level >= 0; level-- {
+		for next := cur.next[level]; next != nil && m.compare(next.keys[0], key) <= 0; next = cur.next[level] {
+			cur = next
+		}
+	}
+	return cur
+}
+
+// predecessors returns the last node at every level whose first key sorts
+// before key.
+func (m *Unrolled[K, V]) predecessors(key K) []*urNode[K, V] {
+	preds := make([]*urNode[K, V], m.maxLevel)
+	cur := m.head
+	for level := m.maxLevel - 1; level >= 0; level-- {
+		for next := cur.next[level]; next != nil && m.compare(next.keys[0], key) < 0; next = cur.next[level] {
+			cur = next
+		}
+		preds[level] = cur
+	}
+	return preds
+}
+
+func (m *Unrolled[K, V]) newNode() *urNode[K, V] {
+	height := min(bits.TrailingZeros64(m.rng.Uint64())+1, m.maxLevel)
+	return &urNode[K, V]{
+		keys:   make([]K, 0, unrolledChunk),
+		values: make([]V, 0, unrolledChunk),
+		next:   make([]*urNode[K, V], height),
+	}
+}
+
+// link splices node into the list at the position of first, which is or
+// will become its first key.
+func (m *Unrolled[K, V]) link(node *urNode[K, V], first K) {
+	preds := m.predecessors(first)
+	for level := range node.next {
+		node.next[level] = preds[level].next[level]
+		preds[level].next[level] = node
+	}
+}
+
+// unlink removes node from the list. It must still hold its entries.
+func (m *Unrolled[K, V]) unlink(node *urNode[K, V]) {
+	preds := m.predecessors(node.keys[0])
+	for level := range node.next {
+		if preds[level].next[level] == node {
+			preds[level].next[level] = node.next[level]
+		}
+	}
+}
+
+// split moves the upper half of a full node into a new node after it and
+// returns the new node.
+func (m *Unrolled[K, V]) split(node *urNode[K, V]) *urNode[K, V] {
+	half := len(node.keys) / 2
+	tail := m.newNode()
+	tail.keys = append(tail.keys, node.keys[half:]...)
+	tail.values = append(tail.values, node.values[half:]...)
+	clear(node.keys[half:])
+	clear(node.values[half:])
+	node.keys, node.values = node.keys[:half], node.values[:half]
+	for _, k := range tail.keys {
+		m.index[k] = tail
+	}
+	m.link(tail, tail.keys[0])
+	return tail
+}
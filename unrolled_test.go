@@ -0,0 +1,48 @@
+package skiphash
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnrolledMatchesSkipHash(t *testing.T) {
+	ur := NewUnrolled[int, int](WithRandSource(rand.NewSource(1)))
+	ref := New[int, int]()
+	r := rand.New(rand.NewSource(2))
+	for range 20000 {
+		k := r.Intn(2000)
+		switch r.Intn(3) {
+		case 0:
+			assert.Equal(t, ref.Remove(k), ur.Remove(k))
+		case 1:
+			assert.Equal(t, ref.Insert(k, k), ur.Insert(k, k))
+		default:
+			assert.Equal(t, ref.Store(k, -k), ur.Store(k, -k))
+		}
+	}
+	assert.Equal(t, ref.Len(), ur.Len())
+	assert.Equal(t, ref.Range(0, 1999), ur.Range(0, 1999))
+	assert.Equal(t, ref.Range(500, 700), ur.Range(500, 700))
+	assert.Empty(t, ur.Range(3000, 4000))
+	assert.Nil(t, ur.Range(5, 1))
+	for k := range 2000 {
+		want, wantOK := ref.Get(k)
+		got, ok := ur.Get(k)
+		assert.Equal(t, wantOK, ok)
+		assert.Equal(t, want, got)
+	}
+
+	for k := range 2000 {
+		ur.Remove(k)
+	}
+	assert.Zero(t, ur.Len())
+	assert.Nil(t, ur.head.next[0])
+
+	desc := NewUnrolled[int, int](WithDescending())
+	for k := range 100 {
+		desc.Store(k, k)
+	}
+	assert.Equal(t, []Entry[int, int]{{3, 3}, {2, 2}, {1, 1}}, desc.Range(3, 1))
+}
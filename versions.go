@@ -0,0 +1,204 @@
+package skiphash
+
+import (
+	"cmp"
+	"slices"
+	"sort"
+	"time"
+)
+
+// WithVersionIndex keeps a secondary ordering of entries by the write
+// version of their last insert or update, which RangeByVersion queries.
+func WithVersionIndex() Option {
+	return func(cfg *config) {
+		cfg.versionIndex = true
+	}
+}
+
+// VersionedEntry is an entry together with the write version that produced
+// its current value.
+type VersionedEntry[K any, V any] struct {
+	Key     K
+	Value   V
+	Version uint64
+}
+
+type versionRecord[K any, V any] struct {
+	ver  uint64
+	node *slNode[K, V]
+}
+
+// WriteVersion returns the version assigned to the most recent insert or
+// update. Versions start at 1 and increase by one per write.
+func (sh *SkipHash[K, V]) WriteVersion() uint64 {
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+	return sh.writeSeq
+}
+
+// RangeByVersion returns the live entries whose current value was written at
+// a version in [fromVer, toVer], ordered by version. Entries rewritten or
+// removed after that window are not reported. It returns nil unless the
+// SkipHash was created with WithVersionIndex.
+func (sh *SkipHash[K, V]) RangeByVersion(fromVer, toVer uint64) []VersionedEntry[K, V] {
+	sh.unsupportedInFineGrained()
+	if fromVer > toVer {
+		return nil
+	}
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+
+	if !sh.trackVersions {
+		return nil
+	}
+	log := sh.versionLog
+	start := sort.Search(len(log), func(i int) bool { return log[i].ver >= fromVer })
+
+	var out []VersionedEntry[K, V]
+	for _, rec := range log[start:] {
+		if rec.ver > toVer {
+			break
+		}
+		if rec.current(sh) {
+			out = append(out, VersionedEntry[K, V]{
+				Key:     rec.node.key,
+				Value:   *rec.node.value.Load(),
+				Version: rec.ver,
+			})
+		}
+	}
+	return out
+}
+
+// GetWithVersion returns the value of key together with the write version
+// of its last insert or update, which serves as the entry's revision: it
+// grows with every write to the entry and never repeats.
+func (sh *SkipHash[K, V]) GetWithVersion(key K) (V, uint64, bool) {
+	sh.unsupportedInFineGrained()
+	key = sh.normalizeKey(key)
+	sh.faultIn(key, key)
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+	if node, ok := sh.index.get(key); ok {
+		return *node.value.Load(), node.version, true
+	}
+	var zero V
+	return zero, 0, false
+}
+
+// StoreIfVersion stores value under key only if the entry's revision, as
+// returned by GetWithVersion, is still expected; an expected revision of 0
+// requires key to be absent. It reports whether value was stored.
+func (sh *SkipHash[K, V]) StoreIfVersion(key K, value V, expected uint64) bool {
+	sh.unsupportedInFineGrained()
+	key = sh.normalizeKey(key)
+	sh.mu.Lock()
+	defer sh.unlock()
+	sh.faultInLocked(key, key)
+
+	node, exists := sh.index.get(key)
+	switch {
+	case !exists && expected == 0:
+		return sh.insertLocked(key, value) == nil
+	case exists && node.version == expected:
+		sh.touch(node)
+		return sh.updateLocked(node, value) == nil
+	}
+	return false
+}
+
+// current reports whether the record still describes the live value of its
+// node in sh, which Split may have moved the node out of.
+func (r versionRecord[K, V]) current(sh *SkipHash[K, V]) bool {
+	if r.node.version != r.ver || r.node.rTime != 0 {
+		return false
+	}
+	node, ok := sh.index.get(r.node.key)
+	return ok && node == r.node
+}
+
+// noteWriteLocked stamps node with the next write version and, when the
+// version index is enabled, appends it to the log.
+func (sh *SkipHash[K, V]) noteWriteLocked(node *slNode[K, V]) {
+	sh.writeSeq++
+	node.version = sh.writeSeq
+	if node.created == 0 {
+		node.created = node.version
+	}
+	if sh.timestamps {
+		node.updatedAt = time.Now().UnixNano()
+		if node.insertedAt == 0 {
+			node.insertedAt = node.updatedAt
+		}
+	}
+	if !sh.trackVersions {
+		return
+	}
+	// Superseded records are dropped lazily once they dominate the log.
+	if len(sh.versionLog) >= 64 && len(sh.versionLog) >= 2*sh.len {
+		live := sh.versionLog[:0]
+		for _, rec := range sh.versionLog {
+			if rec.current(sh) {
+				live = append(live, rec)
+			}
+		}
+		clear(sh.versionLog[len(live):])
+		sh.versionLog = live
+	}
+	sh.versionLog = append(sh.versionLog, versionRecord[K, V]{ver: sh.writeSeq, node: node})
+}
+
+// entryMeta is the bookkeeping of an entry besides its value and TTL, which
+// a rolled back Txn puts back along with the value and a spilled segment
+// keeps for when the entry is read back in.
+type entryMeta[V any] struct {
+	version, created      uint64
+	insertedAt, updatedAt int64
+	writtenAt             uint64
+	history               []pastValue[V]
+	// iTime is restored only on fault-in; a rolled back removal may have
+	// left the old node linked for AsOf reads.
+	iTime uint64
+}
+
+// undoMetaLocked returns the bookkeeping of node for the undo log of the
+// running Txn, if any.
+func (sh *SkipHash[K, V]) undoMetaLocked(node *slNode[K, V]) entryMeta[V] {
+	if sh.txn == nil {
+		return entryMeta[V]{}
+	}
+	return sh.metaLocked(node)
+}
+
+func (sh *SkipHash[K, V]) metaLocked(node *slNode[K, V]) entryMeta[V] {
+	return entryMeta[V]{
+		version:    node.version,
+		created:    node.created,
+		insertedAt: node.insertedAt,
+		updatedAt:  node.updatedAt,
+		writtenAt:  node.writtenAt,
+		iTime:      node.iTime,
+		// recordHistoryLocked shifts the history in place.
+		history: slices.Clone(node.history),
+	}
+}
+
+// restoreMetaLocked puts back bookkeeping taken by metaLocked, so the entry
+// has the version it had before an undone write or a spill.
+func (sh *SkipHash[K, V]) restoreMetaLocked(node *slNode[K, V], m entryMeta[V]) {
+	node.version, node.created = m.version, m.created
+	node.insertedAt, node.updatedAt = m.insertedAt, m.updatedAt
+	node.writtenAt, node.history = m.writtenAt, m.history
+	if !sh.trackVersions || m.version == 0 {
+		return
+	}
+	rec := versionRecord[K, V]{ver: m.version, node: node}
+	i, found := slices.BinarySearchFunc(sh.versionLog, m.version, func(r versionRecord[K, V], ver uint64) int {
+		return cmp.Compare(r.ver, ver)
+	})
+	if found {
+		sh.versionLog[i] = rec
+	} else {
+		sh.versionLog = slices.Insert(sh.versionLog, i, rec)
+	}
+}
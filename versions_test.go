@@ -0,0 +1,71 @@
+package skiphash
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSkipHashRangeByVersion(t *testing.T) {
+	sh := New[string, int](WithVersionIndex())
+	sh.Insert("b", 1) // v1
+	sh.Insert("a", 2) // v2
+	sh.Insert("c", 3) // v3
+	sh.Store("b", 4)  // v4
+	sh.Remove("c")
+	sh.Insert("d", 5) // v5
+	assert.Equal(t, uint64(5), sh.WriteVersion())
+
+	assert.Equal(t, []VersionedEntry[string, int]{
+		{Key: "a", Value: 2, Version: 2},
+		{Key: "b", Value: 4, Version: 4},
+		{Key: "d", Value: 5, Version: 5},
+	}, sh.RangeByVersion(1, 5))
+	assert.Equal(t, []VersionedEntry[string, int]{
+		{Key: "b", Value: 4, Version: 4},
+	}, sh.RangeByVersion(3, 4))
+	assert.Empty(t, sh.RangeByVersion(5, 4))
+
+	assert.Nil(t, New[string, int]().RangeByVersion(0, 10), "version index is opt-in")
+}
+
+func TestSkipHashRangeByVersionCompactsLog(t *testing.T) {
+	sh := New[int, int](WithVersionIndex())
+	for i := range 1000 {
+		sh.Store(i%10, i)
+	}
+	assert.Less(t, len(sh.versionLog), 100, "superseded records must be compacted")
+
+	got := sh.RangeByVersion(0, sh.WriteVersion())
+	assert.Len(t, got, 10)
+	for i, e := range got {
+		assert.Equal(t, 990+i, e.Value)
+		assert.Equal(t, uint64(991+i), e.Version)
+	}
+}
+
+func TestStoreIfVersion(t *testing.T) {
+	sh := New[string, int]()
+	assert.False(t, sh.StoreIfVersion("a", 1, 7))
+	assert.True(t, sh.StoreIfVersion("a", 1, 0))
+	assert.False(t, sh.StoreIfVersion("a", 2, 0))
+
+	v, rev, ok := sh.GetWithVersion("a")
+	require.True(t, ok)
+	assert.Equal(t, 1, v)
+
+	sh.Store("b", 0)
+	_, other, _ := sh.GetWithVersion("b")
+	assert.False(t, sh.StoreIfVersion("a", 2, other))
+	assert.True(t, sh.StoreIfVersion("a", 2, rev))
+	assert.False(t, sh.StoreIfVersion("a", 3, rev))
+
+	v, next, _ := sh.GetWithVersion("a")
+	assert.Equal(t, 2, v)
+	assert.Greater(t, next, rev)
+
+	_, rev, ok = sh.GetWithVersion("missing")
+	assert.False(t, ok)
+	assert.Zero(t, rev)
+}
@@ -0,0 +1,554 @@
+package skiphash
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"crypto/cipher"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The log is split into numbered segments. CheckpointWAL writes checkpoint
+// N, holding everything logged in the segments before N, and starts segment
+// N. Names are zero-padded so that name order is number order.
+const (
+	walSegmentPrefix    = "wal-"
+	walSegmentSuffix    = ".log"
+	walCheckpointPrefix = "checkpoint-"
+	walCheckpointSuffix = ".skh"
+)
+
+const (
+	walInsert byte = iota + 1
+	walStore
+	walRemove
+	walExpire
+)
+
+// walSealed starts the payload of a record encrypted by WithSnapshotCipher;
+// the rest is the sealed plaintext payload.
+const walSealed byte = 0x80
+
+// WithWAL appends every committed Insert, Store and Remove, including
+// expirations and evictions, to a segmented log in dir. Each record carries the range
+// coordinator version current at the write and a checksum, so a record torn
+// by a crash is detected and dropped. Records reach the operating system
+// before the write returns; call SyncWAL to force them to stable storage.
+// Use Recover to rebuild a SkipHash from dir. TTL deadlines are logged
+// too, so a recovered entry still expires when it was due to.
+func WithWAL(dir string) Option {
+	return func(cfg *config) {
+		if dir != "" {
+			cfg.walDir = dir
+		}
+	}
+}
+
+type wal[K any, V any] struct {
+	dir string
+	// f is segment seq, the one being appended to, and size its length.
+	f      *os.File
+	seq    uint64
+	size   int64
+	keys   codec[K]
+	values codec[V]
+	aead   cipher.AEAD
+	// err is sticky: once a write fails the log no longer matches the map,
+	// so nothing more is appended.
+	err     error
+	scratch []byte
+	sealed  []byte
+}
+
+// openWAL opens the newest log segment in dir for appending, dropping any
+// torn tail first so new records are not written after garbage.
+func openWAL[K any, V any](dir string, keys codec[K], values codec[V], aead cipher.AEAD) *wal[K, V] {
+	w := &wal[K, V]{dir: dir, keys: keys, values: values, aead: aead, seq: 1}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		w.err = err
+		return w
+	}
+	// A crash between writing a checkpoint and creating its segment leaves
+	// the checkpoint newer than every segment.
+	for _, prefix := range []string{walSegmentPrefix, walCheckpointPrefix} {
+		files, err := walFiles(dir, prefix)
+		if err != nil {
+			w.err = err
+			return w
+		}
+		if n := len(files); n > 0 {
+			w.seq = max(w.seq, files[n-1].seq)
+		}
+	}
+	f, err := os.OpenFile(walSegmentPath(dir, w.seq), os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		w.err = err
+		return w
+	}
+	good, err := scanWAL(f, func(int64, []byte) error { return nil })
+	if err == nil {
+		err = f.Truncate(good)
+	}
+	if err == nil {
+		_, err = f.Seek(good, io.SeekStart)
+	}
+	if err != nil {
+		f.Close()
+		w.err = err
+		return w
+	}
+	w.f, w.size = f, good
+	return w
+}
+
+type walFile struct {
+	seq  uint64
+	path string
+}
+
+// walFiles returns the log segments or checkpoints in dir, selected by
+// prefix, in number order.
+func walFiles(dir, prefix string) ([]walFile, error) {
+	suffix := walSegmentSuffix
+	if prefix == walCheckpointPrefix {
+		suffix = walCheckpointSuffix
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []walFile
+	for _, e := range entries {
+		name := e.Name()
+		if !e.Type().IsRegular() || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, suffix) {
+			continue
+		}
+		digits := name[len(prefix) : len(name)-len(suffix)]
+		if seq, err := strconv.ParseUint(digits, 10, 64); err == nil {
+			files = append(files, walFile{seq: seq, path: filepath.Join(dir, e.Name())})
+		}
+	}
+	slices.SortFunc(files, func(a, b walFile) int { return cmp.Compare(a.seq, b.seq) })
+	return files, nil
+}
+
+func walSegmentPath(dir string, seq uint64) string {
+	return filepath.Join(dir, fmt.Sprintf("%s%020d%s", walSegmentPrefix, seq, walSegmentSuffix))
+}
+
+func walCheckpointPath(dir string, seq uint64) string {
+	return filepath.Join(dir, fmt.Sprintf("%s%020d%s", walCheckpointPrefix, seq, walCheckpointSuffix))
+}
+
+// appendLocked logs a committed change made at version ver.
+func (w *wal[K, V]) appendLocked(c change[K, V], ver uint64) {
+	if w.err != nil {
+		return
+	}
+	op := walStore
+	switch c.kind {
+	case ChangeInsert:
+		op = walInsert
+	case ChangeRemove:
+		op = walRemove
+	case changeExpiry:
+		op = walExpire
+	}
+
+	buf := append(w.scratch[:0], 0, 0, 0, 0, 0, 0, 0, 0, op)
+	buf = binary.AppendUvarint(buf, ver)
+	if op == walRemove || op == walExpire {
+		kb, err := w.keys.encode(c.key)
+		if err != nil {
+			w.err = fmt.Errorf("skiphash: encode key %v: %w", c.key, err)
+			return
+		}
+		buf = appendChunk(buf, kb)
+		if op == walExpire {
+			buf = binary.AppendVarint(buf, c.expiry)
+		}
+	} else {
+		var err error
+		if buf, err = appendRecord(buf, w.keys, w.values, c.key, c.value); err != nil {
+			w.err = err
+			return
+		}
+	}
+	payload := buf[8:]
+	if w.aead != nil {
+		w.sealed = seal(w.aead, append(w.sealed[:0], walSealed), payload, walRecordAD(w.seq, w.size))
+		buf = append(buf[:8], w.sealed...)
+		payload = buf[8:]
+	}
+	binary.LittleEndian.PutUint32(buf[0:4], uint32(len(payload)))
+	binary.LittleEndian.PutUint32(buf[4:8], crc32.ChecksumIEEE(payload))
+	w.scratch = buf
+	if _, err := w.f.Write(buf); err != nil {
+		w.err = err
+		return
+	}
+	w.size += int64(len(buf))
+}
+
+// walRecordAD binds a sealed record to its place in the log, so records
+// cannot be moved between or within segments.
+func walRecordAD(seq uint64, off int64) []byte {
+	return binary.BigEndian.AppendUint64(binary.BigEndian.AppendUint64(nil, seq), uint64(off))
+}
+
+// unseal returns the plaintext payload of the record at off in segment seq.
+func (w *wal[K, V]) unseal(seq uint64, off int64, payload []byte) ([]byte, error) {
+	sealed := len(payload) > 0 && payload[0] == walSealed
+	switch {
+	case sealed && w.aead == nil:
+		return nil, fmt.Errorf("%w: encrypted log record needs WithSnapshotCipher", ErrBadSnapshot)
+	case !sealed && w.aead != nil:
+		return nil, fmt.Errorf("%w: log record is not encrypted", ErrBadSnapshot)
+	case !sealed:
+		return payload, nil
+	}
+	return unseal(w.aead, nil, payload[1:], walRecordAD(seq, off))
+}
+
+// scanWAL calls apply with the offset and payload of every intact record in
+// r and returns the offset just past the last one.
+func scanWAL(r io.Reader, apply func(off int64, payload []byte) error) (int64, error) {
+	br := bufio.NewReader(r)
+	var good int64
+	header := make([]byte, 8)
+	for {
+		if _, err := io.ReadFull(br, header); err != nil {
+			return good, nil
+		}
+		size := binary.LittleEndian.Uint32(header[0:4])
+		if size > maxSnapshotItem {
+			return good, nil
+		}
+		payload := make([]byte, size)
+		if _, err := io.ReadFull(br, payload); err != nil {
+			return good, nil
+		}
+		if crc32.ChecksumIEEE(payload) != binary.LittleEndian.Uint32(header[4:8]) {
+			return good, nil
+		}
+		if err := apply(good, payload); err != nil {
+			return good, err
+		}
+		good += int64(len(header) + len(payload))
+	}
+}
+
+// replayLocked applies the record logged at off in segment seq.
+func (sh *SkipHash[K, V]) replayLocked(w *wal[K, V], seq uint64, off int64, payload []byte) error {
+	payload, err := w.unseal(seq, off, payload)
+	if err != nil {
+		return err
+	}
+	if len(payload) == 0 {
+		return fmt.Errorf("%w: empty log record", ErrBadSnapshot)
+	}
+	br := bufio.NewReader(bytes.NewReader(payload[1:]))
+	if _, err := binary.ReadUvarint(br); err != nil {
+		return snapshotReadError(err)
+	}
+	kb, err := readChunk(br)
+	if err != nil {
+		return snapshotReadError(err)
+	}
+	key, err := w.keys.decode(kb)
+	if err != nil {
+		return fmt.Errorf("%w: log key: %w", ErrBadSnapshot, err)
+	}
+	key = sh.normalizeKey(key)
+	node, exists := sh.index.get(key)
+
+	switch payload[0] {
+	case walRemove:
+		if exists {
+			sh.removeLocked(node)
+		}
+		return nil
+	case walExpire:
+		at, err := binary.ReadVarint(br)
+		if err != nil {
+			return snapshotReadError(err)
+		}
+		if exists {
+			sh.scheduleLocked(key, time.Unix(0, at))
+		}
+		return nil
+	}
+	vb, err := readChunk(br)
+	if err != nil {
+		return snapshotReadError(err)
+	}
+	value, err := w.values.decode(vb)
+	if err != nil {
+		return fmt.Errorf("%w: log value: %w", ErrBadSnapshot, err)
+	}
+	if exists {
+		return sh.updateLocked(node, value)
+	}
+	return sh.insertLocked(key, value)
+}
+
+// Recover rebuilds the SkipHash logged in dir by WithWAL: it loads the
+// newest checkpoint that is intact, if any, then replays the log segments
+// written after it. A torn final record is discarded; a damaged checkpoint
+// is passed over for the one before it. The returned SkipHash keeps logging
+// to dir; opts should match the ones the log was written with.
+func Recover[K cmp.Ordered, V any](dir string, opts ...Option) (*SkipHash[K, V], error) {
+	sh := New[K, V](append(opts[:len(opts):len(opts)], WithWAL(dir))...)
+	w := sh.wal
+	if w.err != nil {
+		return nil, w.err
+	}
+	// Nothing replayed may be logged again.
+	sh.wal = nil
+	if err := sh.recoverFrom(w); err != nil {
+		w.f.Close()
+		return nil, err
+	}
+	sh.wal = w
+	return sh, nil
+}
+
+func (sh *SkipHash[K, V]) recoverFrom(w *wal[K, V]) error {
+	checkpoints, err := walFiles(w.dir, walCheckpointPrefix)
+	if err != nil {
+		return err
+	}
+	start := uint64(1)
+	var checkpointErr error
+	for i := len(checkpoints) - 1; i >= 0; i-- {
+		err := sh.LoadFromFile(checkpoints[i].path)
+		if err == nil {
+			start = checkpoints[i].seq
+			break
+		}
+		if !errors.Is(err, ErrBadSnapshot) && !errors.Is(err, ErrUnsorted) {
+			return fmt.Errorf("skiphash: load checkpoint: %w", err)
+		}
+		checkpointErr = cmp.Or(checkpointErr, err)
+	}
+
+	segments, err := walFiles(w.dir, walSegmentPrefix)
+	if err != nil {
+		return err
+	}
+	next := start
+	for _, seg := range segments {
+		if seg.seq < start {
+			continue
+		}
+		if seg.seq != next {
+			break
+		}
+		if seg.seq == w.seq {
+			return sh.replayTail(w)
+		}
+		if err := sh.replaySegment(w, seg); err != nil {
+			return err
+		}
+		next++
+	}
+	if checkpointErr != nil {
+		return fmt.Errorf("skiphash: load checkpoint: %w", checkpointErr)
+	}
+	return fmt.Errorf("skiphash: log segment %d is missing", next)
+}
+
+// replaySegment replays a segment that was complete when the next one was
+// started, so it must not end in a torn record.
+func (sh *SkipHash[K, V]) replaySegment(w *wal[K, V], seg walFile) error {
+	f, err := os.Open(seg.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	sh.mu.Lock()
+	end, err := scanWAL(f, func(off int64, payload []byte) error {
+		return sh.replayLocked(w, seg.seq, off, payload)
+	})
+	sh.unlock()
+	if err != nil {
+		return fmt.Errorf("skiphash: replay log segment %d at offset %d: %w", seg.seq, end, err)
+	}
+	if end != info.Size() {
+		return fmt.Errorf("%w: log segment %d is damaged at offset %d", ErrBadSnapshot, seg.seq, end)
+	}
+	return nil
+}
+
+// replayTail replays the segment being appended to, which openWAL has
+// already cut back to its last intact record.
+func (sh *SkipHash[K, V]) replayTail(w *wal[K, V]) error {
+	if _, err := w.f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	sh.mu.Lock()
+	end, err := scanWAL(w.f, func(off int64, payload []byte) error {
+		return sh.replayLocked(w, w.seq, off, payload)
+	})
+	sh.unlock()
+	if err != nil {
+		return fmt.Errorf("skiphash: replay log segment %d at offset %d: %w", w.seq, end, err)
+	}
+	_, err = w.f.Seek(end, io.SeekStart)
+	return err
+}
+
+// CheckpointWAL writes the current contents to a new checkpoint in the WAL
+// directory and starts a new log segment, bounding the work Recover has to
+// do. The previous checkpoint and the segments after it are kept as a
+// fallback in case the new checkpoint is damaged; older files are deleted.
+// It holds the write lock while the checkpoint is written.
+func (sh *SkipHash[K, V]) CheckpointWAL() error {
+	if sh.wal == nil {
+		return errors.New("skiphash: WAL is not enabled")
+	}
+	sh.mu.Lock()
+	defer sh.unlock()
+	w := sh.wal
+	if w.err != nil {
+		return w.err
+	}
+	sh.expireDueLocked(0)
+	if sh.tier != nil {
+		sh.loadSegmentsLocked(func(*segment[K]) bool { return true })
+	}
+
+	// Checkpoints hold the TTLs with the entries. A crash before the
+	// checkpoint is written leaves the previous one, which replays the
+	// segments since, including the one started here.
+	next := w.seq + 1
+	if err := w.startSegment(next); err != nil {
+		w.err = err
+		return err
+	}
+	err := writeFileAtomic(walCheckpointPath(w.dir, next), func(out io.Writer) error {
+		return writeSnapshotFile(out, func(out io.Writer) error {
+			return sh.checkpointLocked(out, w)
+		})
+	})
+	if err != nil {
+		return err
+	}
+	return w.prune(next)
+}
+
+// checkpointLocked writes a SaveTo stream of the live entries.
+func (sh *SkipHash[K, V]) checkpointLocked(dst io.Writer, w *wal[K, V]) error {
+	out, err := sh.newSnapshotWriter(dst, w.keys, w.values, sh.len)
+	if err != nil {
+		return err
+	}
+	var record []byte
+	for node := sh.head.next[0]; node != sh.tail; node = node.next[0] {
+		if node.rTime != 0 {
+			continue
+		}
+		if record, err = appendRecord(record[:0], w.keys, w.values, node.key, *node.value.Load()); err != nil {
+			return err
+		}
+		if _, err = out.Write(binary.AppendUvarint(record, uint64(node.expiry.at))); err != nil {
+			return err
+		}
+	}
+	return out.Close()
+}
+
+// startSegment syncs and closes the current segment and appends to segment
+// seq from now on.
+func (w *wal[K, V]) startSegment(seq uint64) error {
+	err := w.f.Sync()
+	if closeErr := w.f.Close(); err == nil {
+		err = closeErr
+	}
+	w.f = nil
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(walSegmentPath(w.dir, seq), os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	w.f, w.seq, w.size = f, seq, 0
+	return nil
+}
+
+// prune deletes the checkpoints and segments that precede the checkpoint
+// before seq.
+func (w *wal[K, V]) prune(seq uint64) error {
+	checkpoints, err := walFiles(w.dir, walCheckpointPrefix)
+	if err != nil {
+		return err
+	}
+	i := slices.IndexFunc(checkpoints, func(f walFile) bool { return f.seq >= seq })
+	if i < 1 {
+		return nil
+	}
+	keep := checkpoints[i-1].seq
+	segments, err := walFiles(w.dir, walSegmentPrefix)
+	if err != nil {
+		return err
+	}
+	for _, f := range slices.Concat(checkpoints, segments) {
+		if f.seq < keep {
+			if err := os.Remove(f.path); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// SyncWAL flushes the log to stable storage. It returns the first error the
+// log hit, after which nothing more was logged.
+func (sh *SkipHash[K, V]) SyncWAL() error {
+	if sh.wal == nil {
+		return nil
+	}
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	if sh.wal.err != nil {
+		return sh.wal.err
+	}
+	return sh.wal.f.Sync()
+}
+
+// CloseWAL syncs and closes the log; later writes are no longer logged.
+func (sh *SkipHash[K, V]) CloseWAL() error {
+	if sh.wal == nil {
+		return nil
+	}
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	w := sh.wal
+	if w.f == nil {
+		return w.err
+	}
+	err := w.f.Sync()
+	if closeErr := w.f.Close(); err == nil {
+		err = closeErr
+	}
+	w.f = nil
+	if w.err == nil {
+		w.err = os.ErrClosed
+	}
+	return err
+}
@@ -0,0 +1,181 @@
+package skiphash
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWALRecover(t *testing.T) {
+	dir := t.TempDir()
+	sh := New[string, int](WithWAL(dir))
+	sh.Insert("a", 1)
+	sh.Store("b", 2)
+	sh.Store("a", 10)
+	sh.Store("c", 3)
+	sh.Remove("b")
+	require.NoError(t, sh.SyncWAL())
+	require.NoError(t, sh.CloseWAL())
+
+	got, err := Recover[string, int](dir)
+	require.NoError(t, err)
+	require.Equal(t, sh.RangeAll(), got.RangeAll())
+
+	// The recovered map keeps logging to the same directory.
+	got.Store("d", 4)
+	require.NoError(t, got.CloseWAL())
+	again, err := Recover[string, int](dir)
+	require.NoError(t, err)
+	require.Equal(t, got.RangeAll(), again.RangeAll())
+	require.NoError(t, again.CloseWAL())
+}
+
+func TestWALTornTail(t *testing.T) {
+	dir := t.TempDir()
+	sh := New[int, int](WithWAL(dir))
+	for i := range 10 {
+		sh.Store(i, i)
+	}
+	require.NoError(t, sh.CloseWAL())
+
+	f, err := os.OpenFile(walSegmentPath(dir, 1), os.O_APPEND|os.O_WRONLY, 0)
+	require.NoError(t, err)
+	_, err = f.Write([]byte{42, 0, 0, 0, 1, 2})
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	got, err := Recover[int, int](dir)
+	require.NoError(t, err)
+	require.Equal(t, 10, got.Len())
+	got.Store(10, 10)
+	require.NoError(t, got.CloseWAL())
+
+	again, err := Recover[int, int](dir)
+	require.NoError(t, err)
+	require.Equal(t, 11, again.Len(), "writes after a torn tail must not be lost")
+	require.NoError(t, again.CloseWAL())
+}
+
+func TestWALCheckpoint(t *testing.T) {
+	dir := t.TempDir()
+	sh := New[int, int](WithWAL(dir))
+	for i := range 100 {
+		sh.Store(i, i)
+	}
+	require.NoError(t, sh.CheckpointWAL())
+	info, err := os.Stat(walSegmentPath(dir, 2))
+	require.NoError(t, err)
+	require.Zero(t, info.Size())
+
+	sh.Remove(0)
+	sh.Store(1, 100)
+	require.NoError(t, sh.CloseWAL())
+
+	got, err := Recover[int, int](dir)
+	require.NoError(t, err)
+	require.Equal(t, sh.RangeAll(), got.RangeAll())
+	require.NoError(t, got.CloseWAL())
+}
+
+func TestWALCheckpointFallback(t *testing.T) {
+	dir := t.TempDir()
+	sh := New[int, int](WithWAL(dir))
+	for round := range 3 {
+		for i := range 10 {
+			sh.Store(round*10+i, i)
+		}
+		require.NoError(t, sh.CheckpointWAL())
+	}
+	sh.Remove(5)
+	require.NoError(t, sh.CloseWAL())
+
+	// Only the last two checkpoints and the segments after the older one
+	// are kept.
+	checkpoints, err := walFiles(dir, walCheckpointPrefix)
+	require.NoError(t, err)
+	require.Equal(t, []walFile{
+		{seq: 3, path: walCheckpointPath(dir, 3)},
+		{seq: 4, path: walCheckpointPath(dir, 4)},
+	}, checkpoints)
+	segments, err := walFiles(dir, walSegmentPrefix)
+	require.NoError(t, err)
+	require.Len(t, segments, 2)
+
+	// A damaged newest checkpoint falls back to the one before it.
+	require.NoError(t, os.WriteFile(walCheckpointPath(dir, 4), []byte("SKHF\x01garbage"), 0o644))
+	got, err := Recover[int, int](dir)
+	require.NoError(t, err)
+	require.Equal(t, sh.RangeAll(), got.RangeAll())
+	require.NoError(t, got.CloseWAL())
+
+	// Without the segment after it, the fallback cannot be used.
+	require.NoError(t, os.Remove(walSegmentPath(dir, 3)))
+	_, err = Recover[int, int](dir)
+	require.ErrorIs(t, err, ErrBadSnapshot)
+}
+
+func TestWALDamagedSegment(t *testing.T) {
+	dir := t.TempDir()
+	sh := New[int, int](WithWAL(dir))
+	sh.Store(1, 1)
+	require.NoError(t, sh.CheckpointWAL())
+	sh.Store(2, 2)
+	require.NoError(t, sh.CheckpointWAL())
+	require.NoError(t, sh.CloseWAL())
+	require.NoError(t, os.Remove(walCheckpointPath(dir, 3)))
+
+	// Segment 2 was complete when segment 3 started, so a torn record in it
+	// is an error rather than a tail to cut.
+	f, err := os.OpenFile(walSegmentPath(dir, 2), os.O_APPEND|os.O_WRONLY, 0)
+	require.NoError(t, err)
+	_, err = f.Write([]byte{42, 0, 0, 0, 1, 2})
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	_, err = Recover[int, int](dir)
+	require.ErrorIs(t, err, ErrBadSnapshot)
+}
+
+func TestWALLogsEvictions(t *testing.T) {
+	dir := t.TempDir()
+	sh := New[int, int](WithWAL(dir), WithMaxEntries(2, EvictOldest))
+	for i := range 5 {
+		sh.Store(i, i)
+	}
+	require.NoError(t, sh.CloseWAL())
+
+	got, err := Recover[int, int](dir, WithMaxEntries(2, EvictOldest))
+	require.NoError(t, err)
+	require.Equal(t, []Entry[int, int]{{Key: 3, Value: 3}, {Key: 4, Value: 4}}, got.RangeAll())
+	require.NoError(t, got.CloseWAL())
+}
+
+func TestCheckpointWALRequiresWAL(t *testing.T) {
+	require.Error(t, New[int, int]().CheckpointWAL())
+	require.NoError(t, New[int, int]().SyncWAL())
+}
+
+func TestWALLogsDeadlines(t *testing.T) {
+	dir := t.TempDir()
+	sh := New[string, int](WithWAL(dir))
+	sh.StoreTTL("kept", 1, time.Hour)
+	sh.StoreTTL("cleared", 2, time.Hour)
+	sh.Store("cleared", 3)
+	require.NoError(t, sh.CheckpointWAL())
+	sh.StoreTTL("due", 4, 20*time.Millisecond)
+	require.NoError(t, sh.CloseWAL())
+
+	got, err := Recover[string, int](dir)
+	require.NoError(t, err)
+	left, ok := got.TTL("kept")
+	require.True(t, ok)
+	require.Greater(t, left, 59*time.Minute)
+	_, ok = got.TTL("cleared")
+	require.False(t, ok)
+	_, ok = got.TTL("due")
+	require.True(t, ok)
+	time.Sleep(30 * time.Millisecond)
+	require.Equal(t, []Entry[string, int]{{Key: "cleared", Value: 3}, {Key: "kept", Value: 1}}, got.RangeAll())
+	require.NoError(t, got.CloseWAL())
+}
@@ -0,0 +1,106 @@
+package skiphash
+
+// Direction selects which way a walk moves through the key order.
+type Direction int
+
+const (
+	Ascending Direction = iota
+	Descending
+)
+
+// WalkFrom returns up to n live entries strictly after key (Ascending) or
+// strictly before key (Descending), in walk order, under a single read lock.
+// It is the batched form of repeated Succ or Pred calls.
+func (sh *SkipHash[K, V]) WalkFrom(key K, n int, dir Direction) []Entry[K, V] {
+	key = sh.normalizeKey(key)
+	if n <= 0 {
+		return nil
+	}
+	if sh.fine != nil {
+		return sh.fine.walkFrom(&key, n, dir)
+	}
+	sh.faultInAll()
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+
+	out := make([]Entry[K, V], 0, min(n, sh.len))
+	if dir == Descending {
+		for node := sh.predecessorLocked(key, true); node != sh.head && len(out) < n; node = node.prev[0] {
+			if node.rTime == 0 {
+				out = append(out, Entry[K, V]{Key: node.key, Value: *node.value.Load()})
+			}
+		}
+		return out
+	}
+
+	for node := sh.lowerBoundLocked(key); node != sh.tail && len(out) < n; node = node.next[0] {
+		if node.rTime == 0 && sh.compare(node.key, key) != 0 {
+			out = append(out, Entry[K, V]{Key: node.key, Value: *node.value.Load()})
+		}
+	}
+	return out
+}
+
+// NextN returns up to n live entries strictly after key in ascending order;
+// it is WalkFrom(key, n, Ascending).
+func (sh *SkipHash[K, V]) NextN(key K, n int) []Entry[K, V] {
+	return sh.WalkFrom(key, n, Ascending)
+}
+
+// PrevN returns up to n live entries strictly before key, nearest first;
+// it is WalkFrom(key, n, Descending).
+func (sh *SkipHash[K, V]) PrevN(key K, n int) []Entry[K, V] {
+	return sh.WalkFrom(key, n, Descending)
+}
+
+// RangeWhile calls fn for the live entries from low upward, in key order,
+// until fn returns false or the entries run out. It sees the map as of the
+// start of the scan and holds no lock while fn runs, so fn may use the map.
+func (sh *SkipHash[K, V]) RangeWhile(low K, fn func(key K, value V) bool) {
+	low = sh.normalizeKey(low)
+	sh.walkBounded(&low, nil, fn)
+}
+
+// KSmallest returns the first n live entries in key order, walking from the
+// head of the list.
+func (sh *SkipHash[K, V]) KSmallest(n int) []Entry[K, V] {
+	if n <= 0 {
+		return nil
+	}
+	if sh.fine != nil {
+		return sh.fine.walkFrom(nil, n, Ascending)
+	}
+	sh.faultInAll()
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+
+	out := make([]Entry[K, V], 0, min(n, sh.len))
+	for node := sh.head.next[0]; node != sh.tail && len(out) < n; node = node.next[0] {
+		if node.rTime == 0 {
+			out = append(out, Entry[K, V]{Key: node.key, Value: *node.value.Load()})
+		}
+	}
+	return out
+}
+
+// KLargest returns the last n live entries, largest key first, walking back
+// from the tail of the list.
+func (sh *SkipHash[K, V]) KLargest(n int) []Entry[K, V] {
+	if n <= 0 {
+		return nil
+	}
+	if sh.fine != nil {
+		return sh.fine.walkFrom(nil, n, Descending)
+	}
+	sh.faultInAll()
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+
+	out := make([]Entry[K, V], 0, min(n, sh.len))
+	for node := sh.tail.prev[0]; node != sh.head && len(out) < n; node = node.prev[0] {
+		if node.rTime == 0 {
+			out = append(out, Entry[K, V]{Key: node.key, Value: *node.value.Load()})
+		}
+	}
+	return out
+}
@@ -0,0 +1,99 @@
+package skiphash
+
+// watchBuffer is the channel capacity of every watcher.
+const watchBuffer = 64
+
+// ChangeKind says what kind of mutation a ChangeEvent describes.
+type ChangeKind uint8
+
+const (
+	ChangeInsert ChangeKind = iota + 1
+	ChangeUpdate
+	ChangeRemove
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	case ChangeInsert:
+		return "insert"
+	case ChangeUpdate:
+		return "update"
+	case ChangeRemove:
+		return "remove"
+	}
+	return "unknown"
+}
+
+// ChangeEvent describes one committed mutation. For removals Value is the
+// value that was removed.
+type ChangeEvent[K any, V any] struct {
+	Kind  ChangeKind
+	Key   K
+	Value V
+	// Version is the range-coordinator version the change was made at.
+	// Events are delivered in commit order; several may share a version.
+	Version uint64
+}
+
+type watchRegistry[K any, V any] struct {
+	watchers map[*watcher[K, V]]struct{}
+}
+
+type watcher[K any, V any] struct {
+	low, high K
+	ch        chan ChangeEvent[K, V]
+}
+
+// Watch subscribes to changes of key. See WatchRange.
+func (sh *SkipHash[K, V]) Watch(key K) (<-chan ChangeEvent[K, V], func()) {
+	return sh.WatchRange(key, key)
+}
+
+// WatchRange subscribes to changes of keys in [low, high]. Events are
+// published without blocking writers: a watcher whose buffer is full is
+// dropped and its channel closed, which tells the consumer to resync. The
+// returned cancel function unsubscribes and closes the channel; it is safe
+// to call more than once.
+func (sh *SkipHash[K, V]) WatchRange(low, high K) (<-chan ChangeEvent[K, V], func()) {
+	sh.unsupportedInFineGrained()
+	w := &watcher[K, V]{
+		low:  sh.normalizeKey(low),
+		high: sh.normalizeKey(high),
+		ch:   make(chan ChangeEvent[K, V], watchBuffer),
+	}
+
+	sh.mu.Lock()
+	if sh.watchers == nil {
+		sh.watchers = &watchRegistry[K, V]{watchers: make(map[*watcher[K, V]]struct{})}
+	}
+	sh.watchers.watchers[w] = struct{}{}
+	sh.mu.Unlock()
+
+	cancel := func() {
+		sh.mu.Lock()
+		defer sh.mu.Unlock()
+		sh.watchers.dropLocked(w)
+	}
+	return w.ch, cancel
+}
+
+func (r *watchRegistry[K, V]) publishLocked(sh *SkipHash[K, V], ev ChangeEvent[K, V]) {
+	for w := range r.watchers {
+		if sh.compare(ev.Key, w.low) < 0 || sh.compare(ev.Key, w.high) > 0 {
+			continue
+		}
+		select {
+		case w.ch <- ev:
+		default:
+			r.dropLocked(w)
+		}
+	}
+}
+
+func (r *watchRegistry[K, V]) dropLocked(w *watcher[K, V]) {
+	if _, ok := r.watchers[w]; !ok {
+		return
+	}
+	delete(r.watchers, w)
+	close(w.ch)
+}
@@ -0,0 +1,91 @@
+package skiphash
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func drain[K any, V any](ch <-chan ChangeEvent[K, V]) []ChangeEvent[K, V] {
+	var out []ChangeEvent[K, V]
+	for {
+		select {
+		case ev, ok := <-ch:
+			if !ok {
+				return out
+			}
+			out = append(out, ev)
+		default:
+			return out
+		}
+	}
+}
+
+func TestSkipHashWatch(t *testing.T) {
+	sh := New[int, string]()
+	keyCh, cancelKey := sh.Watch(5)
+	rangeCh, cancelRange := sh.WatchRange(1, 10)
+	defer cancelRange()
+
+	sh.Insert(5, "a")
+	sh.Store(5, "b")
+	sh.Insert(7, "x")
+	sh.Insert(20, "out")
+	sh.Remove(5)
+	sh.Remove(6)
+
+	events := drain(keyCh)
+	require.Len(t, events, 3)
+	assert.Equal(t, ChangeInsert, events[0].Kind)
+	assert.Equal(t, "a", events[0].Value)
+	assert.Equal(t, ChangeUpdate, events[1].Kind)
+	assert.Equal(t, "b", events[1].Value)
+	assert.Equal(t, ChangeRemove, events[2].Kind)
+	assert.Equal(t, "b", events[2].Value)
+	assert.Equal(t, "remove", events[2].Kind.String())
+
+	var keys []int
+	for _, ev := range drain(rangeCh) {
+		keys = append(keys, ev.Key)
+	}
+	assert.Equal(t, []int{5, 5, 7, 5}, keys)
+
+	cancelKey()
+	cancelKey()
+	sh.Insert(5, "c")
+	_, ok := <-keyCh
+	assert.False(t, ok, "cancel closes the channel")
+}
+
+func TestSkipHashWatchOverflowCloses(t *testing.T) {
+	sh := New[int, int]()
+	ch, cancel := sh.Watch(1)
+	defer cancel()
+
+	for i := range watchBuffer + 1 {
+		sh.Store(1, i)
+	}
+	assert.Len(t, drain(ch), watchBuffer)
+	_, ok := <-ch
+	assert.False(t, ok, "slow watchers are dropped")
+
+	sh.Store(1, -1)
+	cancel()
+}
+
+func TestSkipHashWatchVersions(t *testing.T) {
+	sh := New[int, int]()
+	ch, cancel := sh.WatchRange(0, 100)
+	defer cancel()
+
+	sh.Insert(1, 1)
+	snap := sh.Snapshot()
+	sh.Insert(2, 2)
+	snap.Close()
+
+	events := drain(ch)
+	require.Len(t, events, 2)
+	assert.Less(t, events[0].Version, snap.Version())
+	assert.Equal(t, snap.Version(), events[1].Version)
+}
@@ -0,0 +1,43 @@
+package skiphash
+
+// WithMaxWeight caps the total weight of the in-memory entries at maxWeight,
+// as measured by weigher, which must return the same weight for the same
+// entry every time. When an eviction policy is set with WithMaxEntries,
+// writes that exceed the budget evict other entries; otherwise they fail with
+// ErrOverWeight. An entry heavier than the whole budget is always rejected.
+func WithMaxWeight[K any, V any](maxWeight int64, weigher func(K, V) int64) Option {
+	return func(cfg *config) {
+		if maxWeight > 0 && weigher != nil {
+			cfg.maxWeight = maxWeight
+			cfg.weigher = weigher
+		}
+	}
+}
+
+// Weight returns the total weight of the in-memory entries, or 0 without
+// WithMaxWeight.
+func (sh *SkipHash[K, V]) Weight() int64 {
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+	return sh.weight
+}
+
+func (sh *SkipHash[K, V]) weigh(key K, value V) int64 {
+	if sh.weigher == nil {
+		return 0
+	}
+	return sh.weigher(key, value)
+}
+
+// admitWeightLocked checks that an entry of the given weight fits and that
+// the budget can absorb growth more weight, counting on eviction to make
+// room when a policy is set.
+func (sh *SkipHash[K, V]) admitWeightLocked(entry, growth int64) error {
+	if sh.weigher == nil {
+		return nil
+	}
+	if entry > sh.maxWeight || sh.eviction == 0 && sh.weight+growth > sh.maxWeight {
+		return ErrOverWeight
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package skiphash
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func blobWeight(_ int, v string) int64 {
+	return int64(len(v))
+}
+
+func TestSkipHashMaxWeightRejects(t *testing.T) {
+	sh := New[int, string](WithMaxWeight(10, blobWeight))
+	require.NoError(t, sh.TryInsert(1, "aaaa"))
+	require.NoError(t, sh.TryInsert(2, "bbbb"))
+	assert.ErrorIs(t, sh.TryInsert(3, "ccc"), ErrOverWeight)
+	_, err := sh.TryStore(1, "aaaaaaa")
+	assert.ErrorIs(t, err, ErrOverWeight)
+	v, _ := sh.Get(1)
+	assert.Equal(t, "aaaa", v, "a rejected update leaves the old value")
+
+	_, err = sh.TryStore(1, "a")
+	require.NoError(t, err)
+	require.NoError(t, sh.TryInsert(3, "ccccc"))
+	assert.Equal(t, int64(10), sh.Weight())
+	sh.Remove(2)
+	assert.Equal(t, int64(6), sh.Weight())
+}
+
+func TestSkipHashMaxWeightEvicts(t *testing.T) {
+	var evicted []int
+	sh := New[int, string](
+		WithMaxWeight(10, blobWeight),
+		WithMaxEntries(0, EvictOldest),
+		WithHooks(Hooks[int, string]{OnEvict: func(key int, _ string) { evicted = append(evicted, key) }}),
+	)
+	for k := range 5 {
+		sh.Store(k, "xxx")
+	}
+	assert.Equal(t, []int{0, 1}, evicted)
+	assert.Equal(t, int64(9), sh.Weight())
+
+	sh.Store(4, "yyyyyyyy")
+	assert.Equal(t, []int{0, 1, 2, 3}, evicted)
+	assert.Equal(t, 1, sh.Len())
+	assert.ErrorIs(t, sh.TryInsert(9, strings.Repeat("z", 11)), ErrOverWeight)
+	checkSpans(t, sh)
+}